@@ -0,0 +1,101 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/wind959/ko-utils/dbutils/boltutil"
+)
+
+// boltHelper 基于bbolt的持久化缓存助手实现
+type boltHelper struct {
+	store  *boltutil.Store
+	bucket []byte
+}
+
+// NewBoltHelper 创建bbolt缓存助手实例,数据存放在 store 的 bucket 存储桶中。
+// store 由调用方管理,Close 不会关闭它;过期键的后台清理通过 boltutil.WithTTLSweeper 开启
+func NewBoltHelper(store *boltutil.Store, bucket string) (CacheInterface, error) {
+	if err := store.CreateBucket([]byte(bucket)); err != nil {
+		return nil, err
+	}
+	return &boltHelper{store: store, bucket: []byte(bucket)}, nil
+}
+
+// Set 设置缓存值,expiration<=0 表示永不过期
+func (b *boltHelper) Set(ctx context.Context, key string, value string, expiration time.Duration) error {
+	return b.store.PutWithTTL(b.bucket, []byte(key), value, expiration)
+}
+
+// Get 获取缓存值,键不存在或已过期时返回空字符串
+func (b *boltHelper) Get(ctx context.Context, key string) (string, error) {
+	var value string
+	err := b.store.Get(b.bucket, []byte(key), &value)
+	if errors.Is(err, boltutil.ErrKeyNotFound) {
+		return "", nil
+	}
+	return value, err
+}
+
+// Del 删除键
+func (b *boltHelper) Del(ctx context.Context, keys ...string) error {
+	for _, key := range keys {
+		if err := b.store.Delete(b.bucket, []byte(key)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Exists 检查键是否存在
+func (b *boltHelper) Exists(ctx context.Context, keys ...string) (int64, error) {
+	count := int64(0)
+	for _, key := range keys {
+		_, _, err := b.store.ExpiresAt(b.bucket, []byte(key))
+		if errors.Is(err, boltutil.ErrKeyNotFound) {
+			continue
+		}
+		if err != nil {
+			return count, err
+		}
+		count++
+	}
+	return count, nil
+}
+
+// Expire 设置键的过期时间,键不存在时忽略
+func (b *boltHelper) Expire(ctx context.Context, key string, expiration time.Duration) error {
+	err := b.store.Expire(b.bucket, []byte(key), expiration)
+	if errors.Is(err, boltutil.ErrKeyNotFound) {
+		return nil
+	}
+	return err
+}
+
+// GetAll 获取所有未过期的缓存项,永不过期的项 ExpiresAt 为零值
+func (b *boltHelper) GetAll(ctx context.Context) ([]CacheItem, error) {
+	var items []CacheItem
+	codec := b.store.Codec()
+	err := b.store.ScanWithTTL(b.bucket, func(k, v []byte, at time.Time, ok bool) error {
+		var value string
+		if err := codec.Unmarshal(v, &value); err != nil {
+			return err
+		}
+		item := CacheItem{Key: string(k), Value: value}
+		if ok {
+			item.ExpiresAt = at
+		}
+		items = append(items, item)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// Close 关闭缓存助手,不关闭底层 store
+func (b *boltHelper) Close() error {
+	return nil
+}
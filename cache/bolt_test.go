@@ -0,0 +1,59 @@
+package cache
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wind959/ko-utils/clock"
+	"github.com/wind959/ko-utils/dbutils/boltutil"
+)
+
+func TestBoltHelper(t *testing.T) {
+	fake := clock.NewFake(time.Time{})
+	store, err := boltutil.Open(filepath.Join(t.TempDir(), "cache.db"), boltutil.WithClock(fake))
+	assert.NoError(t, err)
+	defer store.Close()
+
+	cache, err := NewBoltHelper(store, "cache")
+	assert.NoError(t, err)
+	defer cache.Close()
+
+	ctx := context.Background()
+	assert.NoError(t, cache.Set(ctx, "a", "1", time.Minute))
+	assert.NoError(t, cache.Set(ctx, "b", "2", 0))
+
+	val, err := cache.Get(ctx, "a")
+	assert.NoError(t, err)
+	assert.Equal(t, "1", val)
+	n, err := cache.Exists(ctx, "a", "b", "c")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), n)
+
+	items, err := cache.GetAll(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, items, 2)
+	assert.True(t, fake.Now().Add(time.Minute).Equal(items[0].ExpiresAt))
+	assert.True(t, items[1].ExpiresAt.IsZero())
+
+	// 过期后视为不存在
+	fake.Advance(time.Minute)
+	val, err = cache.Get(ctx, "a")
+	assert.NoError(t, err)
+	assert.Equal(t, "", val)
+	n, _ = cache.Exists(ctx, "a")
+	assert.Equal(t, int64(0), n)
+
+	assert.NoError(t, cache.Expire(ctx, "b", time.Second))
+	fake.Advance(time.Second)
+	items, err = cache.GetAll(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, items)
+
+	assert.NoError(t, cache.Set(ctx, "c", "3", time.Hour))
+	assert.NoError(t, cache.Del(ctx, "c"))
+	val, _ = cache.Get(ctx, "c")
+	assert.Equal(t, "", val)
+}
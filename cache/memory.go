@@ -5,6 +5,8 @@ import (
 	"context"
 	"sync"
 	"time"
+
+	"github.com/wind959/ko-utils/clock"
 )
 
 // memoryHelper 内存缓存助手实现
@@ -14,7 +16,18 @@ type memoryHelper struct {
 	mutex       sync.RWMutex
 	ctx         context.Context
 	stopChan    chan struct{}
-	cleanupTick *time.Timer
+	cleanupTick clock.Timer
+	clock       clock.Clock
+}
+
+// MemoryOption 内存缓存配置选项
+type MemoryOption func(*memoryHelper)
+
+// WithClock 设置时钟,测试中可传入 clock.NewFake 控制过期
+func WithClock(c clock.Clock) MemoryOption {
+	return func(m *memoryHelper) {
+		m.clock = clock.OrReal(c)
+	}
 }
 
 // cacheItem 缓存项结构
@@ -65,11 +78,15 @@ func (h *expiryHeap) Pop() interface{} {
 }
 
 // NewMemoryHelper 创建内存缓存助手实例
-func NewMemoryHelper() CacheInterface {
+func NewMemoryHelper(opts ...MemoryOption) CacheInterface {
 	mh := &memoryHelper{
 		data:     make(map[string]*cacheItem),
 		ctx:      context.Background(),
 		stopChan: make(chan struct{}),
+		clock:    clock.Real,
+	}
+	for _, opt := range opts {
+		opt(mh)
 	}
 	// 启动后台清理goroutine
 	mh.startCleanup()
@@ -85,7 +102,7 @@ func (m *memoryHelper) Set(ctx context.Context, key string, value string, expira
 func (m *memoryHelper) SetVal(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
-	expirationTime := time.Now().Add(expiration)
+	expirationTime := m.clock.Now().Add(expiration)
 	item := &cacheItem{
 		key:        key,
 		value:      value,
@@ -125,7 +142,7 @@ func (m *memoryHelper) GetVal(ctx context.Context, key string) (interface{}, err
 		return nil, nil
 	}
 	// 检查是否过期
-	if time.Now().After(item.expiration) {
+	if m.clock.Now().After(item.expiration) {
 		return nil, nil
 	}
 	return item.value, nil
@@ -156,7 +173,7 @@ func (m *memoryHelper) Exists(ctx context.Context, keys ...string) (int64, error
 	for _, key := range keys {
 		if item, exists := m.data[key]; exists {
 			// 检查是否过期
-			if time.Now().After(item.expiration) {
+			if m.clock.Now().After(item.expiration) {
 				continue
 			}
 			count++
@@ -177,7 +194,7 @@ func (m *memoryHelper) Expire(ctx context.Context, key string, expiration time.D
 	// 先从堆中移除
 	heap.Remove(&m.expiryQueue, item.index)
 	// 更新过期时间
-	item.expiration = time.Now().Add(expiration)
+	item.expiration = m.clock.Now().Add(expiration)
 	// 重新加入堆
 	heap.Push(&m.expiryQueue, item)
 	// 重置定时器
@@ -190,7 +207,7 @@ func (m *memoryHelper) GetAll(ctx context.Context) ([]CacheItem, error) {
 	m.mutex.RLock()
 	defer m.mutex.RUnlock()
 	var items []CacheItem
-	now := time.Now()
+	now := m.clock.Now()
 	for _, item := range m.data {
 		// 跳过已过期的项
 		if now.After(item.expiration) {
@@ -207,16 +224,16 @@ func (m *memoryHelper) GetAll(ctx context.Context) ([]CacheItem, error) {
 
 // startCleanup 启动后台清理goroutine
 func (m *memoryHelper) startCleanup() {
+	// 定时器只创建一次,之后通过 Reset 调整,保证后台goroutine始终等待同一个通道
+	m.cleanupTick = m.clock.NewTimer(time.Hour)
 	m.resetCleanupTimer()
 	go func() {
 		for {
 			select {
-			case <-m.cleanupTick.C:
+			case <-m.cleanupTick.C():
 				m.cleanupExpired()
 			case <-m.stopChan:
-				if m.cleanupTick != nil {
-					m.cleanupTick.Stop()
-				}
+				m.cleanupTick.Stop()
 				return
 			}
 		}
@@ -225,22 +242,20 @@ func (m *memoryHelper) startCleanup() {
 
 // resetCleanupTimer 重置清理定时器
 func (m *memoryHelper) resetCleanupTimer() {
-	if m.cleanupTick != nil {
-		m.cleanupTick.Stop()
-	}
+	m.cleanupTick.Stop()
 	if m.expiryQueue.Len() == 0 {
 		// 没有数据，设置一个较长的定时器
-		m.cleanupTick = time.NewTimer(1 * time.Hour)
+		m.cleanupTick.Reset(1 * time.Hour)
 		return
 	}
 	nextExpiry := m.expiryQueue[0].expiration
-	now := time.Now()
+	now := m.clock.Now()
 	if nextExpiry.Before(now) {
 		// 已经过期，立即清理
-		m.cleanupTick = time.NewTimer(0)
+		m.cleanupTick.Reset(0)
 	} else {
 		// 设置定时器到下一个过期时间
-		m.cleanupTick = time.NewTimer(nextExpiry.Sub(now))
+		m.cleanupTick.Reset(nextExpiry.Sub(now))
 	}
 }
 
@@ -248,7 +263,7 @@ func (m *memoryHelper) resetCleanupTimer() {
 func (m *memoryHelper) cleanupExpired() {
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
-	now := time.Now()
+	now := m.clock.Now()
 	for m.expiryQueue.Len() > 0 {
 		item := m.expiryQueue[0]
 		if now.Before(item.expiration) {
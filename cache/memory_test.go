@@ -3,6 +3,7 @@ package cache
 import (
 	"context"
 	"github.com/stretchr/testify/assert"
+	"github.com/wind959/ko-utils/clock"
 	"reflect"
 	"sync"
 	"testing"
@@ -548,6 +549,31 @@ func TestMemoryHelper_AutoCleanup(t *testing.T) {
 	}
 }
 
+// TestMemoryHelper_FakeClock 使用模拟时钟测试过期和自动清理，无需真实等待
+func TestMemoryHelper_FakeClock(t *testing.T) {
+	fake := clock.NewFake(time.Time{})
+	cache := NewMemoryHelper(WithClock(fake)).(*memoryHelper)
+	defer cache.Close()
+
+	ctx := context.Background()
+	assert.NoError(t, cache.Set(ctx, "k", "v", time.Minute))
+
+	fake.Advance(59 * time.Second)
+	val, _ := cache.Get(ctx, "k")
+	assert.Equal(t, "v", val)
+
+	fake.Advance(2 * time.Second)
+	val, _ = cache.Get(ctx, "k")
+	assert.Equal(t, "", val)
+
+	// 后台清理由模拟定时器触发
+	assert.Eventually(t, func() bool {
+		cache.mutex.RLock()
+		defer cache.mutex.RUnlock()
+		return len(cache.data) == 0
+	}, time.Second, time.Millisecond)
+}
+
 // BenchmarkMemoryHelper_Set 性能测试：设置操作
 func BenchmarkMemoryHelper_Set(b *testing.B) {
 	cache := NewMemoryHelper()
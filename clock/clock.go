@@ -0,0 +1,96 @@
+package clock
+
+import (
+	"time"
+)
+
+// Clock 时钟接口,用于替换直接调用 time 包,方便在测试中控制时间
+type Clock interface {
+	// Now 返回当前时间
+	Now() time.Time
+	// After 在d之后向返回的通道发送当前时间
+	After(d time.Duration) <-chan time.Time
+	// NewTimer 创建定时器
+	NewTimer(d time.Duration) Timer
+	// Sleep 阻塞d时长
+	Sleep(d time.Duration)
+}
+
+// Timer 定时器接口,语义与 time.Timer 一致
+type Timer interface {
+	// C 返回定时器触发时的通道
+	C() <-chan time.Time
+	// Stop 停止定时器,定时器尚未触发时返回true
+	Stop() bool
+	// Reset 重新设置定时器的触发时长,定时器尚未触发时返回true
+	Reset(d time.Duration) bool
+}
+
+// realClock 基于 time 包的真实时钟
+type realClock struct{}
+
+// realTimer 包装 time.Timer
+type realTimer struct {
+	timer *time.Timer
+}
+
+// Real 真实时钟
+var Real Clock = realClock{}
+
+// New 返回真实时钟
+func New() Clock {
+	return Real
+}
+
+// OrReal 非nil时返回c,否则返回真实时钟,方便在选项中处理未设置的时钟
+func OrReal(c Clock) Clock {
+	if c == nil {
+		return Real
+	}
+	return c
+}
+
+// Since 返回c的当前时间与t的差值
+func Since(c Clock, t time.Time) time.Duration {
+	return c.Now().Sub(t)
+}
+
+// Until 返回t与c的当前时间的差值
+func Until(c Clock, t time.Time) time.Duration {
+	return t.Sub(c.Now())
+}
+
+// Now 返回当前时间
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+// After 等同于 time.After
+func (realClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+// NewTimer 等同于 time.NewTimer
+func (realClock) NewTimer(d time.Duration) Timer {
+	return &realTimer{timer: time.NewTimer(d)}
+}
+
+// Sleep 等同于 time.Sleep
+func (realClock) Sleep(d time.Duration) {
+	time.Sleep(d)
+}
+
+// C 返回定时器通道
+func (t *realTimer) C() <-chan time.Time {
+	return t.timer.C
+}
+
+// Stop 停止定时器
+func (t *realTimer) Stop() bool {
+	return t.timer.Stop()
+}
+
+// Reset 重置定时器
+func (t *realTimer) Reset(d time.Duration) bool {
+	return t.timer.Reset(d)
+}
@@ -0,0 +1,57 @@
+package clock
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFakeClock(t *testing.T) {
+	start := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	fake := NewFake(start)
+	assert.Equal(t, start, fake.Now())
+
+	after := fake.After(10 * time.Second)
+	timer := fake.NewTimer(time.Minute)
+	assert.Equal(t, 2, fake.Waiters())
+
+	fake.Advance(9 * time.Second)
+	select {
+	case <-after:
+		t.Fatal("fired too early")
+	default:
+	}
+
+	fake.Advance(time.Second)
+	assert.Equal(t, start.Add(10*time.Second), <-after)
+	assert.Equal(t, 1, fake.Waiters())
+
+	// 停止后不再触发,Reset 重新计时
+	assert.True(t, timer.Stop())
+	assert.False(t, timer.Stop())
+	fake.Advance(time.Hour)
+	select {
+	case <-timer.C():
+		t.Fatal("stopped timer fired")
+	default:
+	}
+	assert.False(t, timer.Reset(time.Second))
+	fake.Advance(time.Second)
+	<-timer.C()
+
+	// Sleep 在另一个goroutine中阻塞,直到时间被推进
+	done := make(chan struct{})
+	go func() {
+		fake.Sleep(5 * time.Minute)
+		close(done)
+	}()
+	fake.BlockUntil(1)
+	fake.Advance(5 * time.Minute)
+	<-done
+
+	assert.WithinDuration(t, time.Now(), Real.Now(), time.Second)
+	assert.Same(t, fake, OrReal(fake))
+	assert.Equal(t, Real, OrReal(nil))
+	assert.Equal(t, time.Minute, Until(fake, fake.Now().Add(time.Minute)))
+}
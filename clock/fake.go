@@ -0,0 +1,181 @@
+package clock
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// FakeClock 可手动推进的模拟时钟,只有调用 Advance 或 Set 时时间才会前进
+type FakeClock struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	now     time.Time
+	waiters []*fakeTimer
+}
+
+// fakeTimer 模拟定时器
+type fakeTimer struct {
+	clock    *FakeClock
+	deadline time.Time
+	ch       chan time.Time
+	active   bool
+}
+
+// NewFake 创建模拟时钟,start 为零值时使用固定的 2024-01-01 00:00:00 UTC
+func NewFake(start time.Time) *FakeClock {
+	if start.IsZero() {
+		start = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	}
+	f := &FakeClock{now: start}
+	f.cond = sync.NewCond(&f.mu)
+	return f
+}
+
+// Now 返回模拟的当前时间
+func (f *FakeClock) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+// After 在模拟时间推进d之后向通道发送时间
+func (f *FakeClock) After(d time.Duration) <-chan time.Time {
+	return f.NewTimer(d).C()
+}
+
+// NewTimer 创建模拟定时器,d <= 0 时立即触发
+func (f *FakeClock) NewTimer(d time.Duration) Timer {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	t := &fakeTimer{clock: f, ch: make(chan time.Time, 1)}
+	f.schedule(t, d)
+	return t
+}
+
+// Sleep 阻塞直到模拟时间推进d
+func (f *FakeClock) Sleep(d time.Duration) {
+	<-f.After(d)
+}
+
+// Advance 将模拟时间推进d,并按到期顺序触发到期的定时器
+func (f *FakeClock) Advance(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.setLocked(f.now.Add(d))
+}
+
+// Set 将模拟时间设置为t,t 早于当前时间时不会触发任何定时器
+func (f *FakeClock) Set(t time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.setLocked(t)
+}
+
+// Waiters 返回尚未触发的定时器(含 After、Sleep)数量
+func (f *FakeClock) Waiters() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.waiters)
+}
+
+// BlockUntil 阻塞直到尚未触发的定时器数量达到n,用于等待被测代码进入 Sleep 或 select
+func (f *FakeClock) BlockUntil(n int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for len(f.waiters) < n {
+		f.cond.Wait()
+	}
+}
+
+// setLocked 设置时间并触发到期的定时器,调用方需持有锁
+func (f *FakeClock) setLocked(t time.Time) {
+	if t.After(f.now) {
+		f.now = t
+	}
+
+	sort.SliceStable(f.waiters, func(i, j int) bool {
+		return f.waiters[i].deadline.Before(f.waiters[j].deadline)
+	})
+	remaining := f.waiters[:0]
+	for _, w := range f.waiters {
+		if w.deadline.After(f.now) {
+			remaining = append(remaining, w)
+			continue
+		}
+		w.active = false
+		select {
+		case w.ch <- f.now:
+		default:
+		}
+	}
+	for i := len(remaining); i < len(f.waiters); i++ {
+		f.waiters[i] = nil
+	}
+	f.waiters = remaining
+	f.cond.Broadcast()
+}
+
+// schedule 注册定时器,调用方需持有锁
+func (f *FakeClock) schedule(t *fakeTimer, d time.Duration) {
+	t.deadline = f.now.Add(d)
+	if d <= 0 {
+		t.active = false
+		select {
+		case t.ch <- f.now:
+		default:
+		}
+		return
+	}
+	t.active = true
+	f.waiters = append(f.waiters, t)
+	f.cond.Broadcast()
+}
+
+// remove 移除定时器,调用方需持有锁
+func (f *FakeClock) remove(t *fakeTimer) {
+	for i, w := range f.waiters {
+		if w == t {
+			f.waiters = append(f.waiters[:i], f.waiters[i+1:]...)
+			return
+		}
+	}
+}
+
+// C 返回定时器通道
+func (t *fakeTimer) C() <-chan time.Time {
+	return t.ch
+}
+
+// Stop 停止定时器,并丢弃尚未读取的触发值,与Go 1.23之后的 time.Timer 行为一致
+func (t *fakeTimer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+
+	wasActive := t.active
+	t.active = false
+	t.clock.remove(t)
+	t.drain()
+	return wasActive
+}
+
+// Reset 重新设置定时器
+func (t *fakeTimer) Reset(d time.Duration) bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+
+	wasActive := t.active
+	t.clock.remove(t)
+	t.drain()
+	t.clock.schedule(t, d)
+	return wasActive
+}
+
+// drain 丢弃通道中未读取的值
+func (t *fakeTimer) drain() {
+	select {
+	case <-t.ch:
+	default:
+	}
+}
@@ -0,0 +1,525 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"time"
+)
+
+// CertKeyType 证书私钥类型
+type CertKeyType string
+
+const (
+	CertKeyECDSA   CertKeyType = "ECDSA"   // ECDSA P-256
+	CertKeyRSA     CertKeyType = "RSA"     // RSA 2048
+	CertKeyEd25519 CertKeyType = "Ed25519" // Ed25519
+)
+
+// DefaultCertValidity 默认证书有效期
+const DefaultCertValidity = 365 * 24 * time.Hour
+
+// CertConfig 证书配置
+type CertConfig struct {
+	CommonName     string             // 通用名称
+	Organization   []string           // 组织
+	DNSNames       []string           // DNS SAN
+	IPAddresses    []net.IP           // IP SAN
+	EmailAddresses []string           // 邮箱 SAN
+	NotBefore      time.Time          // 生效时间,默认当前时间前1分钟
+	Validity       time.Duration      // 有效期
+	KeyType        CertKeyType        // 私钥类型
+	KeyUsage       x509.KeyUsage      // 密钥用途
+	ExtKeyUsage    []x509.ExtKeyUsage // 扩展密钥用途
+}
+
+// CertOption 证书配置选项
+type CertOption func(*CertConfig)
+
+// WithCertCommonName 设置通用名称
+func WithCertCommonName(cn string) CertOption {
+	return func(c *CertConfig) {
+		c.CommonName = cn
+	}
+}
+
+// WithCertOrganization 设置组织
+func WithCertOrganization(org ...string) CertOption {
+	return func(c *CertConfig) {
+		c.Organization = org
+	}
+}
+
+// WithCertHosts 设置SAN,自动区分IP和域名
+func WithCertHosts(hosts ...string) CertOption {
+	return func(c *CertConfig) {
+		for _, h := range hosts {
+			if ip := net.ParseIP(h); ip != nil {
+				c.IPAddresses = append(c.IPAddresses, ip)
+			} else {
+				c.DNSNames = append(c.DNSNames, h)
+			}
+		}
+	}
+}
+
+// WithCertEmails 设置邮箱SAN
+func WithCertEmails(emails ...string) CertOption {
+	return func(c *CertConfig) {
+		c.EmailAddresses = append(c.EmailAddresses, emails...)
+	}
+}
+
+// WithCertNotBefore 设置生效时间
+func WithCertNotBefore(t time.Time) CertOption {
+	return func(c *CertConfig) {
+		c.NotBefore = t
+	}
+}
+
+// WithCertValidity 设置有效期
+func WithCertValidity(d time.Duration) CertOption {
+	return func(c *CertConfig) {
+		c.Validity = d
+	}
+}
+
+// WithCertKeyType 设置私钥类型
+func WithCertKeyType(keyType CertKeyType) CertOption {
+	return func(c *CertConfig) {
+		c.KeyType = keyType
+	}
+}
+
+// WithCertKeyUsage 设置密钥用途,覆盖默认值
+func WithCertKeyUsage(usage x509.KeyUsage) CertOption {
+	return func(c *CertConfig) {
+		c.KeyUsage = usage
+	}
+}
+
+// WithCertExtKeyUsage 设置扩展密钥用途,覆盖默认值
+func WithCertExtKeyUsage(usage ...x509.ExtKeyUsage) CertOption {
+	return func(c *CertConfig) {
+		c.ExtKeyUsage = usage
+	}
+}
+
+// CertBundle 证书及其私钥
+type CertBundle struct {
+	Cert    *x509.Certificate
+	Key     crypto.Signer // 由CSR签发时为nil
+	CertPEM []byte
+	KeyPEM  []byte
+}
+
+// TLSCertificate 转换为tls.Certificate,chain为附加的中间证书
+func (b *CertBundle) TLSCertificate(chain ...*x509.Certificate) (tls.Certificate, error) {
+	if b.Key == nil {
+		return tls.Certificate{}, errors.New("cert: bundle has no private key")
+	}
+	tlsCert := tls.Certificate{
+		Certificate: [][]byte{b.Cert.Raw},
+		PrivateKey:  b.Key,
+		Leaf:        b.Cert,
+	}
+	for _, c := range chain {
+		tlsCert.Certificate = append(tlsCert.Certificate, c.Raw)
+	}
+	return tlsCert, nil
+}
+
+// CsrBundle 证书签名请求及其私钥
+type CsrBundle struct {
+	CSR    *x509.CertificateRequest
+	Key    crypto.Signer
+	CSRPEM []byte
+	KeyPEM []byte
+}
+
+// CertExpiryInfo 证书到期信息
+type CertExpiryInfo struct {
+	Subject   string
+	NotAfter  time.Time
+	Remaining time.Duration
+	Expired   bool
+}
+
+// CreateCA 创建自签名CA证书
+func CreateCA(opts ...CertOption) (*CertBundle, error) {
+	cfg := newCertConfig(opts, x509.KeyUsageCertSign|x509.KeyUsageCRLSign|x509.KeyUsageDigitalSignature, nil)
+	if cfg.CommonName == "" {
+		cfg.CommonName = "ko-utils CA"
+	}
+
+	key, err := generateCertKey(cfg.KeyType)
+	if err != nil {
+		return nil, err
+	}
+
+	tmpl, err := newCertTemplate(cfg)
+	if err != nil {
+		return nil, err
+	}
+	tmpl.IsCA = true
+	tmpl.BasicConstraintsValid = true
+
+	return signCert(tmpl, tmpl, key.Public(), key, key)
+}
+
+// GenerateCSR 生成私钥及证书签名请求
+func GenerateCSR(opts ...CertOption) (*CsrBundle, error) {
+	cfg := newCertConfig(opts, 0, nil)
+
+	key, err := generateCertKey(cfg.KeyType)
+	if err != nil {
+		return nil, err
+	}
+
+	der, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject:        pkix.Name{CommonName: cfg.CommonName, Organization: cfg.Organization},
+		DNSNames:       cfg.DNSNames,
+		IPAddresses:    cfg.IPAddresses,
+		EmailAddresses: cfg.EmailAddresses,
+	}, key)
+	if err != nil {
+		return nil, fmt.Errorf("cert: failed to create csr: %w", err)
+	}
+	csr, err := x509.ParseCertificateRequest(der)
+	if err != nil {
+		return nil, err
+	}
+	keyPEM, err := encodeKeyPEM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CsrBundle{
+		CSR:    csr,
+		Key:    key,
+		CSRPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: der}),
+		KeyPEM: keyPEM,
+	}, nil
+}
+
+// IssueCertFromCSR 使用CA签发CSR,主题和SAN取自CSR,opts可设置有效期与用途
+func IssueCertFromCSR(ca *CertBundle, csrPEM []byte, opts ...CertOption) (*CertBundle, error) {
+	if err := checkIssuer(ca); err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(csrPEM)
+	if block == nil || block.Type != "CERTIFICATE REQUEST" {
+		return nil, errors.New("cert: failed to decode PEM block containing the csr")
+	}
+	csr, err := x509.ParseCertificateRequest(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	if err := csr.CheckSignature(); err != nil {
+		return nil, fmt.Errorf("cert: invalid csr signature: %w", err)
+	}
+
+	cfg := newCertConfig(opts, x509.KeyUsageDigitalSignature,
+		[]x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth})
+	cfg.CommonName = csr.Subject.CommonName
+	cfg.Organization = csr.Subject.Organization
+	cfg.DNSNames = csr.DNSNames
+	cfg.IPAddresses = csr.IPAddresses
+	cfg.EmailAddresses = csr.EmailAddresses
+
+	tmpl, err := newCertTemplate(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return signCert(tmpl, ca.Cert, csr.PublicKey, ca.Key, nil)
+}
+
+// IssueServerCert 使用CA签发服务端证书,未指定SAN时默认为 localhost、127.0.0.1 和 ::1
+func IssueServerCert(ca *CertBundle, opts ...CertOption) (*CertBundle, error) {
+	cfg := newCertConfig(opts, x509.KeyUsageDigitalSignature, []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth})
+	if len(cfg.DNSNames) == 0 && len(cfg.IPAddresses) == 0 {
+		WithCertHosts("localhost", "127.0.0.1", "::1")(cfg)
+	}
+	if cfg.CommonName == "" && len(cfg.DNSNames) > 0 {
+		cfg.CommonName = cfg.DNSNames[0]
+	}
+	return issueCert(ca, cfg)
+}
+
+// IssueClientCert 使用CA签发客户端证书
+func IssueClientCert(ca *CertBundle, opts ...CertOption) (*CertBundle, error) {
+	cfg := newCertConfig(opts, x509.KeyUsageDigitalSignature, []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth})
+	if cfg.CommonName == "" {
+		cfg.CommonName = "client"
+	}
+	return issueCert(ca, cfg)
+}
+
+// EncodeCertChainPEM 将证书链编码为PEM
+func EncodeCertChainPEM(certs ...*x509.Certificate) []byte {
+	var buf bytes.Buffer
+	for _, c := range certs {
+		_ = pem.Encode(&buf, &pem.Block{Type: "CERTIFICATE", Bytes: c.Raw})
+	}
+	return buf.Bytes()
+}
+
+// WritePemBundle 写入PEM文件,certFile 包含证书及附加的证书链,keyFile 为空时不写私钥
+func WritePemBundle(certFile, keyFile string, bundle *CertBundle, chain ...*x509.Certificate) error {
+	if bundle == nil || bundle.Cert == nil {
+		return errors.New("cert: bundle is empty")
+	}
+
+	certs := append([]*x509.Certificate{bundle.Cert}, chain...)
+	if err := os.WriteFile(certFile, EncodeCertChainPEM(certs...), 0644); err != nil {
+		return err
+	}
+
+	if keyFile == "" {
+		return nil
+	}
+	if len(bundle.KeyPEM) == 0 {
+		return errors.New("cert: bundle has no private key")
+	}
+	return os.WriteFile(keyFile, bundle.KeyPEM, 0600)
+}
+
+// ParseCertChain 解析PEM编码的证书链,忽略非证书块
+func ParseCertChain(pemData []byte) ([]*x509.Certificate, error) {
+	var certs []*x509.Certificate
+	for {
+		var block *pem.Block
+		block, pemData = pem.Decode(pemData)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		certs = append(certs, cert)
+	}
+
+	if len(certs) == 0 {
+		return nil, errors.New("cert: no certificate found in PEM data")
+	}
+	return certs, nil
+}
+
+// ParseCertChainFile 从文件解析证书链
+func ParseCertChainFile(path string) ([]*x509.Certificate, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseCertChain(data)
+}
+
+// CertExpiry 返回证书到期信息
+func CertExpiry(cert *x509.Certificate) CertExpiryInfo {
+	remaining := time.Until(cert.NotAfter)
+	return CertExpiryInfo{
+		Subject:   cert.Subject.String(),
+		NotAfter:  cert.NotAfter,
+		Remaining: remaining,
+		Expired:   remaining <= 0,
+	}
+}
+
+// CertChainExpiry 返回证书链中最早到期的证书信息
+func CertChainExpiry(certs []*x509.Certificate) (CertExpiryInfo, error) {
+	if len(certs) == 0 {
+		return CertExpiryInfo{}, errors.New("cert: empty certificate chain")
+	}
+	earliest := certs[0]
+	for _, c := range certs[1:] {
+		if c.NotAfter.Before(earliest.NotAfter) {
+			earliest = c
+		}
+	}
+	return CertExpiry(earliest), nil
+}
+
+// NewMTLSConfigPair 创建一对互相信任的双向TLS配置,用于测试,
+// hosts 为服务端证书的SAN,为空时默认为 localhost、127.0.0.1 和 ::1
+func NewMTLSConfigPair(hosts ...string) (serverCfg, clientCfg *tls.Config, err error) {
+	ca, err := CreateCA(WithCertCommonName("ko-utils test CA"))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var serverOpts []CertOption
+	if len(hosts) > 0 {
+		serverOpts = append(serverOpts, WithCertHosts(hosts...))
+	}
+	server, err := IssueServerCert(ca, serverOpts...)
+	if err != nil {
+		return nil, nil, err
+	}
+	client, err := IssueClientCert(ca)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	serverTLS, err := server.TLSCertificate()
+	if err != nil {
+		return nil, nil, err
+	}
+	clientTLS, err := client.TLSCertificate()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.Cert)
+
+	serverCfg = &tls.Config{
+		Certificates: []tls.Certificate{serverTLS},
+		ClientCAs:    pool,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		MinVersion:   tls.VersionTLS12,
+	}
+	clientCfg = &tls.Config{
+		Certificates: []tls.Certificate{clientTLS},
+		RootCAs:      pool,
+		MinVersion:   tls.VersionTLS12,
+	}
+	if len(server.Cert.DNSNames) > 0 {
+		clientCfg.ServerName = server.Cert.DNSNames[0]
+	}
+	return serverCfg, clientCfg, nil
+}
+
+// newCertConfig 合并默认配置
+func newCertConfig(opts []CertOption, usage x509.KeyUsage, extUsage []x509.ExtKeyUsage) *CertConfig {
+	cfg := &CertConfig{
+		Validity:    DefaultCertValidity,
+		KeyType:     CertKeyECDSA,
+		KeyUsage:    usage,
+		ExtKeyUsage: extUsage,
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if cfg.NotBefore.IsZero() {
+		// 预留时钟偏差
+		cfg.NotBefore = time.Now().Add(-time.Minute)
+	}
+	if cfg.Validity <= 0 {
+		cfg.Validity = DefaultCertValidity
+	}
+	return cfg
+}
+
+// newCertTemplate 根据配置生成证书模板
+func newCertTemplate(cfg *CertConfig) (*x509.Certificate, error) {
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, fmt.Errorf("cert: failed to generate serial number: %w", err)
+	}
+
+	return &x509.Certificate{
+		SerialNumber:   serial,
+		Subject:        pkix.Name{CommonName: cfg.CommonName, Organization: cfg.Organization},
+		NotBefore:      cfg.NotBefore,
+		NotAfter:       cfg.NotBefore.Add(cfg.Validity),
+		KeyUsage:       cfg.KeyUsage,
+		ExtKeyUsage:    cfg.ExtKeyUsage,
+		DNSNames:       cfg.DNSNames,
+		IPAddresses:    cfg.IPAddresses,
+		EmailAddresses: cfg.EmailAddresses,
+	}, nil
+}
+
+// issueCert 生成私钥并使用CA签发证书
+func issueCert(ca *CertBundle, cfg *CertConfig) (*CertBundle, error) {
+	if err := checkIssuer(ca); err != nil {
+		return nil, err
+	}
+
+	key, err := generateCertKey(cfg.KeyType)
+	if err != nil {
+		return nil, err
+	}
+	tmpl, err := newCertTemplate(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return signCert(tmpl, ca.Cert, key.Public(), ca.Key, key)
+}
+
+// signCert 签发证书,key 为证书持有者私钥,可以为nil
+func signCert(tmpl, parent *x509.Certificate, pub crypto.PublicKey, signer, key crypto.Signer) (*CertBundle, error) {
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, parent, pub, signer)
+	if err != nil {
+		return nil, fmt.Errorf("cert: failed to create certificate: %w", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, err
+	}
+
+	bundle := &CertBundle{
+		Cert:    cert,
+		Key:     key,
+		CertPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+	}
+	if key != nil {
+		if bundle.KeyPEM, err = encodeKeyPEM(key); err != nil {
+			return nil, err
+		}
+	}
+	return bundle, nil
+}
+
+// checkIssuer 校验签发者是否为可用的CA
+func checkIssuer(ca *CertBundle) error {
+	if ca == nil || ca.Cert == nil || ca.Key == nil {
+		return errors.New("cert: ca certificate and key are required")
+	}
+	if !ca.Cert.IsCA {
+		return errors.New("cert: issuer is not a CA")
+	}
+	return nil
+}
+
+// generateCertKey 生成指定类型的私钥
+func generateCertKey(keyType CertKeyType) (crypto.Signer, error) {
+	switch keyType {
+	case CertKeyECDSA, "":
+		return ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case CertKeyRSA:
+		return rsa.GenerateKey(rand.Reader, 2048)
+	case CertKeyEd25519:
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		return key, err
+	default:
+		return nil, fmt.Errorf("cert: unsupported key type %q", keyType)
+	}
+}
+
+// encodeKeyPEM 将私钥编码为PKCS#8 PEM
+func encodeKeyPEM(key crypto.Signer) ([]byte, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("cert: failed to marshal private key: %w", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+}
@@ -0,0 +1,71 @@
+package crypto
+
+import (
+	"crypto/x509"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMTLSConfigPair(t *testing.T) {
+	serverCfg, clientCfg, err := NewMTLSConfigPair()
+	assert.NoError(t, err)
+
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, r.TLS.PeerCertificates[0].Subject.CommonName)
+	}))
+	srv.TLS = serverCfg
+	srv.StartTLS()
+	defer srv.Close()
+
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: clientCfg}}
+	resp, err := client.Get(srv.URL)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	assert.Equal(t, "client", string(body))
+
+	// 不携带客户端证书时握手失败
+	noCert := clientCfg.Clone()
+	noCert.Certificates = nil
+	client = &http.Client{Transport: &http.Transport{TLSClientConfig: noCert}}
+	_, err = client.Get(srv.URL)
+	assert.Error(t, err)
+}
+
+func TestIssueCertFromCSR(t *testing.T) {
+	ca, err := CreateCA(WithCertKeyType(CertKeyRSA))
+	assert.NoError(t, err)
+
+	csr, err := GenerateCSR(WithCertCommonName("api.example.com"), WithCertHosts("api.example.com", "10.0.0.1"))
+	assert.NoError(t, err)
+
+	leaf, err := IssueCertFromCSR(ca, csr.CSRPEM, WithCertValidity(48*time.Hour))
+	assert.NoError(t, err)
+	assert.Nil(t, leaf.Key)
+	assert.Equal(t, []string{"api.example.com"}, leaf.Cert.DNSNames)
+	assert.Equal(t, "10.0.0.1", leaf.Cert.IPAddresses[0].String())
+
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.Cert)
+	_, err = leaf.Cert.Verify(x509.VerifyOptions{DNSName: "api.example.com", Roots: pool})
+	assert.NoError(t, err)
+
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "leaf.pem")
+	assert.NoError(t, WritePemBundle(certFile, "", leaf, ca.Cert))
+
+	chain, err := ParseCertChainFile(certFile)
+	assert.NoError(t, err)
+	assert.Len(t, chain, 2)
+
+	info, err := CertChainExpiry(chain)
+	assert.NoError(t, err)
+	assert.False(t, info.Expired)
+	assert.True(t, info.Remaining <= 48*time.Hour)
+}
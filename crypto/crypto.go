@@ -13,9 +13,11 @@ import (
 	"encoding/hex"
 	"encoding/pem"
 	"errors"
+	"fmt"
 	"github.com/tjfoc/gmsm/sm2"
 	"github.com/tjfoc/gmsm/sm3"
 	"github.com/tjfoc/gmsm/sm4"
+	"golang.org/x/crypto/chacha20poly1305"
 	"io"
 	"os"
 )
@@ -359,6 +361,66 @@ func AesGcmDecrypt(data, key []byte) []byte {
 	return plaintext
 }
 
+// ChaCha20Poly1305Encrypt ChaCha20-Poly1305 AEAD加密, key为32字节, nonce为12字节
+func ChaCha20Poly1305Encrypt(data, key, nonce, additionalData []byte) ([]byte, error) {
+	aead, err := chacha20poly1305.New(key)
+	if err != nil {
+		return nil, fmt.Errorf("chacha20poly1305: %w", err)
+	}
+	if len(nonce) != aead.NonceSize() {
+		return nil, fmt.Errorf("chacha20poly1305: invalid nonce length (must be %d bytes)", aead.NonceSize())
+	}
+
+	return aead.Seal(nil, nonce, data, additionalData), nil
+}
+
+// ChaCha20Poly1305Decrypt ChaCha20-Poly1305 AEAD解密
+func ChaCha20Poly1305Decrypt(encrypted, key, nonce, additionalData []byte) ([]byte, error) {
+	aead, err := chacha20poly1305.New(key)
+	if err != nil {
+		return nil, fmt.Errorf("chacha20poly1305: %w", err)
+	}
+	if len(nonce) != aead.NonceSize() {
+		return nil, fmt.Errorf("chacha20poly1305: invalid nonce length (must be %d bytes)", aead.NonceSize())
+	}
+
+	plaintext, err := aead.Open(nil, nonce, encrypted, additionalData)
+	if err != nil {
+		return nil, fmt.Errorf("chacha20poly1305: decryption failed: %w", err)
+	}
+	return plaintext, nil
+}
+
+// XChaCha20Poly1305Encrypt XChaCha20-Poly1305 AEAD加密, key为32字节, nonce为24字节
+func XChaCha20Poly1305Encrypt(data, key, nonce, additionalData []byte) ([]byte, error) {
+	aead, err := chacha20poly1305.NewX(key)
+	if err != nil {
+		return nil, fmt.Errorf("xchacha20poly1305: %w", err)
+	}
+	if len(nonce) != aead.NonceSize() {
+		return nil, fmt.Errorf("xchacha20poly1305: invalid nonce length (must be %d bytes)", aead.NonceSize())
+	}
+
+	return aead.Seal(nil, nonce, data, additionalData), nil
+}
+
+// XChaCha20Poly1305Decrypt XChaCha20-Poly1305 AEAD解密
+func XChaCha20Poly1305Decrypt(encrypted, key, nonce, additionalData []byte) ([]byte, error) {
+	aead, err := chacha20poly1305.NewX(key)
+	if err != nil {
+		return nil, fmt.Errorf("xchacha20poly1305: %w", err)
+	}
+	if len(nonce) != aead.NonceSize() {
+		return nil, fmt.Errorf("xchacha20poly1305: invalid nonce length (must be %d bytes)", aead.NonceSize())
+	}
+
+	plaintext, err := aead.Open(nil, nonce, encrypted, additionalData)
+	if err != nil {
+		return nil, fmt.Errorf("xchacha20poly1305: decryption failed: %w", err)
+	}
+	return plaintext, nil
+}
+
 // DesEcbEncrypt DES ECB模式加密
 func DesEcbEncrypt(data, key []byte) []byte {
 	cipher, err := des.NewCipher(generateDesKey(key))
@@ -624,6 +686,132 @@ func DesOfbDecrypt(data, key []byte) []byte {
 	return decrypted
 }
 
+// TripleDesEcbEncrypt 3DES ECB模式加密, key为16字节(K1K2K1)或24字节
+func TripleDesEcbEncrypt(data, key []byte) ([]byte, error) {
+	block, err := newTripleDesCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	blockSize := block.BlockSize()
+	padded := pkcs5Padding(append([]byte(nil), data...), blockSize)
+	encrypted := make([]byte, len(padded))
+
+	for i := 0; i < len(padded); i += blockSize {
+		block.Encrypt(encrypted[i:], padded[i:])
+	}
+
+	return encrypted, nil
+}
+
+// TripleDesEcbDecrypt 3DES ECB模式解密
+func TripleDesEcbDecrypt(encrypted, key []byte) ([]byte, error) {
+	block, err := newTripleDesCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	blockSize := block.BlockSize()
+	if len(encrypted) == 0 || len(encrypted)%blockSize != 0 {
+		return nil, errors.New("3des: invalid encrypted data length")
+	}
+
+	decrypted := make([]byte, len(encrypted))
+	for i := 0; i < len(encrypted); i += blockSize {
+		block.Decrypt(decrypted[i:], encrypted[i:])
+	}
+
+	return pkcs7UnPaddingWithCheck(decrypted, blockSize)
+}
+
+// TripleDesCbcEncrypt 3DES CBC模式加密, 随机IV作为密文前缀
+func TripleDesCbcEncrypt(data, key []byte) ([]byte, error) {
+	iv := make([]byte, des.BlockSize)
+	if _, err := io.ReadFull(rand.Reader, iv); err != nil {
+		return nil, fmt.Errorf("3des: failed to generate IV: %w", err)
+	}
+
+	encrypted, err := TripleDesCbcEncryptWithIV(data, key, iv)
+	if err != nil {
+		return nil, err
+	}
+	return append(iv, encrypted...), nil
+}
+
+// TripleDesCbcDecrypt 3DES CBC模式解密, 密文需以IV为前缀
+func TripleDesCbcDecrypt(encrypted, key []byte) ([]byte, error) {
+	if len(encrypted) < des.BlockSize {
+		return nil, errors.New("3des: ciphertext too short")
+	}
+	return TripleDesCbcDecryptWithIV(encrypted[des.BlockSize:], key, encrypted[:des.BlockSize])
+}
+
+// TripleDesCbcEncryptWithIV 使用指定IV的3DES CBC模式加密, 密文不包含IV
+func TripleDesCbcEncryptWithIV(data, key, iv []byte) ([]byte, error) {
+	block, err := newTripleDesCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(iv) != des.BlockSize {
+		return nil, errors.New("3des: iv length must be 8 bytes")
+	}
+
+	padded := pkcs7Padding(append([]byte(nil), data...), des.BlockSize)
+	encrypted := make([]byte, len(padded))
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(encrypted, padded)
+
+	return encrypted, nil
+}
+
+// TripleDesCbcDecryptWithIV 使用指定IV的3DES CBC模式解密
+func TripleDesCbcDecryptWithIV(encrypted, key, iv []byte) ([]byte, error) {
+	block, err := newTripleDesCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(iv) != des.BlockSize {
+		return nil, errors.New("3des: iv length must be 8 bytes")
+	}
+	if len(encrypted) == 0 || len(encrypted)%des.BlockSize != 0 {
+		return nil, errors.New("3des: invalid encrypted data length")
+	}
+
+	decrypted := make([]byte, len(encrypted))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(decrypted, encrypted)
+
+	return pkcs7UnPaddingWithCheck(decrypted, des.BlockSize)
+}
+
+// TripleDesCtrEncrypt 3DES CTR模式加密, 随机IV作为密文前缀
+func TripleDesCtrEncrypt(data, key []byte) ([]byte, error) {
+	return tripleDesStreamEncrypt(data, key, cipher.NewCTR)
+}
+
+// TripleDesCtrDecrypt 3DES CTR模式解密
+func TripleDesCtrDecrypt(encrypted, key []byte) ([]byte, error) {
+	return tripleDesStreamDecrypt(encrypted, key, cipher.NewCTR)
+}
+
+// TripleDesCfbEncrypt 3DES CFB模式加密, 随机IV作为密文前缀
+func TripleDesCfbEncrypt(data, key []byte) ([]byte, error) {
+	return tripleDesStreamEncrypt(data, key, cipher.NewCFBEncrypter)
+}
+
+// TripleDesCfbDecrypt 3DES CFB模式解密
+func TripleDesCfbDecrypt(encrypted, key []byte) ([]byte, error) {
+	return tripleDesStreamDecrypt(encrypted, key, cipher.NewCFBDecrypter)
+}
+
+// TripleDesOfbEncrypt 3DES OFB模式加密, 随机IV作为密文前缀
+func TripleDesOfbEncrypt(data, key []byte) ([]byte, error) {
+	return tripleDesStreamEncrypt(data, key, cipher.NewOFB)
+}
+
+// TripleDesOfbDecrypt 3DES OFB模式解密
+func TripleDesOfbDecrypt(encrypted, key []byte) ([]byte, error) {
+	return tripleDesStreamDecrypt(encrypted, key, cipher.NewOFB)
+}
+
 // GenerateRsaKeyFile 在当前目录下创建rsa私钥文件和公钥文件
 func GenerateRsaKeyFile(keySize int, priKeyFile, pubKeyFile string) error {
 	// private key
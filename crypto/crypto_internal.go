@@ -3,6 +3,9 @@ package crypto
 import (
 	"bytes"
 	"crypto"
+	"crypto/cipher"
+	"crypto/des"
+	"crypto/rand"
 	"crypto/rsa"
 	"crypto/sha256"
 	"crypto/sha512"
@@ -12,6 +15,7 @@ import (
 	"errors"
 	"fmt"
 	"github.com/tjfoc/gmsm/sm2"
+	"io"
 	"math/big"
 	"os"
 	"strings"
@@ -55,6 +59,24 @@ func pkcs7UnPadding(src []byte) []byte {
 	return src[:(length - unPadding)]
 }
 
+// pkcs7UnPaddingWithCheck 校验并删除PKCS7填充
+func pkcs7UnPaddingWithCheck(src []byte, blockSize int) ([]byte, error) {
+	length := len(src)
+	if length == 0 {
+		return nil, errors.New("invalid PKCS#7 padding")
+	}
+	padding := int(src[length-1])
+	if padding == 0 || padding > blockSize || padding > length {
+		return nil, errors.New("invalid PKCS#7 padding")
+	}
+	for i := length - padding; i < length; i++ {
+		if src[i] != byte(padding) {
+			return nil, errors.New("invalid PKCS#7 padding content")
+		}
+	}
+	return src[:length-padding], nil
+}
+
 // pkcs5Padding PKCS5填充
 func pkcs5Padding(data []byte, blockSize int) []byte {
 	padding := blockSize - len(data)%blockSize
@@ -227,3 +249,52 @@ func hexToSm2PrivateKey(hexKey string) (*sm2.PrivateKey, error) {
 
 	return priv, nil
 }
+
+// newTripleDesCipher 创建3DES分组密码, 16字节密钥按 K1K2K1 扩展为24字节
+func newTripleDesCipher(key []byte) (cipher.Block, error) {
+	switch len(key) {
+	case 16:
+		key = append(append([]byte(nil), key...), key[:8]...)
+	case 24:
+	default:
+		return nil, errors.New("3des: key length must be 16 or 24 bytes")
+	}
+
+	block, err := des.NewTripleDESCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("3des: failed to create cipher: %w", err)
+	}
+	return block, nil
+}
+
+// tripleDesStreamEncrypt 3DES流模式加密, 随机IV作为密文前缀
+func tripleDesStreamEncrypt(data, key []byte, newStream func(cipher.Block, []byte) cipher.Stream) ([]byte, error) {
+	block, err := newTripleDesCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	encrypted := make([]byte, des.BlockSize+len(data))
+	iv := encrypted[:des.BlockSize]
+	if _, err := io.ReadFull(rand.Reader, iv); err != nil {
+		return nil, fmt.Errorf("3des: failed to generate IV: %w", err)
+	}
+
+	newStream(block, iv).XORKeyStream(encrypted[des.BlockSize:], data)
+	return encrypted, nil
+}
+
+// tripleDesStreamDecrypt 3DES流模式解密
+func tripleDesStreamDecrypt(encrypted, key []byte, newStream func(cipher.Block, []byte) cipher.Stream) ([]byte, error) {
+	block, err := newTripleDesCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(encrypted) < des.BlockSize {
+		return nil, errors.New("3des: ciphertext too short")
+	}
+
+	decrypted := make([]byte, len(encrypted)-des.BlockSize)
+	newStream(block, encrypted[:des.BlockSize]).XORKeyStream(decrypted, encrypted[des.BlockSize:])
+	return decrypted, nil
+}
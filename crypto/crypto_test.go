@@ -0,0 +1,165 @@
+package crypto
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wind959/ko-utils/random"
+)
+
+func TestChaCha20Poly1305(t *testing.T) {
+	key := random.RandBytes(32)
+	data := []byte("hello chacha")
+	ad := []byte("header")
+
+	nonce := random.RandBytes(12)
+	encrypted, err := ChaCha20Poly1305Encrypt(data, key, nonce, ad)
+	assert.NoError(t, err)
+	decrypted, err := ChaCha20Poly1305Decrypt(encrypted, key, nonce, ad)
+	assert.NoError(t, err)
+	assert.Equal(t, data, decrypted)
+
+	_, err = ChaCha20Poly1305Decrypt(encrypted, key, nonce, []byte("tampered"))
+	assert.Error(t, err)
+	_, err = ChaCha20Poly1305Encrypt(data, key, random.RandBytes(24), ad)
+	assert.Error(t, err)
+
+	xnonce := random.RandBytes(24)
+	encrypted, err = XChaCha20Poly1305Encrypt(data, key, xnonce, nil)
+	assert.NoError(t, err)
+	decrypted, err = XChaCha20Poly1305Decrypt(encrypted, key, xnonce, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, data, decrypted)
+
+	_, err = XChaCha20Poly1305Encrypt(data, key[:16], xnonce, nil)
+	assert.Error(t, err)
+}
+
+func TestTripleDes(t *testing.T) {
+	data := []byte("legacy bank payload")
+
+	for _, key := range [][]byte{random.RandBytes(16), random.RandBytes(24)} {
+		encrypted, err := TripleDesEcbEncrypt(data, key)
+		assert.NoError(t, err)
+		decrypted, err := TripleDesEcbDecrypt(encrypted, key)
+		assert.NoError(t, err)
+		assert.Equal(t, data, decrypted)
+
+		encrypted, err = TripleDesCbcEncrypt(data, key)
+		assert.NoError(t, err)
+		decrypted, err = TripleDesCbcDecrypt(encrypted, key)
+		assert.NoError(t, err)
+		assert.Equal(t, data, decrypted)
+
+		encrypted, err = TripleDesCtrEncrypt(data, key)
+		assert.NoError(t, err)
+		decrypted, err = TripleDesCtrDecrypt(encrypted, key)
+		assert.NoError(t, err)
+		assert.Equal(t, data, decrypted)
+
+		encrypted, err = TripleDesCfbEncrypt(data, key)
+		assert.NoError(t, err)
+		decrypted, err = TripleDesCfbDecrypt(encrypted, key)
+		assert.NoError(t, err)
+		assert.Equal(t, data, decrypted)
+
+		encrypted, err = TripleDesOfbEncrypt(data, key)
+		assert.NoError(t, err)
+		decrypted, err = TripleDesOfbDecrypt(encrypted, key)
+		assert.NoError(t, err)
+		assert.Equal(t, data, decrypted)
+	}
+
+	_, err := TripleDesEcbEncrypt(data, []byte("short"))
+	assert.Error(t, err)
+	_, err = TripleDesCbcDecrypt([]byte("12345678abc"), random.RandBytes(24))
+	assert.Error(t, err)
+}
+
+func TestChaCha20Poly1305KnownAnswer(t *testing.T) {
+	unhex := func(s string) []byte {
+		b, err := hex.DecodeString(s)
+		assert.NoError(t, err)
+		return b
+	}
+	plaintext := []byte("Ladies and Gentlemen of the class of '99: If I could offer you only one tip for the future, sunscreen would be it.")
+	key := unhex("808182838485868788898a8b8c8d8e8f909192939495969798999a9b9c9d9e9f")
+	ad := unhex("50515253c0c1c2c3c4c5c6c7")
+
+	// RFC 8439 2.8.2 节
+	nonce := unhex("070000004041424344454647")
+	want := unhex("d31a8d34648e60db7b86afbc53ef7ec2a4aded51296e08fea9e2b5a736ee62d63dbea45e8ca9671282fafb69da92728b" +
+		"1a71de0a9e060b2905d6a5b67ecd3b3692ddbd7f2d778b8c9803aee328091b58fab324e4fad675945585808b4831d7bc" +
+		"3ff4def08e4b7a9de576d26586cec64b6116" + "1ae10b594f09e26a7e902ecbd0600691")
+	encrypted, err := ChaCha20Poly1305Encrypt(plaintext, key, nonce, ad)
+	assert.NoError(t, err)
+	assert.Equal(t, want, encrypted)
+	decrypted, err := ChaCha20Poly1305Decrypt(want, key, nonce, ad)
+	assert.NoError(t, err)
+	assert.Equal(t, plaintext, decrypted)
+
+	// draft-irtf-cfrg-xchacha 附录 A.3.1
+	xnonce := unhex("404142434445464748494a4b4c4d4e4f5051525354555657")
+	want = unhex("bd6d179d3e83d43b9576579493c0e939572a1700252bfaccbed2902c21396cbb731c7f1b0b4aa6440bf3a82f4eda7e39" +
+		"ae64c6708c54c216cb96b72e1213b4522f8c9ba40db5d945b11b69b982c1bb9e3f3fac2bc369488f76b2383565d3fff9" +
+		"21f9664c97637da9768812f615c68b13b52ec0875924c1c7987947deafd8780acf49")
+	encrypted, err = XChaCha20Poly1305Encrypt(plaintext, key, xnonce, ad)
+	assert.NoError(t, err)
+	assert.Equal(t, want, encrypted)
+	decrypted, err = XChaCha20Poly1305Decrypt(want, key, xnonce, ad)
+	assert.NoError(t, err)
+	assert.Equal(t, plaintext, decrypted)
+}
+
+func TestTripleDesKnownAnswer(t *testing.T) {
+	unhex := func(s string) []byte {
+		b, err := hex.DecodeString(s)
+		assert.NoError(t, err)
+		return b
+	}
+
+	// NIST SP 800-67 附录 B 的三密钥示例,加密结果末尾为填充块
+	k1, k2, k3 := unhex("0123456789abcdef"), unhex("23456789abcdef01"), unhex("456789abcdef0123")
+	key := append(append(append([]byte(nil), k1...), k2...), k3...)
+	plaintext := unhex("5468652071756663" + "6b2062726f776e20" + "666f78206a756d70")
+	want := unhex("a826fd8ce53b855f" + "cce21c8112256fe6" + "68d5c05dd9b6b900")
+	encrypted, err := TripleDesEcbEncrypt(plaintext, key)
+	assert.NoError(t, err)
+	assert.Len(t, encrypted, len(want)+8)
+	assert.Equal(t, want, encrypted[:len(want)])
+	decrypted, err := TripleDesEcbDecrypt(encrypted, key)
+	assert.NoError(t, err)
+	assert.Equal(t, plaintext, decrypted)
+
+	// 16字节密钥等同于 K1K2K1 的24字节密钥
+	short := append(append([]byte(nil), k1...), k2...)
+	long := append(append([]byte(nil), short...), k1...)
+	a, err := TripleDesEcbEncrypt(plaintext, short)
+	assert.NoError(t, err)
+	b, err := TripleDesEcbEncrypt(plaintext, long)
+	assert.NoError(t, err)
+	assert.Equal(t, b, a)
+	assert.NotEqual(t, want, a[:len(want)])
+
+	// NIST SP 800-20 可变明文已知答案测试,16字节密钥 K1=K2=0101010101010101,IV为0时 CBC 单块结果与 ECB 相同
+	key16 := unhex("01010101010101010101010101010101")
+	iv := make([]byte, 8)
+	for pt, ct := range map[string]string{
+		"8000000000000000": "95f8a5e5dd31d900",
+		"4000000000000000": "dd7f121ca5015619",
+		"2000000000000000": "2e8653104f3834ea",
+		"1000000000000000": "4bd388ff6cd81d4f",
+	} {
+		encrypted, err := TripleDesEcbEncrypt(unhex(pt), key16)
+		assert.NoError(t, err)
+		assert.Equal(t, unhex(ct), encrypted[:8], pt)
+
+		encrypted, err = TripleDesCbcEncryptWithIV(unhex(pt), key16, iv)
+		assert.NoError(t, err)
+		assert.Equal(t, unhex(ct), encrypted[:8], pt)
+		decrypted, err := TripleDesCbcDecryptWithIV(encrypted, key16, iv)
+		assert.NoError(t, err)
+		assert.Equal(t, unhex(pt), decrypted, pt)
+	}
+}
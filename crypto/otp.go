@@ -0,0 +1,436 @@
+package crypto
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"github.com/wind959/ko-utils/random"
+	"hash"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// OtpAlgorithm 一次性密码使用的HMAC哈希算法
+type OtpAlgorithm string
+
+const (
+	OtpSHA1   OtpAlgorithm = "SHA1"
+	OtpSHA256 OtpAlgorithm = "SHA256"
+	OtpSHA512 OtpAlgorithm = "SHA512"
+)
+
+const (
+	// DefaultOtpDigits 默认密码位数
+	DefaultOtpDigits = 6
+	// DefaultOtpPeriod 默认TOTP时间步长(秒)
+	DefaultOtpPeriod = 30
+	// DefaultOtpSkew 默认验证时允许偏移的时间步数
+	DefaultOtpSkew = 1
+	// DefaultOtpSecretSize 默认密钥字节数(RFC 4226 推荐160位)
+	DefaultOtpSecretSize = 20
+)
+
+var (
+	ErrOtpInvalidSecret    = errors.New("otp: invalid base32 secret")
+	ErrOtpInvalidDigits    = errors.New("otp: digits must be between 6 and 8")
+	ErrOtpInvalidPeriod    = errors.New("otp: period must be greater than 0")
+	ErrOtpInvalidAlgorithm = errors.New("otp: unsupported algorithm")
+	ErrOtpInvalidURI       = errors.New("otp: invalid otpauth uri")
+)
+
+// OtpConfig 一次性密码配置
+type OtpConfig struct {
+	Digits    int              // 密码位数,6~8
+	Period    uint             // TOTP时间步长(秒)
+	Algorithm OtpAlgorithm     // 哈希算法
+	Skew      uint             // 验证时前后允许偏移的时间步数(HOTP为向后查找的计数数)
+	Clock     func() time.Time // 时钟,便于测试时注入固定时间
+}
+
+// OtpOption 一次性密码配置选项
+type OtpOption func(*OtpConfig)
+
+// WithOtpDigits 设置密码位数
+func WithOtpDigits(digits int) OtpOption {
+	return func(c *OtpConfig) {
+		c.Digits = digits
+	}
+}
+
+// WithOtpPeriod 设置TOTP时间步长(秒)
+func WithOtpPeriod(period uint) OtpOption {
+	return func(c *OtpConfig) {
+		c.Period = period
+	}
+}
+
+// WithOtpAlgorithm 设置哈希算法
+func WithOtpAlgorithm(algorithm OtpAlgorithm) OtpOption {
+	return func(c *OtpConfig) {
+		c.Algorithm = algorithm
+	}
+}
+
+// WithOtpSkew 设置验证时允许偏移的时间步数
+func WithOtpSkew(skew uint) OtpOption {
+	return func(c *OtpConfig) {
+		c.Skew = skew
+	}
+}
+
+// WithOtpClock 设置时钟函数
+func WithOtpClock(clock func() time.Time) OtpOption {
+	return func(c *OtpConfig) {
+		c.Clock = clock
+	}
+}
+
+// GenerateOtpSecret 生成base32编码(无填充)的随机密钥,size为密钥字节数,<=0时使用默认值
+func GenerateOtpSecret(size int) (string, error) {
+	if size <= 0 {
+		size = DefaultOtpSecretSize
+	}
+	b := random.RandBytes(size)
+	if len(b) != size {
+		return "", errors.New("otp: failed to generate secret")
+	}
+	return base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(b), nil
+}
+
+// GenerateHotp 根据计数器生成HOTP密码(RFC 4226)
+func GenerateHotp(secret string, counter uint64, opts ...OtpOption) (string, error) {
+	cfg, err := newOtpConfig(opts)
+	if err != nil {
+		return "", err
+	}
+	key, err := decodeOtpSecret(secret)
+	if err != nil {
+		return "", err
+	}
+	return hotpCode(key, counter, cfg), nil
+}
+
+// VerifyHotp 验证HOTP密码,在[counter, counter+skew]范围内查找匹配项,
+// 返回匹配的计数器值,调用方应将计数器更新为 matched+1
+func VerifyHotp(secret, code string, counter uint64, opts ...OtpOption) (matched uint64, ok bool, err error) {
+	cfg, err := newOtpConfig(opts)
+	if err != nil {
+		return 0, false, err
+	}
+	key, err := decodeOtpSecret(secret)
+	if err != nil {
+		return 0, false, err
+	}
+	if len(code) != cfg.Digits {
+		return 0, false, nil
+	}
+
+	for i := uint64(0); i <= uint64(cfg.Skew); i++ {
+		if otpEqual(hotpCode(key, counter+i, cfg), code) {
+			return counter + i, true, nil
+		}
+	}
+	return 0, false, nil
+}
+
+// GenerateTotp 生成当前时间的TOTP密码(RFC 6238)
+func GenerateTotp(secret string, opts ...OtpOption) (string, error) {
+	cfg, err := newOtpConfig(opts)
+	if err != nil {
+		return "", err
+	}
+	return GenerateTotpAt(secret, cfg.Clock(), opts...)
+}
+
+// GenerateTotpAt 生成指定时间的TOTP密码
+func GenerateTotpAt(secret string, t time.Time, opts ...OtpOption) (string, error) {
+	cfg, err := newOtpConfig(opts)
+	if err != nil {
+		return "", err
+	}
+	key, err := decodeOtpSecret(secret)
+	if err != nil {
+		return "", err
+	}
+	return hotpCode(key, totpCounter(t, cfg.Period), cfg), nil
+}
+
+// VerifyTotp 验证当前时间的TOTP密码,允许前后skew个时间步的偏移
+func VerifyTotp(secret, code string, opts ...OtpOption) (bool, error) {
+	cfg, err := newOtpConfig(opts)
+	if err != nil {
+		return false, err
+	}
+	return VerifyTotpAt(secret, code, cfg.Clock(), opts...)
+}
+
+// VerifyTotpAt 验证指定时间的TOTP密码
+func VerifyTotpAt(secret, code string, t time.Time, opts ...OtpOption) (bool, error) {
+	cfg, err := newOtpConfig(opts)
+	if err != nil {
+		return false, err
+	}
+	key, err := decodeOtpSecret(secret)
+	if err != nil {
+		return false, err
+	}
+	if len(code) != cfg.Digits {
+		return false, nil
+	}
+
+	counter := totpCounter(t, cfg.Period)
+	skew := uint64(cfg.Skew)
+	for i := uint64(0); i <= 2*skew; i++ {
+		// 依次校验 counter-skew ... counter+skew
+		if counter+i < skew {
+			continue
+		}
+		if otpEqual(hotpCode(key, counter+i-skew, cfg), code) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// OtpKey otpauth URI描述的密钥信息
+type OtpKey struct {
+	Type      string       // totp 或 hotp
+	Issuer    string       // 发行方
+	Account   string       // 账户名
+	Secret    string       // base32编码的密钥
+	Algorithm OtpAlgorithm // 哈希算法
+	Digits    int          // 密码位数
+	Period    uint         // TOTP时间步长(秒)
+	Counter   uint64       // HOTP初始计数器
+}
+
+// Options 将密钥参数转换为配置选项
+func (k *OtpKey) Options() []OtpOption {
+	opts := make([]OtpOption, 0, 3)
+	if k.Digits > 0 {
+		opts = append(opts, WithOtpDigits(k.Digits))
+	}
+	if k.Period > 0 {
+		opts = append(opts, WithOtpPeriod(k.Period))
+	}
+	if k.Algorithm != "" {
+		opts = append(opts, WithOtpAlgorithm(k.Algorithm))
+	}
+	return opts
+}
+
+// BuildOtpAuthURI 构建供身份验证器App扫码使用的 otpauth:// URI
+func BuildOtpAuthURI(key *OtpKey) (string, error) {
+	if key == nil || key.Account == "" {
+		return "", fmt.Errorf("%w: account is required", ErrOtpInvalidURI)
+	}
+	if _, err := decodeOtpSecret(key.Secret); err != nil {
+		return "", err
+	}
+
+	typ := strings.ToLower(key.Type)
+	if typ == "" {
+		typ = "totp"
+	}
+	if typ != "totp" && typ != "hotp" {
+		return "", fmt.Errorf("%w: unknown type %q", ErrOtpInvalidURI, key.Type)
+	}
+
+	label := key.Account
+	if key.Issuer != "" {
+		label = key.Issuer + ":" + key.Account
+	}
+
+	params := []string{"secret=" + otpQueryEscape(strings.ToUpper(strings.TrimRight(key.Secret, "=")))}
+	if key.Issuer != "" {
+		params = append(params, "issuer="+otpQueryEscape(key.Issuer))
+	}
+	if key.Algorithm != "" {
+		params = append(params, "algorithm="+string(key.Algorithm))
+	}
+	if key.Digits > 0 {
+		params = append(params, "digits="+strconv.Itoa(key.Digits))
+	}
+	if typ == "totp" {
+		if key.Period > 0 {
+			params = append(params, "period="+strconv.FormatUint(uint64(key.Period), 10))
+		}
+	} else {
+		params = append(params, "counter="+strconv.FormatUint(key.Counter, 10))
+	}
+
+	return "otpauth://" + typ + "/" + url.PathEscape(label) + "?" + strings.Join(params, "&"), nil
+}
+
+// ParseOtpAuthURI 解析 otpauth:// URI,位数、时间步长或算法无效时返回 ErrOtpInvalidURI
+func ParseOtpAuthURI(uri string) (*OtpKey, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrOtpInvalidURI, err)
+	}
+	if u.Scheme != "otpauth" {
+		return nil, fmt.Errorf("%w: scheme must be otpauth", ErrOtpInvalidURI)
+	}
+
+	key := &OtpKey{
+		Type:      strings.ToLower(u.Host),
+		Algorithm: OtpSHA1,
+		Digits:    DefaultOtpDigits,
+	}
+	if key.Type != "totp" && key.Type != "hotp" {
+		return nil, fmt.Errorf("%w: unknown type %q", ErrOtpInvalidURI, u.Host)
+	}
+
+	label := strings.TrimPrefix(u.Path, "/")
+	if i := strings.Index(label, ":"); i >= 0 {
+		key.Issuer = strings.TrimSpace(label[:i])
+		key.Account = strings.TrimSpace(label[i+1:])
+	} else {
+		key.Account = label
+	}
+
+	q := u.Query()
+	key.Secret = q.Get("secret")
+	if _, err := decodeOtpSecret(key.Secret); err != nil {
+		return nil, err
+	}
+	// issuer 参数优先于标签中的发行方
+	if issuer := q.Get("issuer"); issuer != "" {
+		key.Issuer = issuer
+	}
+	if alg := q.Get("algorithm"); alg != "" {
+		key.Algorithm = OtpAlgorithm(strings.ToUpper(alg))
+		if _, err := otpHash(key.Algorithm); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrOtpInvalidURI, err)
+		}
+	}
+	if digits := q.Get("digits"); digits != "" {
+		if key.Digits, err = strconv.Atoi(digits); err != nil || key.Digits < 6 || key.Digits > 8 {
+			return nil, fmt.Errorf("%w: %w", ErrOtpInvalidURI, ErrOtpInvalidDigits)
+		}
+	}
+
+	if key.Type == "totp" {
+		key.Period = DefaultOtpPeriod
+		if period := q.Get("period"); period != "" {
+			p, err := strconv.ParseUint(period, 10, 32)
+			if err != nil || p == 0 {
+				return nil, fmt.Errorf("%w: %w", ErrOtpInvalidURI, ErrOtpInvalidPeriod)
+			}
+			key.Period = uint(p)
+		}
+	} else {
+		counter := q.Get("counter")
+		if counter == "" {
+			return nil, fmt.Errorf("%w: hotp requires counter", ErrOtpInvalidURI)
+		}
+		if key.Counter, err = strconv.ParseUint(counter, 10, 64); err != nil {
+			return nil, fmt.Errorf("%w: invalid counter", ErrOtpInvalidURI)
+		}
+	}
+
+	return key, nil
+}
+
+// newOtpConfig 合并默认配置并校验
+func newOtpConfig(opts []OtpOption) (*OtpConfig, error) {
+	cfg := &OtpConfig{
+		Digits:    DefaultOtpDigits,
+		Period:    DefaultOtpPeriod,
+		Algorithm: OtpSHA1,
+		Skew:      DefaultOtpSkew,
+		Clock:     time.Now,
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	if cfg.Digits < 6 || cfg.Digits > 8 {
+		return nil, ErrOtpInvalidDigits
+	}
+	if cfg.Period == 0 {
+		return nil, ErrOtpInvalidPeriod
+	}
+	if _, err := otpHash(cfg.Algorithm); err != nil {
+		return nil, err
+	}
+	if cfg.Clock == nil {
+		cfg.Clock = time.Now
+	}
+	return cfg, nil
+}
+
+// hotpCode 计算HOTP值(动态截断)
+func hotpCode(key []byte, counter uint64, cfg *OtpConfig) string {
+	h, _ := otpHash(cfg.Algorithm)
+	mac := hmac.New(h, key)
+
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], counter)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	mod := uint32(1)
+	for i := 0; i < cfg.Digits; i++ {
+		mod *= 10
+	}
+	return fmt.Sprintf("%0*d", cfg.Digits, value%mod)
+}
+
+// totpCounter 计算时间对应的计数器
+func totpCounter(t time.Time, period uint) uint64 {
+	unix := t.Unix()
+	if unix < 0 {
+		return 0
+	}
+	return uint64(unix) / uint64(period)
+}
+
+// otpHash 根据算法返回哈希构造函数
+func otpHash(algorithm OtpAlgorithm) (func() hash.Hash, error) {
+	switch OtpAlgorithm(strings.ToUpper(string(algorithm))) {
+	case OtpSHA1:
+		return sha1.New, nil
+	case OtpSHA256:
+		return sha256.New, nil
+	case OtpSHA512:
+		return sha512.New, nil
+	default:
+		return nil, ErrOtpInvalidAlgorithm
+	}
+}
+
+// decodeOtpSecret 解码base32密钥,忽略大小写、空格和填充
+func decodeOtpSecret(secret string) ([]byte, error) {
+	s := strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(secret), " ", ""))
+	s = strings.TrimRight(s, "=")
+	if s == "" {
+		return nil, ErrOtpInvalidSecret
+	}
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(s)
+	if err != nil {
+		return nil, ErrOtpInvalidSecret
+	}
+	return key, nil
+}
+
+// otpEqual 常量时间比较密码
+func otpEqual(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
+
+// otpQueryEscape URI参数编码,空格编码为%20以兼容身份验证器App
+func otpQueryEscape(s string) string {
+	return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+}
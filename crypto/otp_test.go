@@ -0,0 +1,117 @@
+package crypto
+
+import (
+	"encoding/base32"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHotpRFC4226(t *testing.T) {
+	secret := base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+	expected := []string{"755224", "287082", "359152", "969429", "338314",
+		"254676", "287922", "162583", "399871", "520489"}
+
+	for i, want := range expected {
+		code, err := GenerateHotp(secret, uint64(i))
+		assert.NoError(t, err)
+		assert.Equal(t, want, code)
+	}
+
+	// 计数器向后偏移在skew范围内
+	matched, ok, err := VerifyHotp(secret, "969429", 1, WithOtpSkew(2))
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(3), matched)
+
+	_, ok, _ = VerifyHotp(secret, "969429", 0, WithOtpSkew(2))
+	assert.False(t, ok)
+}
+
+func TestTotpRFC6238(t *testing.T) {
+	cases := []struct {
+		key       string
+		algorithm OtpAlgorithm
+		unix      int64
+		want      string
+	}{
+		{"12345678901234567890", OtpSHA1, 59, "94287082"},
+		{"12345678901234567890123456789012", OtpSHA256, 59, "46119246"},
+		{"1234567890123456789012345678901234567890123456789012345678901234", OtpSHA512, 59, "90693936"},
+		{"12345678901234567890", OtpSHA1, 1111111109, "07081804"},
+		{"12345678901234567890", OtpSHA1, 20000000000, "65353130"},
+	}
+
+	for _, c := range cases {
+		secret := base32.StdEncoding.EncodeToString([]byte(c.key))
+		clock := func() time.Time { return time.Unix(c.unix, 0) }
+
+		code, err := GenerateTotp(secret, WithOtpDigits(8), WithOtpAlgorithm(c.algorithm), WithOtpClock(clock))
+		assert.NoError(t, err)
+		assert.Equal(t, c.want, code)
+
+		ok, err := VerifyTotp(secret, c.want, WithOtpDigits(8), WithOtpAlgorithm(c.algorithm), WithOtpClock(clock))
+		assert.NoError(t, err)
+		assert.True(t, ok)
+	}
+}
+
+func TestTotpSkew(t *testing.T) {
+	secret, err := GenerateOtpSecret(0)
+	assert.NoError(t, err)
+
+	now := time.Unix(1700000000, 0)
+	code, err := GenerateTotpAt(secret, now)
+	assert.NoError(t, err)
+
+	ok, _ := VerifyTotpAt(secret, code, now.Add(30*time.Second))
+	assert.True(t, ok)
+
+	ok, _ = VerifyTotpAt(secret, code, now.Add(90*time.Second))
+	assert.False(t, ok)
+
+	ok, _ = VerifyTotpAt(secret, code, now.Add(90*time.Second), WithOtpSkew(3))
+	assert.True(t, ok)
+}
+
+func TestOtpAuthURI(t *testing.T) {
+	key := &OtpKey{
+		Type:      "totp",
+		Issuer:    "Ko Admin",
+		Account:   "alice@example.com",
+		Secret:    "JBSWY3DPEHPK3PXP",
+		Algorithm: OtpSHA256,
+		Digits:    8,
+		Period:    60,
+	}
+
+	uri, err := BuildOtpAuthURI(key)
+	assert.NoError(t, err)
+	assert.Equal(t, "otpauth://totp/Ko%20Admin:alice@example.com?secret=JBSWY3DPEHPK3PXP&issuer=Ko%20Admin&algorithm=SHA256&digits=8&period=60", uri)
+
+	parsed, err := ParseOtpAuthURI(uri)
+	assert.NoError(t, err)
+	assert.Equal(t, key, parsed)
+
+	_, err = ParseOtpAuthURI("otpauth://hotp/alice?secret=JBSWY3DPEHPK3PXP")
+	assert.ErrorIs(t, err, ErrOtpInvalidURI)
+
+	_, err = ParseOtpAuthURI("otpauth://totp/alice?secret=not-base32!")
+	assert.ErrorIs(t, err, ErrOtpInvalidSecret)
+
+	// 参数超出范围时在解析阶段报错
+	for param, want := range map[string]error{
+		"digits=0":      ErrOtpInvalidDigits,
+		"digits=-6":     ErrOtpInvalidDigits,
+		"digits=9":      ErrOtpInvalidDigits,
+		"digits=six":    ErrOtpInvalidDigits,
+		"period=0":      ErrOtpInvalidPeriod,
+		"period=-30":    ErrOtpInvalidPeriod,
+		"algorithm=MD5": ErrOtpInvalidAlgorithm,
+	} {
+		_, err = ParseOtpAuthURI("otpauth://totp/alice?secret=JBSWY3DPEHPK3PXP&" + param)
+		assert.ErrorIs(t, err, ErrOtpInvalidURI, param)
+		assert.ErrorIs(t, err, want, param)
+	}
+}
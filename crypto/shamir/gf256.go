@@ -0,0 +1,79 @@
+package shamir
+
+// GF(2^8) 运算,使用AES的既约多项式 x^8+x^4+x^3+x+1 (0x11b),生成元为3
+var (
+	expTable [510]byte
+	logTable [256]byte
+)
+
+func init() {
+	x := byte(1)
+	for i := 0; i < 255; i++ {
+		expTable[i] = x
+		expTable[i+255] = x
+		logTable[x] = byte(i)
+		x = gfMulNoTable(x, 3)
+	}
+}
+
+// gfMulNoTable 不查表的乘法,仅用于构建对数表
+func gfMulNoTable(a, b byte) byte {
+	var p byte
+	for b > 0 {
+		if b&1 != 0 {
+			p ^= a
+		}
+		carry := a & 0x80
+		a <<= 1
+		if carry != 0 {
+			a ^= 0x1b
+		}
+		b >>= 1
+	}
+	return p
+}
+
+// gfMul 乘法
+func gfMul(a, b byte) byte {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	return expTable[int(logTable[a])+int(logTable[b])]
+}
+
+// gfDiv 除法,b不能为0
+func gfDiv(a, b byte) byte {
+	if b == 0 {
+		panic("shamir: division by zero")
+	}
+	if a == 0 {
+		return 0
+	}
+	return expTable[int(logTable[a])+255-int(logTable[b])]
+}
+
+// evaluate 使用霍纳法则计算多项式在x处的值,coeffs[0]为常数项
+func evaluate(coeffs []byte, x byte) byte {
+	var y byte
+	for i := len(coeffs) - 1; i >= 0; i-- {
+		y = gfMul(y, x) ^ coeffs[i]
+	}
+	return y
+}
+
+// interpolate 拉格朗日插值计算经过点(xs, ys)的多项式在x处的值
+func interpolate(xs, ys []byte, x byte) byte {
+	var result byte
+	for i := range xs {
+		basis := byte(1)
+		for j := range xs {
+			if i == j {
+				continue
+			}
+			// 加减法在GF(2^8)中均为异或
+			basis = gfMul(basis, gfDiv(x^xs[j], xs[i]^xs[j]))
+		}
+		result ^= gfMul(ys[i], basis)
+	}
+	return result
+}
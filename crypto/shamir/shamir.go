@@ -0,0 +1,155 @@
+package shamir
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+)
+
+// 分片编码格式: [版本 1字节][门限k 1字节][索引x 1字节][数据 len(secret)字节][CRC32 4字节]
+const (
+	shareVersion  = 1
+	headerSize    = 3
+	checksumSize  = 4
+	shareOverhead = headerSize + checksumSize
+)
+
+var (
+	ErrInvalidParams      = errors.New("shamir: invalid parameters")
+	ErrEmptySecret        = errors.New("shamir: secret is empty")
+	ErrInvalidShare       = errors.New("shamir: invalid share")
+	ErrChecksumMismatch   = errors.New("shamir: share checksum mismatch")
+	ErrInsufficientShares = errors.New("shamir: insufficient shares")
+	ErrDuplicateShare     = errors.New("shamir: duplicate share index")
+	ErrInconsistentShares = errors.New("shamir: shares are inconsistent")
+)
+
+// Split 将秘密拆分为n个分片,任意k个分片即可恢复秘密(2 <= k <= n <= 255)
+func Split(secret []byte, n, k int) ([][]byte, error) {
+	if len(secret) == 0 {
+		return nil, ErrEmptySecret
+	}
+	if k < 2 || n < k || n > 255 {
+		return nil, fmt.Errorf("%w: require 2 <= k <= n <= 255, got n=%d k=%d", ErrInvalidParams, n, k)
+	}
+
+	shares := make([][]byte, n)
+	for i := range shares {
+		share := make([]byte, len(secret)+shareOverhead)
+		share[0] = shareVersion
+		share[1] = byte(k)
+		share[2] = byte(i + 1)
+		shares[i] = share
+	}
+
+	// 为每个字节构造 k-1 次随机多项式,常数项为秘密字节
+	coeffs := make([]byte, k)
+	for j, s := range secret {
+		coeffs[0] = s
+		if _, err := io.ReadFull(rand.Reader, coeffs[1:]); err != nil {
+			return nil, fmt.Errorf("shamir: failed to generate coefficients: %w", err)
+		}
+		for i := range shares {
+			shares[i][headerSize+j] = evaluate(coeffs, byte(i+1))
+		}
+	}
+
+	for _, share := range shares {
+		sum := crc32.ChecksumIEEE(share[:len(share)-checksumSize])
+		binary.BigEndian.PutUint32(share[len(share)-checksumSize:], sum)
+	}
+	// 清理系数
+	for i := range coeffs {
+		coeffs[i] = 0
+	}
+	return shares, nil
+}
+
+// Combine 使用分片恢复秘密,分片数量少于门限、分片损坏或分片之间不一致时返回错误
+func Combine(shares [][]byte) ([]byte, error) {
+	if len(shares) == 0 {
+		return nil, ErrInsufficientShares
+	}
+
+	parsed := make([]parsedShare, 0, len(shares))
+	seen := make(map[byte]struct{}, len(shares))
+	for _, raw := range shares {
+		s, err := parseShare(raw)
+		if err != nil {
+			return nil, err
+		}
+		if len(parsed) > 0 && (s.threshold != parsed[0].threshold || len(s.data) != len(parsed[0].data)) {
+			return nil, ErrInconsistentShares
+		}
+		if _, ok := seen[s.index]; ok {
+			return nil, fmt.Errorf("%w: %d", ErrDuplicateShare, s.index)
+		}
+		seen[s.index] = struct{}{}
+		parsed = append(parsed, s)
+	}
+
+	k := int(parsed[0].threshold)
+	if len(parsed) < k {
+		return nil, fmt.Errorf("%w: need %d, got %d", ErrInsufficientShares, k, len(parsed))
+	}
+
+	base, extra := parsed[:k], parsed[k:]
+	xs := make([]byte, k)
+	ys := make([]byte, k)
+	for i, s := range base {
+		xs[i] = s.index
+	}
+
+	secret := make([]byte, len(base[0].data))
+	for j := range secret {
+		for i, s := range base {
+			ys[i] = s.data[j]
+		}
+		secret[j] = interpolate(xs, ys, 0)
+
+		// 多余的分片必须落在同一多项式上
+		for _, s := range extra {
+			if interpolate(xs, ys, s.index) != s.data[j] {
+				return nil, ErrInconsistentShares
+			}
+		}
+	}
+	return secret, nil
+}
+
+// Threshold 返回分片记录的门限值
+func Threshold(share []byte) (int, error) {
+	s, err := parseShare(share)
+	if err != nil {
+		return 0, err
+	}
+	return int(s.threshold), nil
+}
+
+// parsedShare 解析后的分片
+type parsedShare struct {
+	threshold byte
+	index     byte
+	data      []byte
+}
+
+// parseShare 解析并校验分片
+func parseShare(raw []byte) (parsedShare, error) {
+	if len(raw) <= shareOverhead {
+		return parsedShare{}, fmt.Errorf("%w: too short", ErrInvalidShare)
+	}
+	body, sum := raw[:len(raw)-checksumSize], raw[len(raw)-checksumSize:]
+	if crc32.ChecksumIEEE(body) != binary.BigEndian.Uint32(sum) {
+		return parsedShare{}, ErrChecksumMismatch
+	}
+	if body[0] != shareVersion {
+		return parsedShare{}, fmt.Errorf("%w: unsupported version %d", ErrInvalidShare, body[0])
+	}
+	if body[1] < 2 || body[2] == 0 {
+		return parsedShare{}, fmt.Errorf("%w: bad header", ErrInvalidShare)
+	}
+	return parsedShare{threshold: body[1], index: body[2], data: body[headerSize:]}, nil
+}
@@ -0,0 +1,57 @@
+package shamir
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitCombine(t *testing.T) {
+	secret := []byte("master-key-0123456789abcdef")
+
+	shares, err := Split(secret, 5, 3)
+	assert.NoError(t, err)
+	assert.Len(t, shares, 5)
+
+	// 任意3个分片均可恢复
+	for _, idx := range [][]int{{0, 1, 2}, {4, 2, 0}, {1, 3, 4}} {
+		subset := [][]byte{shares[idx[0]], shares[idx[1]], shares[idx[2]]}
+		got, err := Combine(subset)
+		assert.NoError(t, err)
+		assert.Equal(t, secret, got)
+	}
+
+	got, err := Combine(shares)
+	assert.NoError(t, err)
+	assert.Equal(t, secret, got)
+
+	k, err := Threshold(shares[0])
+	assert.NoError(t, err)
+	assert.Equal(t, 3, k)
+}
+
+func TestCombineErrors(t *testing.T) {
+	shares, err := Split([]byte("secret"), 4, 3)
+	assert.NoError(t, err)
+
+	_, err = Combine(shares[:2])
+	assert.ErrorIs(t, err, ErrInsufficientShares)
+
+	_, err = Combine([][]byte{shares[0], shares[0], shares[1]})
+	assert.ErrorIs(t, err, ErrDuplicateShare)
+
+	corrupted := append([]byte(nil), shares[1]...)
+	corrupted[4] ^= 0xff
+	_, err = Combine([][]byte{shares[0], corrupted, shares[2]})
+	assert.ErrorIs(t, err, ErrChecksumMismatch)
+
+	// 来自另一次拆分的分片校验和正确,但与其他分片不在同一多项式上
+	other, _ := Split([]byte("secret"), 4, 3)
+	_, err = Combine([][]byte{shares[0], shares[1], shares[2], other[3]})
+	assert.ErrorIs(t, err, ErrInconsistentShares)
+
+	_, err = Split([]byte("secret"), 2, 3)
+	assert.ErrorIs(t, err, ErrInvalidParams)
+	_, err = Split(nil, 3, 2)
+	assert.ErrorIs(t, err, ErrEmptySecret)
+}
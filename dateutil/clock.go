@@ -0,0 +1,27 @@
+package dateutil
+
+import (
+	"sync"
+	"time"
+
+	"github.com/wind959/ko-utils/clock"
+)
+
+var (
+	clockMu  sync.RWMutex
+	pkgClock = clock.Real
+)
+
+// SetClock 设置本包获取当前时间使用的时钟,传入nil恢复为真实时钟,测试中可传入 clock.NewFake
+func SetClock(c clock.Clock) {
+	clockMu.Lock()
+	defer clockMu.Unlock()
+	pkgClock = clock.OrReal(c)
+}
+
+// Now 返回当前时间,受 SetClock 影响
+func Now() time.Time {
+	clockMu.RLock()
+	defer clockMu.RUnlock()
+	return pkgClock.Now()
+}
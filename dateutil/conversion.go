@@ -8,7 +8,7 @@ type theTime struct {
 
 // NewUnixNow 创建一个当前时间的unix时间戳
 func NewUnixNow() *theTime {
-	return &theTime{unix: time.Now().Unix()}
+	return &theTime{unix: Now().Unix()}
 }
 
 // NewUnix 创建一个unix时间戳
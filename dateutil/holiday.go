@@ -0,0 +1,169 @@
+package dateutil
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+//go:embed data/holidays_cn.json
+var defaultHolidayData []byte
+
+const dateLayout = "2006-01-02"
+
+// HolidayKind 日期类型
+type HolidayKind int
+
+const (
+	NormalDay        HolidayKind = iota // 普通日期,按星期判断是否上班
+	StatutoryHoliday                    // 法定节假日(含连休)
+	AdjustedWorkday                     // 调休上班日
+)
+
+// HolidayPeriod 一次节假日安排,日期格式为 yyyy-mm-dd
+type HolidayPeriod struct {
+	Name     string   `json:"name"`     // 节日名称
+	From     string   `json:"from"`     // 放假开始日期
+	To       string   `json:"to"`       // 放假结束日期(含)
+	Workdays []string `json:"workdays"` // 调休上班日期
+}
+
+// HolidayInfo 日期的节假日信息
+type HolidayInfo struct {
+	Name string
+	Kind HolidayKind
+}
+
+// HolidayTable 节假日表,并发安全
+type HolidayTable struct {
+	mu   sync.RWMutex
+	days map[string]HolidayInfo
+}
+
+var (
+	holidayMu      sync.RWMutex
+	holidayDefault *HolidayTable
+)
+
+func init() {
+	var periods []HolidayPeriod
+	if err := json.Unmarshal(defaultHolidayData, &periods); err != nil {
+		panic("dateutil: invalid embedded holiday data: " + err.Error())
+	}
+	table, err := NewHolidayTable(periods...)
+	if err != nil {
+		panic("dateutil: invalid embedded holiday data: " + err.Error())
+	}
+	holidayDefault = table
+}
+
+// NewHolidayTable 根据节假日安排创建节假日表
+func NewHolidayTable(periods ...HolidayPeriod) (*HolidayTable, error) {
+	h := &HolidayTable{days: make(map[string]HolidayInfo)}
+	if err := h.Add(periods...); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// LoadHolidayTable 从JSON读取节假日表,格式为 HolidayPeriod 数组
+func LoadHolidayTable(r io.Reader) (*HolidayTable, error) {
+	var periods []HolidayPeriod
+	if err := json.NewDecoder(r).Decode(&periods); err != nil {
+		return nil, fmt.Errorf("holiday: decode failed: %w", err)
+	}
+	return NewHolidayTable(periods...)
+}
+
+// Add 添加节假日安排,已存在的日期会被覆盖
+func (h *HolidayTable) Add(periods ...HolidayPeriod) error {
+	parsed := make(map[string]HolidayInfo)
+	for _, p := range periods {
+		from, err := time.Parse(dateLayout, p.From)
+		if err != nil {
+			return fmt.Errorf("holiday: invalid from date %q: %w", p.From, err)
+		}
+		to := from
+		if p.To != "" {
+			if to, err = time.Parse(dateLayout, p.To); err != nil {
+				return fmt.Errorf("holiday: invalid to date %q: %w", p.To, err)
+			}
+		}
+		if to.Before(from) {
+			return fmt.Errorf("holiday: %s ends before it starts", p.Name)
+		}
+
+		for d := from; !d.After(to); d = d.AddDate(0, 0, 1) {
+			parsed[d.Format(dateLayout)] = HolidayInfo{Name: p.Name, Kind: StatutoryHoliday}
+		}
+		for _, w := range p.Workdays {
+			d, err := time.Parse(dateLayout, w)
+			if err != nil {
+				return fmt.Errorf("holiday: invalid workday %q: %w", w, err)
+			}
+			parsed[d.Format(dateLayout)] = HolidayInfo{Name: p.Name, Kind: AdjustedWorkday}
+		}
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for k, v := range parsed {
+		h.days[k] = v
+	}
+	return nil
+}
+
+// Lookup 查询日期(按t所在时区的日期)的节假日信息
+func (h *HolidayTable) Lookup(t time.Time) (HolidayInfo, bool) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	info, ok := h.days[t.Format(dateLayout)]
+	return info, ok
+}
+
+// IsHoliday 是否为法定节假日
+func (h *HolidayTable) IsHoliday(t time.Time) bool {
+	info, ok := h.Lookup(t)
+	return ok && info.Kind == StatutoryHoliday
+}
+
+// IsAdjustedWorkday 是否为调休上班日
+func (h *HolidayTable) IsAdjustedWorkday(t time.Time) bool {
+	info, ok := h.Lookup(t)
+	return ok && info.Kind == AdjustedWorkday
+}
+
+// DefaultHolidayTable 返回当前默认节假日表,初始为内置的中国法定节假日安排
+func DefaultHolidayTable() *HolidayTable {
+	holidayMu.RLock()
+	defer holidayMu.RUnlock()
+	return holidayDefault
+}
+
+// SetHolidayTable 替换默认节假日表,用于加载新年度或自定义的安排
+func SetHolidayTable(table *HolidayTable) {
+	if table == nil {
+		return
+	}
+	holidayMu.Lock()
+	defer holidayMu.Unlock()
+	holidayDefault = table
+}
+
+// GetHolidayInfo 使用默认节假日表查询日期信息
+func GetHolidayInfo(t time.Time) (HolidayInfo, bool) {
+	return DefaultHolidayTable().Lookup(t)
+}
+
+// IsHoliday 使用默认节假日表判断是否为法定节假日
+func IsHoliday(t time.Time) bool {
+	return DefaultHolidayTable().IsHoliday(t)
+}
+
+// IsAdjustedWorkday 使用默认节假日表判断是否为调休上班日
+func IsAdjustedWorkday(t time.Time) bool {
+	return DefaultHolidayTable().IsAdjustedWorkday(t)
+}
@@ -0,0 +1,330 @@
+package dateutil
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// HumanUnit 人性化输出使用的时间单位
+type HumanUnit int
+
+const (
+	UnitMillisecond HumanUnit = iota
+	UnitSecond
+	UnitMinute
+	UnitHour
+	UnitDay
+	UnitWeek
+	UnitMonth
+	UnitYear
+)
+
+// humanUnitDurations 各单位的近似时长,月按30天、年按365天计算
+var humanUnitDurations = map[HumanUnit]time.Duration{
+	UnitMillisecond: time.Millisecond,
+	UnitSecond:      time.Second,
+	UnitMinute:      time.Minute,
+	UnitHour:        time.Hour,
+	UnitDay:         24 * time.Hour,
+	UnitWeek:        7 * 24 * time.Hour,
+	UnitMonth:       30 * 24 * time.Hour,
+	UnitYear:        365 * 24 * time.Hour,
+}
+
+// humanizeUnits HumanizeDuration 使用的单位,不含周
+var humanizeUnits = []HumanUnit{UnitYear, UnitMonth, UnitDay, UnitHour, UnitMinute, UnitSecond}
+
+// relativeUnits RelativeTime 使用的单位
+var relativeUnits = []HumanUnit{UnitYear, UnitMonth, UnitWeek, UnitDay, UnitHour, UnitMinute, UnitSecond}
+
+var ErrInvalidHumanDuration = errors.New("dateutil: invalid human duration")
+
+// Locale 人性化时间的本地化文案
+type Locale struct {
+	JustNow   string                  // 刚刚
+	Past      string                  // 过去时间的格式,如 "%s前"
+	Future    string                  // 将来时间的格式,如 "%s后"
+	Separator string                  // 多个单位之间的分隔符
+	Units     map[HumanUnit][2]string // 单位格式 [单数, 复数],如 {"%d minute", "%d minutes"}
+	Aliases   map[string]HumanUnit    // 解析时可识别的单位名称
+}
+
+// LocaleZh 简体中文
+var LocaleZh = &Locale{
+	JustNow: "刚刚",
+	Past:    "%s前",
+	Future:  "%s后",
+	Units: map[HumanUnit][2]string{
+		UnitMillisecond: {"%d毫秒", "%d毫秒"},
+		UnitSecond:      {"%d秒", "%d秒"},
+		UnitMinute:      {"%d分钟", "%d分钟"},
+		UnitHour:        {"%d小时", "%d小时"},
+		UnitDay:         {"%d天", "%d天"},
+		UnitWeek:        {"%d周", "%d周"},
+		UnitMonth:       {"%d个月", "%d个月"},
+		UnitYear:        {"%d年", "%d年"},
+	},
+	Aliases: map[string]HumanUnit{
+		"毫秒": UnitMillisecond, "秒": UnitSecond, "秒钟": UnitSecond,
+		"分": UnitMinute, "分钟": UnitMinute, "小时": UnitHour, "个小时": UnitHour, "时": UnitHour,
+		"天": UnitDay, "日": UnitDay, "周": UnitWeek, "星期": UnitWeek, "个星期": UnitWeek,
+		"月": UnitMonth, "个月": UnitMonth, "年": UnitYear,
+	},
+}
+
+// LocaleEn 英文
+var LocaleEn = &Locale{
+	JustNow:   "just now",
+	Past:      "%s ago",
+	Future:    "in %s",
+	Separator: " ",
+	Units: map[HumanUnit][2]string{
+		UnitMillisecond: {"%d millisecond", "%d milliseconds"},
+		UnitSecond:      {"%d second", "%d seconds"},
+		UnitMinute:      {"%d minute", "%d minutes"},
+		UnitHour:        {"%d hour", "%d hours"},
+		UnitDay:         {"%d day", "%d days"},
+		UnitWeek:        {"%d week", "%d weeks"},
+		UnitMonth:       {"%d month", "%d months"},
+		UnitYear:        {"%d year", "%d years"},
+	},
+	Aliases: map[string]HumanUnit{
+		"ms": UnitMillisecond, "millisecond": UnitMillisecond, "milliseconds": UnitMillisecond,
+		"s": UnitSecond, "sec": UnitSecond, "secs": UnitSecond, "second": UnitSecond, "seconds": UnitSecond,
+		"m": UnitMinute, "min": UnitMinute, "mins": UnitMinute, "minute": UnitMinute, "minutes": UnitMinute,
+		"h": UnitHour, "hr": UnitHour, "hrs": UnitHour, "hour": UnitHour, "hours": UnitHour,
+		"d": UnitDay, "day": UnitDay, "days": UnitDay,
+		"w": UnitWeek, "wk": UnitWeek, "week": UnitWeek, "weeks": UnitWeek,
+		"mo": UnitMonth, "month": UnitMonth, "months": UnitMonth,
+		"y": UnitYear, "yr": UnitYear, "year": UnitYear, "years": UnitYear,
+	},
+}
+
+var (
+	localeMu      sync.RWMutex
+	locales       = map[string]*Locale{"zh": LocaleZh, "en": LocaleEn}
+	defaultLocale = "zh"
+)
+
+// HumanizeOption 人性化输出配置选项
+type HumanizeOption func(*humanizeConfig)
+
+type humanizeConfig struct {
+	locale    *Locale
+	precision int
+}
+
+// WithLocale 指定语言,未注册的语言使用默认语言
+func WithLocale(name string) HumanizeOption {
+	return func(c *humanizeConfig) {
+		if l := GetLocale(name); l != nil {
+			c.locale = l
+		}
+	}
+}
+
+// WithPrecision 设置 HumanizeDuration 最多输出的单位个数,默认2
+func WithPrecision(n int) HumanizeOption {
+	return func(c *humanizeConfig) {
+		if n > 0 {
+			c.precision = n
+		}
+	}
+}
+
+// RegisterLocale 注册或覆盖语言
+func RegisterLocale(name string, locale *Locale) {
+	localeMu.Lock()
+	defer localeMu.Unlock()
+	locales[name] = locale
+}
+
+// GetLocale 获取已注册的语言,不存在时返回nil
+func GetLocale(name string) *Locale {
+	localeMu.RLock()
+	defer localeMu.RUnlock()
+	return locales[name]
+}
+
+// SetDefaultLocale 设置默认语言,语言未注册时返回错误
+func SetDefaultLocale(name string) error {
+	localeMu.Lock()
+	defer localeMu.Unlock()
+	if _, ok := locales[name]; !ok {
+		return fmt.Errorf("dateutil: locale %q not registered", name)
+	}
+	defaultLocale = name
+	return nil
+}
+
+// RelativeTime 返回t相对当前时间的描述,如 "3分钟前"、"in 2 hours"
+func RelativeTime(t time.Time, opts ...HumanizeOption) string {
+	return RelativeTimeFrom(t, Now(), opts...)
+}
+
+// RelativeTimeFrom 返回t相对base的描述,取最大的整单位
+func RelativeTimeFrom(t, base time.Time, opts ...HumanizeOption) string {
+	cfg := newHumanizeConfig(opts)
+	d := t.Sub(base)
+	abs := d
+	if abs < 0 {
+		abs = -abs
+	}
+	if abs < 5*time.Second {
+		return cfg.locale.JustNow
+	}
+
+	var text string
+	for _, u := range relativeUnits {
+		if n := int64(abs / humanUnitDurations[u]); n > 0 {
+			text = cfg.locale.formatUnit(u, n)
+			break
+		}
+	}
+	if d < 0 {
+		return fmt.Sprintf(cfg.locale.Past, text)
+	}
+	return fmt.Sprintf(cfg.locale.Future, text)
+}
+
+// HumanizeDuration 将时长格式化为人类可读的形式,如 "1天3小时"、"2 hours 30 minutes"
+func HumanizeDuration(d time.Duration, opts ...HumanizeOption) string {
+	cfg := newHumanizeConfig(opts)
+	sign := ""
+	if d < 0 {
+		sign, d = "-", -d
+	}
+	if d < time.Second {
+		return sign + cfg.locale.formatUnit(UnitMillisecond, int64(d/time.Millisecond))
+	}
+
+	parts := make([]string, 0, cfg.precision)
+	for _, u := range humanizeUnits {
+		if len(parts) == cfg.precision {
+			break
+		}
+		unit := humanUnitDurations[u]
+		if n := int64(d / unit); n > 0 {
+			parts = append(parts, cfg.locale.formatUnit(u, n))
+			d -= time.Duration(n) * unit
+		} else if len(parts) > 0 {
+			// 只输出相邻的单位,避免 "1天5秒" 这类跳跃
+			break
+		}
+	}
+	return sign + strings.Join(parts, cfg.locale.Separator)
+}
+
+// ParseHumanDuration 解析人类可读的时长,支持Go格式 "2h30m"、"1天3小时"、"2 hours 30 minutes"、"1.5h",
+// 单位名称来自所有已注册语言的 Aliases
+func ParseHumanDuration(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, ErrInvalidHumanDuration
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		return d, nil
+	}
+
+	neg := false
+	if s[0] == '-' || s[0] == '+' {
+		neg = s[0] == '-'
+		s = s[1:]
+	}
+
+	aliases := unitAliases()
+	runes := []rune(s)
+	var total float64
+	for i := 0; i < len(runes); {
+		if isDurationSeparator(runes[i]) {
+			i++
+			continue
+		}
+
+		start := i
+		for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.') {
+			i++
+		}
+		if start == i {
+			return 0, fmt.Errorf("%w: expected number at %q", ErrInvalidHumanDuration, string(runes[start:]))
+		}
+		n, err := strconv.ParseFloat(string(runes[start:i]), 64)
+		if err != nil {
+			return 0, fmt.Errorf("%w: bad number %q", ErrInvalidHumanDuration, string(runes[start:i]))
+		}
+
+		for i < len(runes) && unicode.IsSpace(runes[i]) {
+			i++
+		}
+		start = i
+		for i < len(runes) && !unicode.IsDigit(runes[i]) && !isDurationSeparator(runes[i]) {
+			i++
+		}
+		name := strings.ToLower(string(runes[start:i]))
+		unit, ok := aliases[name]
+		if !ok {
+			return 0, fmt.Errorf("%w: unknown unit %q", ErrInvalidHumanDuration, name)
+		}
+		total += n * float64(humanUnitDurations[unit])
+	}
+
+	if total > math.MaxInt64 {
+		return 0, fmt.Errorf("%w: overflow", ErrInvalidHumanDuration)
+	}
+	if neg {
+		total = -total
+	}
+	return time.Duration(total), nil
+}
+
+// formatUnit 按单复数格式化单位
+func (l *Locale) formatUnit(u HumanUnit, n int64) string {
+	names := l.Units[u]
+	if n == 1 {
+		return fmt.Sprintf(names[0], n)
+	}
+	return fmt.Sprintf(names[1], n)
+}
+
+// newHumanizeConfig 合并默认配置和选项
+func newHumanizeConfig(opts []HumanizeOption) *humanizeConfig {
+	localeMu.RLock()
+	cfg := &humanizeConfig{locale: locales[defaultLocale], precision: 2}
+	localeMu.RUnlock()
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	return cfg
+}
+
+// unitAliases 合并所有已注册语言的单位名称,名称按语言名排序后合并,保证结果确定
+func unitAliases() map[string]HumanUnit {
+	localeMu.RLock()
+	defer localeMu.RUnlock()
+
+	names := make([]string, 0, len(locales))
+	for name := range locales {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	aliases := make(map[string]HumanUnit)
+	for _, name := range names {
+		for alias, u := range locales[name].Aliases {
+			aliases[strings.ToLower(alias)] = u
+		}
+	}
+	return aliases
+}
+
+// isDurationSeparator 时长各部分之间允许的分隔符
+func isDurationSeparator(r rune) bool {
+	return unicode.IsSpace(r) || r == ',' || r == '，' || r == '、'
+}
@@ -0,0 +1,89 @@
+package dateutil
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wind959/ko-utils/clock"
+)
+
+func TestRelativeTime(t *testing.T) {
+	base := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, "刚刚", RelativeTimeFrom(base.Add(-2*time.Second), base))
+	assert.Equal(t, "3分钟前", RelativeTimeFrom(base.Add(-3*time.Minute-20*time.Second), base))
+	assert.Equal(t, "2小时后", RelativeTimeFrom(base.Add(2*time.Hour), base))
+	assert.Equal(t, "1周前", RelativeTimeFrom(base.AddDate(0, 0, -10), base))
+	assert.Equal(t, "2年前", RelativeTimeFrom(base.AddDate(-2, 0, 0), base))
+
+	assert.Equal(t, "just now", RelativeTimeFrom(base, base, WithLocale("en")))
+	assert.Equal(t, "3 minutes ago", RelativeTimeFrom(base.Add(-3*time.Minute), base, WithLocale("en")))
+	assert.Equal(t, "in 1 day", RelativeTimeFrom(base.Add(30*time.Hour), base, WithLocale("en")))
+}
+
+func TestHumanizeDuration(t *testing.T) {
+	d := 27*time.Hour + 15*time.Minute + 10*time.Second
+	assert.Equal(t, "1天3小时", HumanizeDuration(d))
+	assert.Equal(t, "1天3小时15分钟", HumanizeDuration(d, WithPrecision(3)))
+	assert.Equal(t, "1 day 3 hours", HumanizeDuration(d, WithLocale("en")))
+	assert.Equal(t, "1天", HumanizeDuration(24*time.Hour+5*time.Second))
+	assert.Equal(t, "-1 minute 30 seconds", HumanizeDuration(-90*time.Second, WithLocale("en")))
+	assert.Equal(t, "250毫秒", HumanizeDuration(250*time.Millisecond))
+
+	// 自定义语言
+	RegisterLocale("pirate", &Locale{
+		JustNow: "aye now", Past: "%s back", Future: "%s hence", Separator: ", ",
+		Units:   map[HumanUnit][2]string{UnitHour: {"%d bell", "%d bells"}, UnitMinute: {"%d tick", "%d ticks"}},
+		Aliases: map[string]HumanUnit{"bells": UnitHour},
+	})
+	assert.Equal(t, "2 bells, 1 tick", HumanizeDuration(2*time.Hour+time.Minute, WithLocale("pirate")))
+	assert.Error(t, SetDefaultLocale("klingon"))
+}
+
+func TestParseHumanDuration(t *testing.T) {
+	cases := map[string]time.Duration{
+		"2h30m":              2*time.Hour + 30*time.Minute,
+		"1.5h":               90 * time.Minute,
+		"1天3小时":              27 * time.Hour,
+		"1 天 3 小时 20 分钟":     27*time.Hour + 20*time.Minute,
+		"2 hours 30 minutes": 2*time.Hour + 30*time.Minute,
+		"1d12h":              36 * time.Hour,
+		"2周":                 14 * 24 * time.Hour,
+		"-1 day, 2 hours":    -26 * time.Hour,
+		"0.5天":               12 * time.Hour,
+	}
+	for in, want := range cases {
+		got, err := ParseHumanDuration(in)
+		assert.NoError(t, err, in)
+		assert.Equal(t, want, got, in)
+	}
+
+	for _, bad := range []string{"", "abc", "3 fortnights", "小时"} {
+		_, err := ParseHumanDuration(bad)
+		assert.ErrorIs(t, err, ErrInvalidHumanDuration, bad)
+	}
+}
+
+func TestNowWithFakeClock(t *testing.T) {
+	fake := clock.NewFake(time.Date(2024, 3, 1, 12, 0, 0, 0, time.Local))
+	SetClock(fake)
+	defer SetClock(nil)
+
+	event := fake.Now().Add(-3 * time.Minute)
+	assert.Equal(t, "3分钟前", RelativeTime(event))
+	assert.Equal(t, "in 2 hours", RelativeTime(fake.Now().Add(2*time.Hour), WithLocale("en")))
+	fake.Advance(time.Hour)
+	assert.Equal(t, "1小时前", RelativeTime(event))
+	fake.Advance(47 * time.Hour)
+	assert.Equal(t, "2天前", RelativeTime(event))
+
+	assert.Equal(t, "2024-03-03", GetNowDate())
+	assert.Equal(t, "2024-03-03 12:00:00", GetNowDateTime())
+	assert.Equal(t, fake.Now().Unix(), Timestamp())
+	assert.Equal(t, fake.Now().UnixMilli(), TimestampMilli())
+	assert.Equal(t, fake.Now().UTC().Format(time.DateTime), NowDateOrTime("yyyy-mm-dd hh:mm:ss", "UTC"))
+
+	SetClock(nil)
+	assert.WithinDuration(t, time.Now(), Now(), time.Second)
+}
@@ -0,0 +1,272 @@
+package dateutil
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SplitUnit Interval.SplitBy 的切分单位
+type SplitUnit int
+
+const (
+	SplitByDay   SplitUnit = iota // 按自然日
+	SplitByWeek                   // 按自然周,周一为一周开始
+	SplitByMonth                  // 按自然月
+)
+
+var ErrInvalidInterval = errors.New("dateutil: interval end is before start")
+
+// Interval 半开时间区间 [Start, End),Start 等于 End 时为空区间
+type Interval struct {
+	Start time.Time
+	End   time.Time
+}
+
+// NewInterval 创建时间区间,end 早于 start 时返回错误
+func NewInterval(start, end time.Time) (Interval, error) {
+	if end.Before(start) {
+		return Interval{}, ErrInvalidInterval
+	}
+	return Interval{Start: start, End: end}, nil
+}
+
+// IsEmpty 是否为空区间
+func (iv Interval) IsEmpty() bool {
+	return !iv.End.After(iv.Start)
+}
+
+// Duration 区间时长
+func (iv Interval) Duration() time.Duration {
+	if iv.IsEmpty() {
+		return 0
+	}
+	return iv.End.Sub(iv.Start)
+}
+
+// Contains 判断t是否在区间内,包含 Start 不包含 End
+func (iv Interval) Contains(t time.Time) bool {
+	return !t.Before(iv.Start) && t.Before(iv.End)
+}
+
+// ContainsInterval 判断o是否完全落在区间内,空区间只要起点在区间内即视为包含
+func (iv Interval) ContainsInterval(o Interval) bool {
+	if o.IsEmpty() {
+		return !o.Start.Before(iv.Start) && !o.Start.After(iv.End)
+	}
+	return !o.Start.Before(iv.Start) && !o.End.After(iv.End)
+}
+
+// Overlaps 判断两个区间是否有重叠部分,首尾相接不算重叠
+func (iv Interval) Overlaps(o Interval) bool {
+	return !iv.IsEmpty() && !o.IsEmpty() && iv.Start.Before(o.End) && o.Start.Before(iv.End)
+}
+
+// Abuts 判断两个区间是否首尾相接
+func (iv Interval) Abuts(o Interval) bool {
+	return iv.End.Equal(o.Start) || o.End.Equal(iv.Start)
+}
+
+// Intersection 返回两个区间的交集,没有重叠时 ok 为false
+func (iv Interval) Intersection(o Interval) (Interval, bool) {
+	if !iv.Overlaps(o) {
+		return Interval{}, false
+	}
+	return Interval{Start: Max(iv.Start, o.Start), End: Min(iv.End, o.End)}, true
+}
+
+// Union 合并重叠或相接的两个区间,无法合并为一个区间时 ok 为false
+func (iv Interval) Union(o Interval) (Interval, bool) {
+	if iv.IsEmpty() {
+		return o, true
+	}
+	if o.IsEmpty() {
+		return iv, true
+	}
+	if !iv.Overlaps(o) && !iv.Abuts(o) {
+		return Interval{}, false
+	}
+	return Interval{Start: Min(iv.Start, o.Start), End: Max(iv.End, o.End)}, true
+}
+
+// Subtract 从区间中减去o,返回剩余的0~2个区间
+func (iv Interval) Subtract(o Interval) []Interval {
+	if iv.IsEmpty() {
+		return nil
+	}
+	if !iv.Overlaps(o) {
+		return []Interval{iv}
+	}
+
+	result := make([]Interval, 0, 2)
+	if iv.Start.Before(o.Start) {
+		result = append(result, Interval{Start: iv.Start, End: o.Start})
+	}
+	if o.End.Before(iv.End) {
+		result = append(result, Interval{Start: o.End, End: iv.End})
+	}
+	return result
+}
+
+// SplitBy 按自然日/周/月切分区间,首尾的区间可能不完整
+func (iv Interval) SplitBy(unit SplitUnit) []Interval {
+	if iv.IsEmpty() {
+		return nil
+	}
+
+	var result []Interval
+	for start := iv.Start; start.Before(iv.End); {
+		end := nextBoundary(start, unit)
+		if end.After(iv.End) {
+			end = iv.End
+		}
+		result = append(result, Interval{Start: start, End: end})
+		start = end
+	}
+	return result
+}
+
+// String 返回区间的字符串表示
+func (iv Interval) String() string {
+	return fmt.Sprintf("[%s, %s)", iv.Start.Format(time.RFC3339), iv.End.Format(time.RFC3339))
+}
+
+// nextBoundary 返回t之后的下一个自然日/周/月起点
+func nextBoundary(t time.Time, unit SplitUnit) time.Time {
+	switch unit {
+	case SplitByWeek:
+		return BeginOfWeek(t, time.Monday).AddDate(0, 0, 7)
+	case SplitByMonth:
+		return BeginOfMonth(t).AddDate(0, 1, 0)
+	default:
+		return BeginOfDay(t).AddDate(0, 0, 1)
+	}
+}
+
+// IntervalSet 互不重叠、按起点排序的区间集合,重叠或相接的区间会自动合并
+type IntervalSet struct {
+	intervals []Interval
+}
+
+// NewIntervalSet 创建区间集合
+func NewIntervalSet(intervals ...Interval) *IntervalSet {
+	return &IntervalSet{intervals: MergeIntervals(intervals...)}
+}
+
+// MergeIntervals 合并重叠或相接的区间,返回按起点排序的结果,空区间会被丢弃
+func MergeIntervals(intervals ...Interval) []Interval {
+	sorted := make([]Interval, 0, len(intervals))
+	for _, iv := range intervals {
+		if !iv.IsEmpty() {
+			sorted = append(sorted, iv)
+		}
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Start.Before(sorted[j].Start)
+	})
+
+	merged := make([]Interval, 0, len(sorted))
+	for _, iv := range sorted {
+		if n := len(merged); n > 0 && !iv.Start.After(merged[n-1].End) {
+			if iv.End.After(merged[n-1].End) {
+				merged[n-1].End = iv.End
+			}
+			continue
+		}
+		merged = append(merged, iv)
+	}
+	return merged
+}
+
+// Add 添加区间
+func (s *IntervalSet) Add(intervals ...Interval) {
+	s.intervals = MergeIntervals(append(s.intervals, intervals...)...)
+}
+
+// Intervals 返回集合中的区间副本
+func (s *IntervalSet) Intervals() []Interval {
+	return append([]Interval(nil), s.intervals...)
+}
+
+// Len 区间个数
+func (s *IntervalSet) Len() int {
+	return len(s.intervals)
+}
+
+// Duration 所有区间的总时长
+func (s *IntervalSet) Duration() time.Duration {
+	var total time.Duration
+	for _, iv := range s.intervals {
+		total += iv.Duration()
+	}
+	return total
+}
+
+// Contains 判断t是否落在任一区间内
+func (s *IntervalSet) Contains(t time.Time) bool {
+	i := sort.Search(len(s.intervals), func(i int) bool {
+		return s.intervals[i].End.After(t)
+	})
+	return i < len(s.intervals) && s.intervals[i].Contains(t)
+}
+
+// Overlaps 判断区间是否与集合中任一区间重叠
+func (s *IntervalSet) Overlaps(iv Interval) bool {
+	for _, cur := range s.intervals {
+		if cur.Overlaps(iv) {
+			return true
+		}
+	}
+	return false
+}
+
+// Union 返回两个集合的并集
+func (s *IntervalSet) Union(o *IntervalSet) *IntervalSet {
+	return NewIntervalSet(append(s.Intervals(), o.intervals...)...)
+}
+
+// Intersection 返回两个集合的交集
+func (s *IntervalSet) Intersection(o *IntervalSet) *IntervalSet {
+	var result []Interval
+	for i, j := 0, 0; i < len(s.intervals) && j < len(o.intervals); {
+		a, b := s.intervals[i], o.intervals[j]
+		if iv, ok := a.Intersection(b); ok {
+			result = append(result, iv)
+		}
+		if a.End.Before(b.End) {
+			i++
+		} else {
+			j++
+		}
+	}
+	return &IntervalSet{intervals: result}
+}
+
+// Subtract 返回从集合中减去o之后的结果
+func (s *IntervalSet) Subtract(o *IntervalSet) *IntervalSet {
+	result := s.Intervals()
+	for _, cut := range o.intervals {
+		next := make([]Interval, 0, len(result))
+		for _, iv := range result {
+			next = append(next, iv.Subtract(cut)...)
+		}
+		result = next
+	}
+	return &IntervalSet{intervals: result}
+}
+
+// Gaps 返回bound范围内未被集合覆盖的空闲区间
+func (s *IntervalSet) Gaps(bound Interval) []Interval {
+	return NewIntervalSet(bound).Subtract(s).Intervals()
+}
+
+// String 返回集合的字符串表示
+func (s *IntervalSet) String() string {
+	parts := make([]string, len(s.intervals))
+	for i, iv := range s.intervals {
+		parts[i] = iv.String()
+	}
+	return "{" + strings.Join(parts, ", ") + "}"
+}
@@ -0,0 +1,92 @@
+package dateutil
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInterval(t *testing.T) {
+	at := func(h int) time.Time { return time.Date(2024, 3, 1, h, 0, 0, 0, time.UTC) }
+	iv := func(a, b int) Interval { return Interval{Start: at(a), End: at(b)} }
+
+	_, err := NewInterval(at(10), at(9))
+	assert.ErrorIs(t, err, ErrInvalidInterval)
+
+	a := iv(9, 12)
+	assert.True(t, a.Contains(at(9)))
+	assert.False(t, a.Contains(at(12)))
+	assert.Equal(t, 3*time.Hour, a.Duration())
+
+	// 首尾相接不算重叠,但可以合并
+	assert.False(t, a.Overlaps(iv(12, 14)))
+	u, ok := a.Union(iv(12, 14))
+	assert.True(t, ok)
+	assert.Equal(t, iv(9, 14), u)
+	_, ok = a.Union(iv(13, 14))
+	assert.False(t, ok)
+
+	x, ok := a.Intersection(iv(11, 15))
+	assert.True(t, ok)
+	assert.Equal(t, iv(11, 12), x)
+	_, ok = a.Intersection(iv(12, 15))
+	assert.False(t, ok)
+
+	assert.Equal(t, []Interval{iv(9, 10), iv(11, 12)}, a.Subtract(iv(10, 11)))
+	assert.Equal(t, []Interval{a}, a.Subtract(iv(13, 14)))
+	assert.Empty(t, a.Subtract(iv(8, 13)))
+}
+
+func TestIntervalSplitBy(t *testing.T) {
+	start := time.Date(2024, 1, 30, 18, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 3, 2, 6, 0, 0, 0, time.UTC)
+	iv := Interval{Start: start, End: end}
+
+	days := iv.SplitBy(SplitByDay)
+	assert.Len(t, days, 33)
+	assert.Equal(t, time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC), days[0].End)
+	assert.Equal(t, end, days[len(days)-1].End)
+
+	months := iv.SplitBy(SplitByMonth)
+	assert.Equal(t, []Interval{
+		{start, time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), end},
+	}, months)
+
+	// 2024-01-30 是周二,下一个周一是 2024-02-05
+	weeks := iv.SplitBy(SplitByWeek)
+	assert.Equal(t, time.Date(2024, 2, 5, 0, 0, 0, 0, time.UTC), weeks[0].End)
+	assert.Len(t, weeks, 5)
+
+	var total time.Duration
+	for _, w := range weeks {
+		total += w.Duration()
+	}
+	assert.Equal(t, iv.Duration(), total)
+}
+
+func TestIntervalSet(t *testing.T) {
+	at := func(h int) time.Time { return time.Date(2024, 3, 1, h, 0, 0, 0, time.UTC) }
+	iv := func(a, b int) Interval { return Interval{Start: at(a), End: at(b)} }
+
+	set := NewIntervalSet(iv(13, 15), iv(9, 10), iv(10, 11), iv(14, 16), iv(12, 12))
+	assert.Equal(t, []Interval{iv(9, 11), iv(13, 16)}, set.Intervals())
+	assert.Equal(t, 5*time.Hour, set.Duration())
+	assert.True(t, set.Contains(at(14)))
+	assert.False(t, set.Contains(at(11)))
+	assert.True(t, set.Overlaps(iv(10, 13)))
+	assert.False(t, set.Overlaps(iv(11, 13)))
+
+	// 工作时间内的空闲时段
+	assert.Equal(t, []Interval{iv(8, 9), iv(11, 13), iv(16, 18)}, set.Gaps(iv(8, 18)))
+
+	other := NewIntervalSet(iv(10, 14), iv(15, 20))
+	assert.Equal(t, []Interval{iv(10, 11), iv(13, 14), iv(15, 16)}, set.Intersection(other).Intervals())
+	assert.Equal(t, []Interval{iv(9, 20)}, set.Union(other).Intervals())
+	assert.Equal(t, []Interval{iv(9, 10), iv(14, 15)}, set.Subtract(other).Intervals())
+
+	set.Add(iv(11, 13))
+	assert.Equal(t, []Interval{iv(9, 16)}, set.Intervals())
+}
@@ -0,0 +1,334 @@
+package dateutil
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidISODuration = errors.New("dateutil: invalid ISO 8601 duration")
+	ErrInvalidRecurrence  = errors.New("dateutil: invalid ISO 8601 repeating interval")
+)
+
+// ISODuration ISO 8601 时长,如 P1Y2M3DT4H5M6.5S、P2W,年月按日历计算
+type ISODuration struct {
+	Negative bool
+	Years    int
+	Months   int
+	Weeks    int
+	Days     int
+	Hours    int
+	Minutes  int
+	Seconds  float64 // 仅秒允许小数
+}
+
+// ParseISODuration 解析ISO 8601时长,支持扩展的前导负号,如 -P1D
+func ParseISODuration(s string) (ISODuration, error) {
+	var d ISODuration
+	orig := s
+	if strings.HasPrefix(s, "-") {
+		d.Negative = true
+		s = s[1:]
+	} else if strings.HasPrefix(s, "+") {
+		s = s[1:]
+	}
+	if !strings.HasPrefix(s, "P") || len(s) < 3 {
+		return ISODuration{}, fmt.Errorf("%w: %q", ErrInvalidISODuration, orig)
+	}
+	s = s[1:]
+
+	inTime := false
+	last := -1 // 上一个已解析单位的次序,保证单位按 Y M W D H M S 顺序出现
+	for len(s) > 0 {
+		if s[0] == 'T' {
+			if inTime || len(s) == 1 {
+				return ISODuration{}, fmt.Errorf("%w: %q", ErrInvalidISODuration, orig)
+			}
+			inTime = true
+			s = s[1:]
+			continue
+		}
+
+		i := 0
+		for i < len(s) && (s[i] >= '0' && s[i] <= '9' || s[i] == '.' || s[i] == ',') {
+			i++
+		}
+		if i == 0 || i == len(s) {
+			return ISODuration{}, fmt.Errorf("%w: %q", ErrInvalidISODuration, orig)
+		}
+		num, unit := strings.ReplaceAll(s[:i], ",", "."), s[i]
+		s = s[i+1:]
+
+		order := strings.IndexByte("YMWD", unit)
+		if inTime {
+			order = strings.IndexByte("HMS", unit)
+			if order >= 0 {
+				order += 4
+			}
+		}
+		if order <= last {
+			return ISODuration{}, fmt.Errorf("%w: %q", ErrInvalidISODuration, orig)
+		}
+		last = order
+
+		if order == 6 {
+			v, err := strconv.ParseFloat(num, 64)
+			if err != nil {
+				return ISODuration{}, fmt.Errorf("%w: %q", ErrInvalidISODuration, orig)
+			}
+			d.Seconds = v
+			continue
+		}
+		v, err := strconv.Atoi(num)
+		if err != nil {
+			return ISODuration{}, fmt.Errorf("%w: only seconds may be fractional in %q", ErrInvalidISODuration, orig)
+		}
+		switch order {
+		case 0:
+			d.Years = v
+		case 1:
+			d.Months = v
+		case 2:
+			d.Weeks = v
+		case 3:
+			d.Days = v
+		case 4:
+			d.Hours = v
+		case 5:
+			d.Minutes = v
+		}
+	}
+	return d, nil
+}
+
+// MustParseISODuration 解析ISO 8601时长,失败时panic
+func MustParseISODuration(s string) ISODuration {
+	d, err := ParseISODuration(s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+// NewISODuration 将 time.Duration 转换为 PTnHnMnS 形式的ISO时长
+func NewISODuration(d time.Duration) ISODuration {
+	var iso ISODuration
+	if d < 0 {
+		iso.Negative = true
+		d = -d
+	}
+	iso.Hours = int(d / time.Hour)
+	d -= time.Duration(iso.Hours) * time.Hour
+	iso.Minutes = int(d / time.Minute)
+	d -= time.Duration(iso.Minutes) * time.Minute
+	iso.Seconds = d.Seconds()
+	return iso
+}
+
+// IsZero 是否为零时长
+func (d ISODuration) IsZero() bool {
+	return d.Years == 0 && d.Months == 0 && d.Weeks == 0 && d.Days == 0 &&
+		d.Hours == 0 && d.Minutes == 0 && d.Seconds == 0
+}
+
+// String 格式化为ISO 8601时长,零时长为 PT0S
+func (d ISODuration) String() string {
+	if d.IsZero() {
+		return "PT0S"
+	}
+
+	var b strings.Builder
+	if d.Negative {
+		b.WriteByte('-')
+	}
+	b.WriteByte('P')
+	for _, p := range []struct {
+		v    int
+		unit byte
+	}{{d.Years, 'Y'}, {d.Months, 'M'}, {d.Weeks, 'W'}, {d.Days, 'D'}} {
+		if p.v != 0 {
+			b.WriteString(strconv.Itoa(p.v))
+			b.WriteByte(p.unit)
+		}
+	}
+	if d.Hours != 0 || d.Minutes != 0 || d.Seconds != 0 {
+		b.WriteByte('T')
+		if d.Hours != 0 {
+			b.WriteString(strconv.Itoa(d.Hours) + "H")
+		}
+		if d.Minutes != 0 {
+			b.WriteString(strconv.Itoa(d.Minutes) + "M")
+		}
+		if d.Seconds != 0 {
+			b.WriteString(strconv.FormatFloat(d.Seconds, 'f', -1, 64) + "S")
+		}
+	}
+	return b.String()
+}
+
+// AddTo 将时长加到t上,年月使用 AddMonthSafe 保证月末安全(1月31日加1个月为2月末),
+// 周和天按日历日计算,时分秒按绝对时长计算
+func (d ISODuration) AddTo(t time.Time) time.Time {
+	sign := 1
+	if d.Negative {
+		sign = -1
+	}
+	if months := d.Years*12 + d.Months; months != 0 {
+		t = AddMonthSafe(t, sign*months)
+	}
+	if days := d.Weeks*7 + d.Days; days != 0 {
+		t = t.AddDate(0, 0, sign*days)
+	}
+	return t.Add(time.Duration(sign) * d.clockDuration())
+}
+
+// SubFrom 从t中减去时长
+func (d ISODuration) SubFrom(t time.Time) time.Time {
+	d.Negative = !d.Negative
+	return d.AddTo(t)
+}
+
+// ToDuration 转换为 time.Duration,天按24小时计算,包含年或月时返回错误
+func (d ISODuration) ToDuration() (time.Duration, error) {
+	if d.Years != 0 || d.Months != 0 {
+		return 0, fmt.Errorf("%w: years and months have no fixed length", ErrInvalidISODuration)
+	}
+	total := time.Duration(d.Weeks*7+d.Days)*24*time.Hour + d.clockDuration()
+	if d.Negative {
+		total = -total
+	}
+	return total, nil
+}
+
+// clockDuration 时分秒部分的时长
+func (d ISODuration) clockDuration() time.Duration {
+	return time.Duration(d.Hours)*time.Hour + time.Duration(d.Minutes)*time.Minute +
+		time.Duration(math.Round(d.Seconds*float64(time.Second)))
+}
+
+// scale 将各分量乘以n,用于从起点直接计算第n次重复,避免逐次相加导致月末漂移
+func (d ISODuration) scale(n int) ISODuration {
+	return ISODuration{
+		Negative: d.Negative,
+		Years:    d.Years * n, Months: d.Months * n, Weeks: d.Weeks * n, Days: d.Days * n,
+		Hours: d.Hours * n, Minutes: d.Minutes * n, Seconds: d.Seconds * float64(n),
+	}
+}
+
+// RecurringInterval ISO 8601 重复区间,如 R5/2024-01-01T00:00:00Z/P1D
+type RecurringInterval struct {
+	Repetitions int // 重复次数,即展开的区间个数,-1 表示无限
+	Start       time.Time
+	End         time.Time
+	Duration    ISODuration
+}
+
+// ParseRecurringInterval 解析重复区间,支持 R[n]/开始/时长、R[n]/时长/结束、R[n]/开始/结束 三种形式,
+// 不带时区的时间按UTC解析
+func ParseRecurringInterval(s string) (*RecurringInterval, error) {
+	parts := strings.Split(s, "/")
+	if len(parts) != 3 || !strings.HasPrefix(parts[0], "R") {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidRecurrence, s)
+	}
+
+	ri := &RecurringInterval{Repetitions: -1}
+	if n := parts[0][1:]; n != "" {
+		v, err := strconv.Atoi(n)
+		if err != nil || v < 0 {
+			return nil, fmt.Errorf("%w: bad repetitions %q", ErrInvalidRecurrence, n)
+		}
+		ri.Repetitions = v
+	}
+
+	var err error
+	switch {
+	case strings.HasPrefix(parts[1], "P") && strings.HasPrefix(parts[2], "P"):
+		return nil, fmt.Errorf("%w: %q", ErrInvalidRecurrence, s)
+	case strings.HasPrefix(parts[2], "P"):
+		if ri.Start, err = parseISOTime(parts[1]); err == nil {
+			ri.Duration, err = ParseISODuration(parts[2])
+		}
+	case strings.HasPrefix(parts[1], "P"):
+		if ri.Duration, err = ParseISODuration(parts[1]); err == nil {
+			ri.End, err = parseISOTime(parts[2])
+		}
+	default:
+		if ri.Start, err = parseISOTime(parts[1]); err == nil {
+			ri.End, err = parseISOTime(parts[2])
+		}
+		if err == nil && !ri.End.After(ri.Start) {
+			err = fmt.Errorf("%w: end must be after start", ErrInvalidRecurrence)
+		}
+		if err == nil {
+			ri.Duration = NewISODuration(ri.End.Sub(ri.Start))
+			ri.End = time.Time{}
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if ri.Duration.IsZero() || ri.Duration.Negative {
+		return nil, fmt.Errorf("%w: duration must be positive", ErrInvalidRecurrence)
+	}
+	return ri, nil
+}
+
+// Occurrences 展开重复区间,返回各次区间的开始时间,limit 限制最多返回的个数(无限重复时必须大于0)
+//
+// Rn 展开为n个区间(与多数实现一致);"时长/结束" 形式从结束时间向前推算
+func (r *RecurringInterval) Occurrences(limit int) []time.Time {
+	count := r.Repetitions
+	if r.Repetitions < 0 || (limit > 0 && count > limit) {
+		count = limit
+	}
+	if count <= 0 {
+		return nil
+	}
+
+	result := make([]time.Time, count)
+	if !r.Start.IsZero() {
+		for i := range result {
+			result[i] = r.Duration.scale(i).AddTo(r.Start)
+		}
+		return result
+	}
+	for i := range result {
+		result[count-1-i] = r.Duration.scale(i + 1).SubFrom(r.End)
+	}
+	return result
+}
+
+// Intervals 展开为时间区间,规则同 Occurrences
+func (r *RecurringInterval) Intervals(limit int) []Interval {
+	starts := r.Occurrences(limit)
+	intervals := make([]Interval, len(starts))
+	for i, start := range starts {
+		intervals[i] = Interval{Start: start, End: r.Duration.AddTo(start)}
+	}
+	return intervals
+}
+
+// String 格式化为ISO 8601重复区间
+func (r *RecurringInterval) String() string {
+	rep := "R"
+	if r.Repetitions >= 0 {
+		rep += strconv.Itoa(r.Repetitions)
+	}
+	if r.Start.IsZero() {
+		return rep + "/" + r.Duration.String() + "/" + r.End.Format(time.RFC3339Nano)
+	}
+	return rep + "/" + r.Start.Format(time.RFC3339Nano) + "/" + r.Duration.String()
+}
+
+// parseISOTime 解析重复区间中的时间,支持扩展格式和基本格式
+func parseISOTime(s string) (time.Time, error) {
+	t, _, err := ParseAny(s, WithParseLocation(time.UTC), WithParseLayouts("20060102T150405Z0700", "20060102T150405"))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: bad time %q", ErrInvalidRecurrence, s)
+	}
+	return t, nil
+}
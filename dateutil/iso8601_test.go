@@ -0,0 +1,73 @@
+package dateutil
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestISODuration(t *testing.T) {
+	d, err := ParseISODuration("P1Y2M3DT4H5M6.5S")
+	assert.NoError(t, err)
+	assert.Equal(t, ISODuration{Years: 1, Months: 2, Days: 3, Hours: 4, Minutes: 5, Seconds: 6.5}, d)
+	assert.Equal(t, "P1Y2M3DT4H5M6.5S", d.String())
+
+	for in, want := range map[string]string{"P2W": "P2W", "PT36H": "PT36H", "-P1D": "-P1D", "PT0,5S": "PT0.5S", "P0D": "PT0S"} {
+		d, err := ParseISODuration(in)
+		assert.NoError(t, err, in)
+		assert.Equal(t, want, d.String(), in)
+	}
+	for _, bad := range []string{"", "P", "PT", "1D", "P1H", "PT1D", "P1D2Y", "P1.5D", "P1DT"} {
+		_, err := ParseISODuration(bad)
+		assert.ErrorIs(t, err, ErrInvalidISODuration, bad)
+	}
+
+	// 月末安全
+	jan31 := time.Date(2024, 1, 31, 10, 0, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2024, 2, 29, 10, 0, 0, 0, time.UTC), MustParseISODuration("P1M").AddTo(jan31))
+	assert.Equal(t, time.Date(2025, 2, 28, 10, 0, 0, 0, time.UTC), MustParseISODuration("P1Y").AddTo(time.Date(2024, 2, 29, 10, 0, 0, 0, time.UTC)))
+	assert.Equal(t, time.Date(2023, 12, 31, 10, 0, 0, 0, time.UTC), MustParseISODuration("P1M").SubFrom(jan31))
+	assert.Equal(t, time.Date(2024, 2, 1, 14, 30, 0, 0, time.UTC), MustParseISODuration("P1DT4H30M").AddTo(jan31))
+
+	dur, err := MustParseISODuration("P1DT1.5S").ToDuration()
+	assert.NoError(t, err)
+	assert.Equal(t, 24*time.Hour+1500*time.Millisecond, dur)
+	_, err = MustParseISODuration("P1M").ToDuration()
+	assert.Error(t, err)
+	assert.Equal(t, "PT1H30M", NewISODuration(90*time.Minute).String())
+}
+
+func TestRecurringInterval(t *testing.T) {
+	day := func(m time.Month, d int) time.Time { return time.Date(2024, m, d, 0, 0, 0, 0, time.UTC) }
+
+	r, err := ParseRecurringInterval("R5/2024-01-01T00:00:00Z/P1D")
+	assert.NoError(t, err)
+	assert.Equal(t, []time.Time{day(1, 1), day(1, 2), day(1, 3), day(1, 4), day(1, 5)}, r.Occurrences(0))
+	assert.Equal(t, "R5/2024-01-01T00:00:00Z/P1D", r.String())
+
+	// 按月重复从起点直接计算,不会因2月而漂移到29日
+	r, err = ParseRecurringInterval("R4/2024-01-31T00:00:00Z/P1M")
+	assert.NoError(t, err)
+	assert.Equal(t, []time.Time{day(1, 31), day(2, 29), day(3, 31), day(4, 30)}, r.Occurrences(0))
+
+	// 无限重复需要限制个数
+	r, err = ParseRecurringInterval("R/20240101T000000Z/PT12H")
+	assert.NoError(t, err)
+	assert.Len(t, r.Occurrences(3), 3)
+	assert.Empty(t, r.Occurrences(0))
+
+	// 时长/结束 形式从结束时间向前推算
+	r, err = ParseRecurringInterval("R3/P1D/2024-01-10T00:00:00Z")
+	assert.NoError(t, err)
+	assert.Equal(t, []Interval{{day(1, 7), day(1, 8)}, {day(1, 8), day(1, 9)}, {day(1, 9), day(1, 10)}}, r.Intervals(0))
+
+	r, err = ParseRecurringInterval("R2/2024-01-01T09:00:00Z/2024-01-01T10:30:00Z")
+	assert.NoError(t, err)
+	assert.Equal(t, "PT1H30M", r.Duration.String())
+
+	for _, bad := range []string{"R5/P1D/P1D", "X5/2024-01-01T00:00:00Z/P1D", "R-1/2024-01-01T00:00:00Z/P1D", "R1/2024-01-02T00:00:00Z/2024-01-01T00:00:00Z", "R1/garbage/P1D"} {
+		_, err := ParseRecurringInterval(bad)
+		assert.ErrorIs(t, err, ErrInvalidRecurrence, bad)
+	}
+}
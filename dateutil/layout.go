@@ -0,0 +1,416 @@
+package dateutil
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// ParseAny 识别Unix时间戳时返回的布局名称
+const (
+	LayoutUnix      = "unix"
+	LayoutUnixMilli = "unix_milli"
+	LayoutUnixMicro = "unix_micro"
+	LayoutUnixNano  = "unix_nano"
+)
+
+var (
+	ErrUnsupportedPattern = errors.New("dateutil: unsupported pattern")
+	ErrUnrecognizedTime   = errors.New("dateutil: unrecognized time format")
+)
+
+// anyLayouts ParseAny 按顺序尝试的布局,带时区的布局在前;Go解析时会自动接受秒后的小数部分
+var anyLayouts = []string{
+	time.RFC3339,
+	"2006-01-02 15:04:05.999999999 -0700 MST",
+	"2006-01-02 15:04:05 -0700",
+	"2006-01-02 15:04:05Z07:00",
+	time.RFC1123Z,
+	time.RFC1123,
+	time.RFC850,
+	time.RFC822Z,
+	time.RFC822,
+	time.RubyDate,
+	time.UnixDate,
+	time.ANSIC,
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-1-2 15:04:05",
+	"2006-1-2 15:04",
+	"2006-1-2",
+	"2006/1/2 15:04:05",
+	"2006/1/2 15:04",
+	"2006/1/2",
+	"2006.1.2 15:04:05",
+	"2006.1.2",
+	"2006年1月2日 15时4分5秒",
+	"2006年1月2日 15:04:05",
+	"2006年1月2日 15:04",
+	"2006年1月2日15时4分5秒",
+	"2006年1月2日",
+	"2006年1月",
+	"2006-01",
+	"Jan 2, 2006 15:04:05",
+	"Jan 2, 2006",
+	"2 Jan 2006 15:04:05",
+	"2 Jan 2006",
+	"20060102150405",
+	"20060102",
+}
+
+// ParseOption ParseAny 配置选项
+type ParseOption func(*parseConfig)
+
+type parseConfig struct {
+	location *time.Location
+	layouts  []string
+}
+
+// WithParseLocation 设置不含时区信息的时间所在的时区,默认本地时区
+func WithParseLocation(loc *time.Location) ParseOption {
+	return func(c *parseConfig) {
+		if loc != nil {
+			c.location = loc
+		}
+	}
+}
+
+// WithParseLayouts 追加优先尝试的布局,支持Go布局、Java和strftime格式
+func WithParseLayouts(layouts ...string) ParseOption {
+	return func(c *parseConfig) {
+		c.layouts = append(c.layouts, layouts...)
+	}
+}
+
+// ParseAny 自动识别常见时间格式并解析,返回匹配的Go布局(时间戳返回 LayoutUnix 等)
+//
+// 支持RFC3339、RFC1123等标准格式,"2006-01-02 15:04:05"、"2006/1/2"、"2024年1月2日"、
+// 紧凑格式 "20240102"、"20240102150405",以及10/13/16/19位的Unix秒/毫秒/微秒/纳秒时间戳
+func ParseAny(value string, opts ...ParseOption) (time.Time, string, error) {
+	cfg := &parseConfig{location: time.Local}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, "", ErrUnrecognizedTime
+	}
+
+	for _, layout := range cfg.layouts {
+		goLayout, err := toGoLayout(layout)
+		if err != nil {
+			return time.Time{}, "", err
+		}
+		if t, err := time.ParseInLocation(goLayout, value, cfg.location); err == nil {
+			return t, goLayout, nil
+		}
+	}
+
+	if t, layout, ok := parseUnixDigits(value, cfg.location); ok {
+		return t, layout, nil
+	}
+
+	for _, layout := range anyLayouts {
+		if t, err := time.ParseInLocation(layout, value, cfg.location); err == nil {
+			return t, layout, nil
+		}
+	}
+	return time.Time{}, "", fmt.Errorf("%w: %q", ErrUnrecognizedTime, value)
+}
+
+// JavaToGoLayout 将Java风格的格式(如 "yyyy-MM-dd HH:mm:ss.SSS")转换为Go布局
+func JavaToGoLayout(pattern string) (string, error) {
+	return cachedLayout("java:"+pattern, func() (string, error) { return translateJava(pattern) })
+}
+
+// StrftimeToGoLayout 将strftime格式(如 "%Y/%m/%d %H:%M:%S")转换为Go布局
+func StrftimeToGoLayout(pattern string) (string, error) {
+	return cachedLayout("strftime:"+pattern, func() (string, error) { return translateStrftime(pattern) })
+}
+
+// FormatJava 按Java风格格式格式化时间
+func FormatJava(t time.Time, pattern string) (string, error) {
+	layout, err := JavaToGoLayout(pattern)
+	if err != nil {
+		return "", err
+	}
+	return t.Format(layout), nil
+}
+
+// ParseJava 按Java风格格式解析时间,可选指定时区
+func ParseJava(str, pattern string, timezone ...string) (time.Time, error) {
+	layout, err := JavaToGoLayout(pattern)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return parseInTimezone(layout, str, timezone...)
+}
+
+// FormatStrftime 按strftime格式格式化时间
+func FormatStrftime(t time.Time, pattern string) (string, error) {
+	layout, err := StrftimeToGoLayout(pattern)
+	if err != nil {
+		return "", err
+	}
+	return t.Format(layout), nil
+}
+
+// ParseStrftime 按strftime格式解析时间,可选指定时区
+func ParseStrftime(str, pattern string, timezone ...string) (time.Time, error) {
+	layout, err := StrftimeToGoLayout(pattern)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return parseInTimezone(layout, str, timezone...)
+}
+
+var layoutCache sync.Map
+
+// cachedLayout 缓存格式转换结果
+func cachedLayout(key string, translate func() (string, error)) (string, error) {
+	if v, ok := layoutCache.Load(key); ok {
+		return v.(string), nil
+	}
+	layout, err := translate()
+	if err != nil {
+		return "", err
+	}
+	layoutCache.Store(key, layout)
+	return layout, nil
+}
+
+// toGoLayout 根据格式特征识别strftime、Java或Go布局
+func toGoLayout(pattern string) (string, error) {
+	switch {
+	case strings.Contains(pattern, "%"):
+		return StrftimeToGoLayout(pattern)
+	case strings.ContainsAny(pattern, "yMdHms") && !strings.ContainsAny(pattern, "0123456789"):
+		return JavaToGoLayout(pattern)
+	default:
+		return pattern, nil
+	}
+}
+
+// parseInTimezone 与 FormatStrToTime 一致的时区处理
+func parseInTimezone(layout, str string, timezone ...string) (time.Time, error) {
+	if timezone != nil && timezone[0] != "" {
+		loc, err := time.LoadLocation(timezone[0])
+		if err != nil {
+			return time.Time{}, err
+		}
+		return time.ParseInLocation(layout, str, loc)
+	}
+	return time.Parse(layout, str)
+}
+
+// parseUnixDigits 解析纯数字的Unix时间戳,8位和14位数字留给紧凑日期布局
+func parseUnixDigits(value string, loc *time.Location) (time.Time, string, bool) {
+	digits := strings.TrimPrefix(value, "-")
+	if digits == "" || strings.IndexFunc(digits, func(r rune) bool { return r < '0' || r > '9' }) >= 0 {
+		return time.Time{}, "", false
+	}
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return time.Time{}, "", false
+	}
+
+	switch len(digits) {
+	case 9, 10:
+		return time.Unix(n, 0).In(loc), LayoutUnix, true
+	case 12, 13:
+		return time.UnixMilli(n).In(loc), LayoutUnixMilli, true
+	case 16:
+		return time.UnixMicro(n).In(loc), LayoutUnixMicro, true
+	case 19:
+		return time.Unix(0, n).In(loc), LayoutUnixNano, true
+	}
+	return time.Time{}, "", false
+}
+
+// translateJava 转换Java格式,单引号内为原样文本,两个连续的单引号表示单引号本身
+func translateJava(pattern string) (string, error) {
+	var b layoutBuilder
+	runes := []rune(pattern)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+
+		if r == '\'' {
+			if i+1 < len(runes) && runes[i+1] == '\'' {
+				if err := b.writeLiteral("'", pattern); err != nil {
+					return "", err
+				}
+				i += 2
+				continue
+			}
+			end := i + 1
+			for end < len(runes) && runes[end] != '\'' {
+				end++
+			}
+			if end == len(runes) {
+				return "", fmt.Errorf("%w: unterminated quote in %q", ErrUnsupportedPattern, pattern)
+			}
+			if err := b.writeLiteral(string(runes[i+1:end]), pattern); err != nil {
+				return "", err
+			}
+			i = end + 1
+			continue
+		}
+
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z') {
+			if err := b.writeLiteral(string(r), pattern); err != nil {
+				return "", err
+			}
+			i++
+			continue
+		}
+
+		n := 1
+		for i+n < len(runes) && runes[i+n] == r {
+			n++
+		}
+		token, err := javaToken(r, n, b.String())
+		if err != nil {
+			return "", fmt.Errorf("%w: %q in %q", err, strings.Repeat(string(r), n), pattern)
+		}
+		b.writeToken(token)
+		i += n
+	}
+	return b.String(), nil
+}
+
+// javaToken 转换单个Java格式字母,prev 为已输出的布局,用于校验毫秒前的分隔符
+func javaToken(r rune, n int, prev string) (string, error) {
+	switch r {
+	case 'y', 'u':
+		if n == 2 {
+			return "06", nil
+		}
+		return "2006", nil
+	case 'M', 'L':
+		return pick(n, "1", "01", "Jan", "January"), nil
+	case 'd':
+		return pick(n, "2", "02"), nil
+	case 'D':
+		return "002", nil
+	case 'H':
+		return "15", nil
+	case 'h':
+		return pick(n, "3", "03"), nil
+	case 'm':
+		return pick(n, "4", "04"), nil
+	case 's':
+		return pick(n, "5", "05"), nil
+	case 'S':
+		if !strings.HasSuffix(prev, ".") && !strings.HasSuffix(prev, ",") {
+			return "", fmt.Errorf("%w: fraction must follow '.' or ','", ErrUnsupportedPattern)
+		}
+		return strings.Repeat("0", n), nil
+	case 'E':
+		return pick(n, "Mon", "Mon", "Mon", "Monday"), nil
+	case 'a':
+		return "PM", nil
+	case 'z':
+		return "MST", nil
+	case 'Z':
+		return pick(n, "-0700", "-0700", "-0700", "-0700", "-07:00"), nil
+	case 'X':
+		return pick(n, "Z07", "Z0700", "Z07:00"), nil
+	case 'x':
+		return pick(n, "-07", "-0700", "-07:00"), nil
+	}
+	return "", ErrUnsupportedPattern
+}
+
+// translateStrftime 转换strftime格式,支持 %-d 形式的去零填充
+func translateStrftime(pattern string) (string, error) {
+	var b layoutBuilder
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		if c != '%' {
+			if err := b.writeLiteral(string(c), pattern); err != nil {
+				return "", err
+			}
+			continue
+		}
+		if i+1 >= len(pattern) {
+			return "", fmt.Errorf("%w: trailing %% in %q", ErrUnsupportedPattern, pattern)
+		}
+
+		i++
+		directive := string(pattern[i])
+		if (pattern[i] == '-' || pattern[i] == ':') && i+1 < len(pattern) {
+			i++
+			directive += string(pattern[i])
+		}
+
+		token, ok := strftimeTokens[directive]
+		if !ok {
+			return "", fmt.Errorf("%w: %%%s in %q", ErrUnsupportedPattern, directive, pattern)
+		}
+		if directive == "f" || directive == "L" || directive == "N" {
+			prev := b.String()
+			if !strings.HasSuffix(prev, ".") && !strings.HasSuffix(prev, ",") {
+				return "", fmt.Errorf("%w: %%%s must follow '.' or ','", ErrUnsupportedPattern, directive)
+			}
+		}
+		b.writeToken(token)
+	}
+	return b.String(), nil
+}
+
+// strftimeTokens strftime指令与Go布局的对应关系
+var strftimeTokens = map[string]string{
+	"Y": "2006", "y": "06",
+	"m": "01", "-m": "1", "b": "Jan", "h": "Jan", "B": "January",
+	"d": "02", "-d": "2", "e": "_2", "j": "002",
+	"a": "Mon", "A": "Monday",
+	"H": "15", "I": "03", "-I": "3", "M": "04", "-M": "4", "S": "05", "-S": "5", "p": "PM",
+	"f": "000000", "L": "000", "N": "000000000",
+	"z": "-0700", ":z": "-07:00", "Z": "MST",
+	"F": "2006-01-02", "T": "15:04:05", "D": "01/02/06", "R": "15:04",
+	"n": "\n", "t": "\t", "%": "%",
+}
+
+// layoutWords Go布局中以字母开头的元素,出现在原样文本中会被当作布局元素
+var layoutWords = []string{"Jan", "Mon", "MST", "PM", "pm", "Z07"}
+
+// layoutBuilder 拼接Go布局,记录末尾连续的原样文本,用于校验相邻的原样文本拼接后的结果
+type layoutBuilder struct {
+	strings.Builder
+	literal string
+}
+
+// writeToken 写入布局元素
+func (b *layoutBuilder) writeToken(s string) {
+	b.WriteString(s)
+	b.literal = ""
+}
+
+// writeLiteral 写入原样文本,数字和 Mon、Jan、PM、MST 等会被Go当作布局元素,因此不允许
+func (b *layoutBuilder) writeLiteral(s, pattern string) error {
+	if strings.IndexFunc(s, unicode.IsDigit) >= 0 {
+		return fmt.Errorf("%w: literal digits in %q", ErrUnsupportedPattern, pattern)
+	}
+	literal := b.literal + s
+	for _, w := range layoutWords {
+		if strings.Contains(literal, w) {
+			return fmt.Errorf("%w: literal %q contains layout element %q in %q", ErrUnsupportedPattern, literal, w, pattern)
+		}
+	}
+	b.WriteString(s)
+	b.literal = literal
+	return nil
+}
+
+// pick 按字母重复次数选择布局,超出时取最后一个
+func pick(n int, options ...string) string {
+	if n > len(options) {
+		n = len(options)
+	}
+	return options[n-1]
+}
@@ -0,0 +1,106 @@
+package dateutil
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPatternTranslation(t *testing.T) {
+	java := map[string]string{
+		"yyyy-MM-dd HH:mm:ss":          "2006-01-02 15:04:05",
+		"yyyy/M/d h:mm a":              "2006/1/2 3:04 PM",
+		"yyyy-MM-dd'T'HH:mm:ss.SSSXXX": "2006-01-02T15:04:05.000Z07:00",
+		"EEEE, dd MMMM yy":             "Monday, 02 January 06",
+		"yyyy年MM月dd日 ''HH''":           "2006年01月02日 '15'",
+	}
+	for in, want := range java {
+		got, err := JavaToGoLayout(in)
+		assert.NoError(t, err, in)
+		assert.Equal(t, want, got, in)
+	}
+
+	strftime := map[string]string{
+		"%Y/%m/%d":                 "2006/01/02",
+		"%F %T":                    "2006-01-02 15:04:05",
+		"%a, %d %b %Y %H:%M:%S %z": "Mon, 02 Jan 2006 15:04:05 -0700",
+		"%-m/%-d/%y %I:%M %p":      "1/2/06 03:04 PM",
+		"%H:%M:%S.%f 100%%":        "",
+	}
+	for in, want := range strftime {
+		got, err := StrftimeToGoLayout(in)
+		if want == "" {
+			assert.ErrorIs(t, err, ErrUnsupportedPattern, in)
+			continue
+		}
+		assert.NoError(t, err, in)
+		assert.Equal(t, want, got, in)
+	}
+
+	for _, bad := range []string{"yyyy-MM-dd kk", "ss SSS", "yyyy'T", "yyyy-MM-dd '1'",
+		"yyyy 'Monday' MM", "HH:mm 'PM'", "HH:mm 'pm'", "HH:mm 'MST'", "'January' yyyy", "'Mo''n' dd"} {
+		_, err := JavaToGoLayout(bad)
+		assert.ErrorIs(t, err, ErrUnsupportedPattern, bad)
+	}
+	for _, bad := range []string{"Mon %d", "%H:%M PM", "%Y MST"} {
+		_, err := StrftimeToGoLayout(bad)
+		assert.ErrorIs(t, err, ErrUnsupportedPattern, bad)
+	}
+	// 与布局元素相邻但不构成布局元素的原样文本可以使用
+	layout, err := JavaToGoLayout("EEE 'at' HH:mm 'Uhr'")
+	assert.NoError(t, err)
+	assert.Equal(t, "Mon at 15:04 Uhr", layout)
+
+	tm := time.Date(2024, 1, 2, 15, 4, 5, 123000000, time.UTC)
+	s, err := FormatJava(tm, "yyyy-MM-dd HH:mm:ss.SSS")
+	assert.NoError(t, err)
+	assert.Equal(t, "2024-01-02 15:04:05.123", s)
+	s, err = FormatStrftime(tm, "%Y/%m/%d")
+	assert.NoError(t, err)
+	assert.Equal(t, "2024/01/02", s)
+
+	parsed, err := ParseJava("2024-01-02 08:00:00", "yyyy-MM-dd HH:mm:ss", "Asia/Shanghai")
+	assert.NoError(t, err)
+	assert.True(t, parsed.Equal(time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)))
+	parsed, err = ParseStrftime("2024/01/02", "%Y/%m/%d")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), parsed)
+}
+
+func TestParseAny(t *testing.T) {
+	shanghai := time.FixedZone("CST", 8*3600)
+	want := time.Date(2024, 1, 2, 0, 0, 0, 0, shanghai)
+
+	cases := map[string]string{
+		"2024-01-02":                "2006-1-2",
+		"2024/1/2":                  "2006/1/2",
+		"2024年1月2日":                 "2006年1月2日",
+		"20240102":                  "20060102",
+		"2024-01-02T00:00:00+08:00": time.RFC3339,
+		"1704124800":                LayoutUnix,
+		"1704124800000":             LayoutUnixMilli,
+	}
+	for in, layout := range cases {
+		got, matched, err := ParseAny(in, WithParseLocation(shanghai))
+		assert.NoError(t, err, in)
+		assert.Equal(t, layout, matched, in)
+		assert.True(t, got.Equal(want), "%s: %s", in, got)
+	}
+
+	// 不含时区的时间使用指定时区,带时区的时间保留原偏移
+	got, _, err := ParseAny("2024-01-02 08:30:00", WithParseLocation(time.UTC))
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 1, 2, 8, 30, 0, 0, time.UTC), got)
+	got, _, err = ParseAny("2024-01-02T08:30:00Z", WithParseLocation(shanghai))
+	assert.NoError(t, err)
+	assert.Equal(t, time.UTC, got.Location())
+
+	got, matched, err := ParseAny("02.01.2024", WithParseLayouts("dd.MM.yyyy"), WithParseLocation(time.UTC))
+	assert.NoError(t, err)
+	assert.Equal(t, "02.01.2006", matched)
+	assert.Equal(t, time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), got)
+
+	_, _, err = ParseAny("not a time")
+	assert.ErrorIs(t, err, ErrUnrecognizedTime)
+}
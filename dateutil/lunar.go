@@ -0,0 +1,257 @@
+package dateutil
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	lunarMinYear = 1900
+	lunarMaxYear = 2100
+)
+
+var (
+	ErrLunarOutOfRange  = errors.New("lunar: date out of range [1900, 2100]")
+	ErrLunarInvalidDate = errors.New("lunar: invalid lunar date")
+)
+
+var (
+	heavenlyStems   = [10]string{"甲", "乙", "丙", "丁", "戊", "己", "庚", "辛", "壬", "癸"}
+	earthlyBranches = [12]string{"子", "丑", "寅", "卯", "辰", "巳", "午", "未", "申", "酉", "戌", "亥"}
+	zodiacAnimals   = [12]string{"鼠", "牛", "虎", "兔", "龙", "蛇", "马", "羊", "猴", "鸡", "狗", "猪"}
+	lunarMonthNames = [12]string{"正月", "二月", "三月", "四月", "五月", "六月", "七月", "八月", "九月", "十月", "冬月", "腊月"}
+	lunarDayPrefix  = [4]string{"初", "十", "廿", "卅"}
+	chineseDigits   = [11]string{"十", "一", "二", "三", "四", "五", "六", "七", "八", "九", "十"}
+)
+
+// lunarBaseDate 农历1900年正月初一对应的公历日期
+var lunarBaseDate = time.Date(1900, time.January, 31, 0, 0, 0, 0, time.UTC)
+
+// LunarDate 农历日期
+type LunarDate struct {
+	Year   int  // 农历年
+	Month  int  // 农历月,1~12
+	Day    int  // 农历日,1~30
+	IsLeap bool // 是否为闰月
+}
+
+// SolarToLunar 公历日期转农历日期,按t所在时区的日期计算,支持1900-01-31至2100年末
+func SolarToLunar(t time.Time) (LunarDate, error) {
+	y, m, d := t.Date()
+	offset := int(time.Date(y, m, d, 0, 0, 0, 0, time.UTC).Sub(lunarBaseDate).Hours() / 24)
+	if offset < 0 {
+		return LunarDate{}, ErrLunarOutOfRange
+	}
+
+	year := lunarMinYear
+	for ; year <= lunarMaxYear; year++ {
+		days := lunarYearDays(year)
+		if offset < days {
+			break
+		}
+		offset -= days
+	}
+	if year > lunarMaxYear {
+		return LunarDate{}, ErrLunarOutOfRange
+	}
+
+	leap := lunarLeapMonth(year)
+	for month := 1; month <= 12; month++ {
+		days := lunarMonthDays(year, month)
+		if offset < days {
+			return LunarDate{Year: year, Month: month, Day: offset + 1}, nil
+		}
+		offset -= days
+
+		if month == leap {
+			days = lunarLeapDays(year)
+			if offset < days {
+				return LunarDate{Year: year, Month: month, Day: offset + 1, IsLeap: true}, nil
+			}
+			offset -= days
+		}
+	}
+	return LunarDate{}, ErrLunarOutOfRange
+}
+
+// LunarToSolar 农历日期转公历日期,返回本地时区零点
+func LunarToSolar(year, month, day int, isLeap bool) (time.Time, error) {
+	if year < lunarMinYear || year > lunarMaxYear {
+		return time.Time{}, ErrLunarOutOfRange
+	}
+	if month < 1 || month > 12 || day < 1 {
+		return time.Time{}, ErrLunarInvalidDate
+	}
+
+	leap := lunarLeapMonth(year)
+	if isLeap && leap != month {
+		return time.Time{}, fmt.Errorf("%w: year %d has no leap month %d", ErrLunarInvalidDate, year, month)
+	}
+	maxDay := lunarMonthDays(year, month)
+	if isLeap {
+		maxDay = lunarLeapDays(year)
+	}
+	if day > maxDay {
+		return time.Time{}, fmt.Errorf("%w: month has only %d days", ErrLunarInvalidDate, maxDay)
+	}
+
+	offset := 0
+	for y := lunarMinYear; y < year; y++ {
+		offset += lunarYearDays(y)
+	}
+	for m := 1; m < month; m++ {
+		offset += lunarMonthDays(year, m)
+		if m == leap {
+			offset += lunarLeapDays(year)
+		}
+	}
+	if isLeap {
+		offset += lunarMonthDays(year, month)
+	}
+	offset += day - 1
+
+	solar := lunarBaseDate.AddDate(0, 0, offset)
+	return time.Date(solar.Year(), solar.Month(), solar.Day(), 0, 0, 0, 0, time.Local), nil
+}
+
+// LunarLeapMonth 返回农历年的闰月,无闰月返回0
+func LunarLeapMonth(year int) int {
+	if year < lunarMinYear || year > lunarMaxYear {
+		return 0
+	}
+	return lunarLeapMonth(year)
+}
+
+// LunarMonthDays 返回农历月的天数
+func LunarMonthDays(year, month int, isLeap bool) (int, error) {
+	if year < lunarMinYear || year > lunarMaxYear {
+		return 0, ErrLunarOutOfRange
+	}
+	if month < 1 || month > 12 || (isLeap && lunarLeapMonth(year) != month) {
+		return 0, ErrLunarInvalidDate
+	}
+	if isLeap {
+		return lunarLeapDays(year), nil
+	}
+	return lunarMonthDays(year, month), nil
+}
+
+// ToSolar 转换为公历日期
+func (l LunarDate) ToSolar() (time.Time, error) {
+	return LunarToSolar(l.Year, l.Month, l.Day, l.IsLeap)
+}
+
+// MonthName 返回农历月份名称,如"正月"、"闰四月"
+func (l LunarDate) MonthName() string {
+	if l.Month < 1 || l.Month > 12 {
+		return ""
+	}
+	if l.IsLeap {
+		return "闰" + lunarMonthNames[l.Month-1]
+	}
+	return lunarMonthNames[l.Month-1]
+}
+
+// DayName 返回农历日名称,如"初一"、"廿三"
+func (l LunarDate) DayName() string {
+	switch {
+	case l.Day < 1 || l.Day > 30:
+		return ""
+	case l.Day == 10:
+		return "初十"
+	case l.Day == 20:
+		return "二十"
+	case l.Day == 30:
+		return "三十"
+	}
+	return lunarDayPrefix[l.Day/10] + chineseDigits[l.Day%10]
+}
+
+// GanZhiYear 返回农历年的干支纪年,如"甲辰"
+func (l LunarDate) GanZhiYear() string {
+	return GanZhiYear(l.Year)
+}
+
+// Zodiac 返回农历年的生肖
+func (l LunarDate) Zodiac() string {
+	return Zodiac(l.Year)
+}
+
+// String 返回如"甲辰年正月初一"的农历日期字符串
+func (l LunarDate) String() string {
+	return l.GanZhiYear() + "年" + l.MonthName() + l.DayName()
+}
+
+// GanZhiYear 返回农历年份的干支纪年
+func GanZhiYear(year int) string {
+	i := ((year-4)%60 + 60) % 60
+	return heavenlyStems[i%10] + earthlyBranches[i%12]
+}
+
+// Zodiac 返回农历年份的生肖
+func Zodiac(year int) string {
+	return zodiacAnimals[((year-4)%12+12)%12]
+}
+
+// lunarYearDays 农历年总天数
+func lunarYearDays(year int) int {
+	days := 348
+	info := lunarInfo[year-lunarMinYear]
+	for mask := 0x8000; mask > 0x8; mask >>= 1 {
+		if info&mask != 0 {
+			days++
+		}
+	}
+	return days + lunarLeapDays(year)
+}
+
+// lunarLeapMonth 闰月月份,0表示无闰月
+func lunarLeapMonth(year int) int {
+	return lunarInfo[year-lunarMinYear] & 0xf
+}
+
+// lunarLeapDays 闰月天数
+func lunarLeapDays(year int) int {
+	if lunarLeapMonth(year) == 0 {
+		return 0
+	}
+	if lunarInfo[year-lunarMinYear]&0x10000 != 0 {
+		return 30
+	}
+	return 29
+}
+
+// lunarMonthDays 农历非闰月天数
+func lunarMonthDays(year, month int) int {
+	if lunarInfo[year-lunarMinYear]&(0x10000>>month) != 0 {
+		return 30
+	}
+	return 29
+}
+
+// lunarInfo 1900-2100年农历数据(香港天文台),每年20位:
+// 第0-3位为闰月月份,第4-15位依次为12月至正月的大小月(1为30天),第16位为闰月大小
+var lunarInfo = [...]int{
+	0x04bd8, 0x04ae0, 0x0a570, 0x054d5, 0x0d260, 0x0d950, 0x16554, 0x056a0, 0x09ad0, 0x055d2, // 1900-1909
+	0x04ae0, 0x0a5b6, 0x0a4d0, 0x0d250, 0x1d255, 0x0b540, 0x0d6a0, 0x0ada2, 0x095b0, 0x14977, // 1910-1919
+	0x04970, 0x0a4b0, 0x0b4b5, 0x06a50, 0x06d40, 0x1ab54, 0x02b60, 0x09570, 0x052f2, 0x04970, // 1920-1929
+	0x06566, 0x0d4a0, 0x0ea50, 0x16a95, 0x05ad0, 0x02b60, 0x186e3, 0x092e0, 0x1c8d7, 0x0c950, // 1930-1939
+	0x0d4a0, 0x1d8a6, 0x0b550, 0x056a0, 0x1a5b4, 0x025d0, 0x092d0, 0x0d2b2, 0x0a950, 0x0b557, // 1940-1949
+	0x06ca0, 0x0b550, 0x15355, 0x04da0, 0x0a5b0, 0x14573, 0x052b0, 0x0a9a8, 0x0e950, 0x06aa0, // 1950-1959
+	0x0aea6, 0x0ab50, 0x04b60, 0x0aae4, 0x0a570, 0x05260, 0x0f263, 0x0d950, 0x05b57, 0x056a0, // 1960-1969
+	0x096d0, 0x04dd5, 0x04ad0, 0x0a4d0, 0x0d4d4, 0x0d250, 0x0d558, 0x0b540, 0x0b6a0, 0x195a6, // 1970-1979
+	0x095b0, 0x049b0, 0x0a974, 0x0a4b0, 0x0b27a, 0x06a50, 0x06d40, 0x0af46, 0x0ab60, 0x09570, // 1980-1989
+	0x04af5, 0x04970, 0x064b0, 0x074a3, 0x0ea50, 0x06b58, 0x05ac0, 0x0ab60, 0x096d5, 0x092e0, // 1990-1999
+	0x0c960, 0x0d954, 0x0d4a0, 0x0da50, 0x07552, 0x056a0, 0x0abb7, 0x025d0, 0x092d0, 0x0cab5, // 2000-2009
+	0x0a950, 0x0b4a0, 0x0baa4, 0x0ad50, 0x055d9, 0x04ba0, 0x0a5b0, 0x15176, 0x052b0, 0x0a930, // 2010-2019
+	0x07954, 0x06aa0, 0x0ad50, 0x05b52, 0x04b60, 0x0a6e6, 0x0a4e0, 0x0d260, 0x0ea65, 0x0d530, // 2020-2029
+	0x05aa0, 0x076a3, 0x096d0, 0x04afb, 0x04ad0, 0x0a4d0, 0x1d0b6, 0x0d250, 0x0d520, 0x0dd45, // 2030-2039
+	0x0b5a0, 0x056d0, 0x055b2, 0x049b0, 0x0a577, 0x0a4b0, 0x0aa50, 0x1b255, 0x06d20, 0x0ada0, // 2040-2049
+	0x14b63, 0x09370, 0x049f8, 0x04970, 0x064b0, 0x168a6, 0x0ea50, 0x06b20, 0x1a6c4, 0x0aae0, // 2050-2059
+	0x092e0, 0x0d2e3, 0x0c960, 0x0d557, 0x0d4a0, 0x0da50, 0x05d55, 0x056a0, 0x0a6d0, 0x055d4, // 2060-2069
+	0x052d0, 0x0a9b8, 0x0a950, 0x0b4a0, 0x0b6a6, 0x0ad50, 0x055a0, 0x0aba4, 0x0a5b0, 0x052b0, // 2070-2079
+	0x0b273, 0x06930, 0x07337, 0x06aa0, 0x0ad50, 0x14b55, 0x04b60, 0x0a570, 0x054e4, 0x0d160, // 2080-2089
+	0x0e968, 0x0d520, 0x0daa0, 0x16aa6, 0x056d0, 0x04ae0, 0x0a9d4, 0x0a2d0, 0x0d150, 0x0f252, // 2090-2099
+	0x0d520, // 2100
+}
@@ -0,0 +1,138 @@
+package dateutil
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSolarToLunar(t *testing.T) {
+	// 春节日期
+	newYears := map[int]string{
+		1949: "1949-01-29", 2000: "2000-02-05", 2008: "2008-02-07", 2020: "2020-01-25",
+		2023: "2023-01-22", 2024: "2024-02-10", 2025: "2025-01-29", 2026: "2026-02-17",
+	}
+	for year, date := range newYears {
+		d, _ := time.Parse(dateLayout, date)
+		lunar, err := SolarToLunar(d)
+		assert.NoError(t, err)
+		assert.Equal(t, LunarDate{Year: year, Month: 1, Day: 1}, lunar)
+
+		solar, err := LunarToSolar(year, 1, 1, false)
+		assert.NoError(t, err)
+		assert.Equal(t, date, solar.Format(dateLayout))
+
+		// 除夕
+		eve, err := SolarToLunar(d.AddDate(0, 0, -1))
+		assert.NoError(t, err)
+		assert.Equal(t, year-1, eve.Year)
+		assert.Equal(t, 12, eve.Month)
+	}
+
+	// 2023年闰二月
+	lunar, err := SolarToLunar(time.Date(2023, 3, 22, 12, 0, 0, 0, time.Local))
+	assert.NoError(t, err)
+	assert.Equal(t, LunarDate{Year: 2023, Month: 2, Day: 1, IsLeap: true}, lunar)
+	assert.Equal(t, "癸卯年闰二月初一", lunar.String())
+	assert.Equal(t, "兔", lunar.Zodiac())
+
+	_, err = LunarToSolar(2024, 2, 1, true)
+	assert.ErrorIs(t, err, ErrLunarInvalidDate)
+	_, err = SolarToLunar(time.Date(1899, 12, 31, 0, 0, 0, 0, time.UTC))
+	assert.ErrorIs(t, err, ErrLunarOutOfRange)
+
+	// 往返转换
+	for d := time.Date(2024, 1, 1, 0, 0, 0, 0, time.Local); d.Year() < 2026; d = d.AddDate(0, 0, 1) {
+		l, err := SolarToLunar(d)
+		assert.NoError(t, err)
+		back, err := l.ToSolar()
+		assert.NoError(t, err)
+		assert.True(t, back.Equal(d), "%s -> %v -> %s", d, l, back)
+	}
+}
+
+func TestLunarNames(t *testing.T) {
+	assert.Equal(t, "甲辰", GanZhiYear(2024))
+	assert.Equal(t, "龙", Zodiac(2024))
+	assert.Equal(t, "甲子", GanZhiYear(1984))
+
+	days := make([]string, 0, 30)
+	for d := 1; d <= 30; d++ {
+		days = append(days, LunarDate{Day: d}.DayName())
+	}
+	assert.Equal(t, "初一 初二 初三 初四 初五 初六 初七 初八 初九 初十 十一 十二 十三 十四 十五 十六 十七 十八 十九 二十 廿一 廿二 廿三 廿四 廿五 廿六 廿七 廿八 廿九 三十",
+		strings.Join(days, " "))
+	assert.Equal(t, "腊月", LunarDate{Month: 12}.MonthName())
+}
+
+func TestSolarTerms(t *testing.T) {
+	cases := []struct {
+		year int
+		term SolarTerm
+		want string
+	}{
+		{2024, StartOfSpring, "2024-02-04 16:27"},
+		{2024, SpringEquinox, "2024-03-20 11:06"},
+		{2024, SummerSolstice, "2024-06-21 04:50"},
+		{2024, WinterSolstice, "2024-12-21 17:20"},
+		{2025, MinorCold, "2025-01-05 10:32"},
+		{2024, PureBrightness, "2024-04-04 15:02"},
+		{2025, PureBrightness, "2025-04-04 20:48"},
+	}
+	for _, c := range cases {
+		st, err := SolarTermTime(c.year, c.term)
+		assert.NoError(t, err)
+		want, _ := time.ParseInLocation("2006-01-02 15:04", c.want, chinaStandardTime)
+		assert.InDelta(t, 0, st.Sub(want).Minutes(), 2, "%s %d", c.term, c.year)
+	}
+
+	term, ok := GetSolarTerm(time.Date(2024, 4, 4, 0, 0, 0, 0, chinaStandardTime))
+	assert.True(t, ok)
+	assert.Equal(t, "清明", term.String())
+	_, ok = GetSolarTerm(time.Date(2024, 4, 5, 0, 0, 0, 0, chinaStandardTime))
+	assert.False(t, ok)
+	// 非东八区的输入按东八区日期判断: UTC 4月3日17点为东八区4月4日1点
+	term, ok = GetSolarTerm(time.Date(2024, 4, 3, 17, 0, 0, 0, time.UTC))
+	assert.True(t, ok)
+	assert.Equal(t, PureBrightness, term)
+	_, ok = GetSolarTerm(time.Date(2024, 4, 4, 17, 0, 0, 0, time.UTC))
+	assert.False(t, ok)
+	ny, _ := time.LoadLocation("America/New_York")
+	term, ok = GetSolarTerm(time.Date(2024, 12, 20, 23, 30, 0, 0, ny))
+	assert.True(t, ok)
+	assert.Equal(t, WinterSolstice, term)
+
+	terms, err := SolarTermsOfYear(2026)
+	assert.NoError(t, err)
+	assert.Len(t, terms, 24)
+	for i := 1; i < len(terms); i++ {
+		assert.True(t, terms[i].Time.After(terms[i-1].Time))
+	}
+}
+
+func TestHolidays(t *testing.T) {
+	day := func(s string) time.Time {
+		d, _ := time.ParseInLocation(dateLayout, s, time.Local)
+		return d
+	}
+
+	assert.True(t, IsHoliday(day("2024-10-03")))
+	assert.True(t, IsAdjustedWorkday(day("2024-09-29")))
+	info, ok := GetHolidayInfo(day("2025-01-29"))
+	assert.True(t, ok)
+	assert.Equal(t, HolidayInfo{Name: "春节", Kind: StatutoryHoliday}, info)
+	assert.False(t, IsHoliday(day("2024-10-08")))
+
+	custom, err := LoadHolidayTable(strings.NewReader(`[{"name":"公司年会","from":"2030-01-10","to":"2030-01-11","workdays":["2030-01-12"]}]`))
+	assert.NoError(t, err)
+	assert.True(t, custom.IsHoliday(day("2030-01-11")))
+	assert.True(t, custom.IsAdjustedWorkday(day("2030-01-12")))
+
+	old := DefaultHolidayTable()
+	SetHolidayTable(custom)
+	defer SetHolidayTable(old)
+	assert.True(t, IsHoliday(day("2030-01-10")))
+	assert.False(t, IsHoliday(day("2024-10-03")))
+}
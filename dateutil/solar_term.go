@@ -0,0 +1,274 @@
+package dateutil
+
+import (
+	"errors"
+	"math"
+	"time"
+)
+
+// SolarTerm 二十四节气,按公历年内顺序从小寒开始
+type SolarTerm int
+
+const (
+	MinorCold          SolarTerm = iota // 小寒
+	MajorCold                           // 大寒
+	StartOfSpring                       // 立春
+	RainWater                           // 雨水
+	AwakeningOfInsects                  // 惊蛰
+	SpringEquinox                       // 春分
+	PureBrightness                      // 清明
+	GrainRain                           // 谷雨
+	StartOfSummer                       // 立夏
+	GrainBuds                           // 小满
+	GrainInEar                          // 芒种
+	SummerSolstice                      // 夏至
+	MinorHeat                           // 小暑
+	MajorHeat                           // 大暑
+	StartOfAutumn                       // 立秋
+	EndOfHeat                           // 处暑
+	WhiteDew                            // 白露
+	AutumnEquinox                       // 秋分
+	ColdDew                             // 寒露
+	FrostDescent                        // 霜降
+	StartOfWinter                       // 立冬
+	MinorSnow                           // 小雪
+	MajorSnow                           // 大雪
+	WinterSolstice                      // 冬至
+)
+
+var solarTermNames = [24]string{
+	"小寒", "大寒", "立春", "雨水", "惊蛰", "春分", "清明", "谷雨",
+	"立夏", "小满", "芒种", "夏至", "小暑", "大暑", "立秋", "处暑",
+	"白露", "秋分", "寒露", "霜降", "立冬", "小雪", "大雪", "冬至",
+}
+
+// chinaStandardTime 节气与农历均以东八区时间为准
+var chinaStandardTime = time.FixedZone("CST", 8*3600)
+
+// ErrSolarTermYearOutOfRange 节气计算年份超出范围
+var ErrSolarTermYearOutOfRange = errors.New("solar term: year out of range [1900, 2100]")
+
+// String 返回节气名称
+func (s SolarTerm) String() string {
+	if s < MinorCold || s > WinterSolstice {
+		return ""
+	}
+	return solarTermNames[s]
+}
+
+// Longitude 返回节气对应的太阳视黄经(度)
+func (s SolarTerm) Longitude() float64 {
+	return math.Mod(285+15*float64(s), 360)
+}
+
+// IsMajor 是否为中气(黄经为30度的整数倍),农历置闰以中气为准
+func (s SolarTerm) IsMajor() bool {
+	return s%2 == 1
+}
+
+// SolarTermInfo 节气及其交节时刻
+type SolarTermInfo struct {
+	Term SolarTerm
+	Name string
+	Time time.Time // 东八区交节时刻
+}
+
+// SolarTermTime 返回指定年份节气的交节时刻(东八区),精度约为1分钟
+func SolarTermTime(year int, term SolarTerm) (time.Time, error) {
+	if year < 1900 || year > 2100 {
+		return time.Time{}, ErrSolarTermYearOutOfRange
+	}
+	if term < MinorCold || term > WinterSolstice {
+		return time.Time{}, errors.New("solar term: invalid term")
+	}
+
+	// 以平均回归年估算初值后迭代逼近
+	angle := term.Longitude()
+	jde := julianDay(year, 1, 6) + float64(term)*365.2422/24
+	for i := 0; i < 20; i++ {
+		diff := math.Mod(angle-sunApparentLongitude(jde)+540, 360) - 180
+		jde += diff * 365.2422 / 360
+		if math.Abs(diff) < 1e-7 {
+			break
+		}
+	}
+
+	// 力学时转换为世界时
+	jd := jde - deltaT(float64(year)+float64(term)/24)/86400
+	return julianDayToTime(jd).In(chinaStandardTime), nil
+}
+
+// SolarTermsOfYear 返回指定年份的全部二十四节气
+func SolarTermsOfYear(year int) ([]SolarTermInfo, error) {
+	terms := make([]SolarTermInfo, 0, 24)
+	for s := MinorCold; s <= WinterSolstice; s++ {
+		t, err := SolarTermTime(year, s)
+		if err != nil {
+			return nil, err
+		}
+		terms = append(terms, SolarTermInfo{Term: s, Name: s.String(), Time: t})
+	}
+	return terms, nil
+}
+
+// GetSolarTerm 判断日期(按东八区日期)是否为节气日,返回对应节气
+func GetSolarTerm(t time.Time) (SolarTerm, bool) {
+	y, m, d := t.In(chinaStandardTime).Date()
+	// 每月两个节气,分别位于月初和月中后
+	for _, s := range []SolarTerm{SolarTerm(2 * (int(m) - 1)), SolarTerm(2*(int(m)-1) + 1)} {
+		st, err := SolarTermTime(y, s)
+		if err != nil {
+			return 0, false
+		}
+		sy, sm, sd := st.Date()
+		if sy == y && sm == m && sd == d {
+			return s, true
+		}
+	}
+	return 0, false
+}
+
+// NextSolarTerm 返回t之后(含t)的第一个节气
+func NextSolarTerm(t time.Time) (SolarTermInfo, error) {
+	year := t.In(chinaStandardTime).Year()
+	for y := year; y <= year+1; y++ {
+		terms, err := SolarTermsOfYear(y)
+		if err != nil {
+			return SolarTermInfo{}, err
+		}
+		for _, info := range terms {
+			if !info.Time.Before(t) {
+				return info, nil
+			}
+		}
+	}
+	return SolarTermInfo{}, ErrSolarTermYearOutOfRange
+}
+
+// sunApparentLongitude 计算太阳视黄经(度),基于截断的VSOP87地球黄经级数
+func sunApparentLongitude(jde float64) float64 {
+	tau := (jde - 2451545.0) / 365250
+	series := [][][3]float64{vsopL0, vsopL1, vsopL2, vsopL3, vsopL4, vsopL5}
+
+	var l, pow float64 = 0, 1
+	for _, terms := range series {
+		var sum float64
+		for _, term := range terms {
+			sum += term[0] * math.Cos(term[1]+term[2]*tau)
+		}
+		l += sum * pow
+		pow *= tau
+	}
+	// 日心黄经转换为地心黄经,并转换到FK5
+	theta := l/1e8*180/math.Pi + 180 - 0.09033/3600
+
+	t := tau * 10
+	omega := degToRad(125.04452 - 1934.136261*t)
+	ls := degToRad(280.4665 + 36000.7698*t)
+	lm := degToRad(218.3165 + 481267.8813*t)
+	nutation := -17.20*math.Sin(omega) - 1.32*math.Sin(2*ls) - 0.23*math.Sin(2*lm) + 0.21*math.Sin(2*omega)
+
+	e := 0.016708634 - 0.000042037*t
+	m := degToRad(357.52911 + 35999.05029*t)
+	c := (1.914602-0.004817*t)*math.Sin(m) + 0.019993*math.Sin(2*m)
+	r := 1.000001018 * (1 - e*e) / (1 + e*math.Cos(m+degToRad(c)))
+	aberration := -20.4898 / r
+
+	return math.Mod(theta+(nutation+aberration)/3600+720, 360)
+}
+
+// deltaT 力学时与世界时之差(秒),Espenak-Meeus 多项式
+func deltaT(y float64) float64 {
+	switch {
+	case y < 1920:
+		t := y - 1900
+		return -2.79 + 1.494119*t - 0.0598939*t*t + 0.0061966*t*t*t - 0.000197*t*t*t*t
+	case y < 1941:
+		t := y - 1920
+		return 21.20 + 0.84493*t - 0.076100*t*t + 0.0020936*t*t*t
+	case y < 1961:
+		t := y - 1950
+		return 29.07 + 0.407*t - t*t/233 + t*t*t/2547
+	case y < 1986:
+		t := y - 1975
+		return 45.45 + 1.067*t - t*t/260 - t*t*t/718
+	case y < 2005:
+		t := y - 2000
+		return 63.86 + 0.3345*t - 0.060374*t*t + 0.0017275*t*t*t + 0.000651814*t*t*t*t + 0.00002373599*t*t*t*t*t
+	case y < 2050:
+		t := y - 2000
+		return 62.92 + 0.32217*t + 0.005589*t*t
+	default:
+		u := (y - 1820) / 100
+		return -20 + 32*u*u - 0.5628*(2150-y)
+	}
+}
+
+// julianDay 公历日期转儒略日
+func julianDay(year, month int, day float64) float64 {
+	if month <= 2 {
+		year--
+		month += 12
+	}
+	a := year / 100
+	b := 2 - a + a/4
+	return math.Floor(365.25*float64(year+4716)) + math.Floor(30.6001*float64(month+1)) + day + float64(b) - 1524.5
+}
+
+// julianDayToTime 儒略日转UTC时间
+func julianDayToTime(jd float64) time.Time {
+	const unixEpochJD = 2440587.5
+	ms := math.Round((jd - unixEpochJD) * 86400 * 1000)
+	return time.UnixMilli(int64(ms)).UTC()
+}
+
+// degToRad 角度转弧度
+func degToRad(d float64) float64 {
+	return d * math.Pi / 180
+}
+
+// 截断的VSOP87地球日心黄经级数(Meeus《天文算法》附录),单位1e-8弧度
+var (
+	vsopL0 = [][3]float64{
+		{175347046, 0, 0}, {3341656, 4.6692568, 6283.07585}, {34894, 4.6261, 12566.1517},
+		{3497, 2.7441, 5753.3849}, {3418, 2.8289, 3.5231}, {3136, 3.6277, 77713.7715}, {2676, 4.4181, 7860.4194},
+		{2343, 6.1352, 3930.2097}, {1324, 0.7425, 11506.7698}, {1273, 2.0371, 529.691}, {1199, 1.1096, 1577.3435},
+		{990, 5.233, 5884.927}, {902, 2.045, 26.298}, {857, 3.508, 398.149}, {780, 1.179, 5223.694},
+		{753, 2.533, 5507.553}, {505, 4.583, 18849.228}, {492, 4.205, 775.523}, {357, 2.92, 0.067},
+		{317, 5.849, 11790.629}, {284, 1.899, 796.298}, {271, 0.315, 10977.079}, {243, 0.345, 5486.778},
+		{206, 4.806, 2544.314}, {205, 1.869, 5573.143}, {202, 2.458, 6069.777}, {156, 0.833, 213.299},
+		{132, 3.411, 2942.463}, {126, 1.083, 20.775}, {115, 0.645, 0.98}, {103, 0.636, 4694.003},
+		{102, 0.976, 15720.839}, {102, 4.267, 7.114}, {99, 6.21, 2146.17}, {98, 0.68, 155.42},
+		{86, 5.98, 161000.69}, {85, 1.3, 6275.96}, {85, 3.67, 71430.7}, {80, 1.81, 17260.15}, {79, 3.04, 12036.46},
+		{75, 1.76, 5088.63}, {74, 3.5, 3154.69}, {74, 4.68, 801.82}, {70, 0.83, 9437.76}, {62, 3.98, 8827.39},
+		{61, 1.82, 7084.9}, {57, 2.78, 6286.6}, {56, 4.39, 14143.5}, {56, 3.47, 6279.55}, {52, 0.19, 12139.55},
+		{52, 1.33, 1748.02}, {51, 0.28, 5856.48}, {49, 0.49, 1194.45}, {41, 5.37, 8429.24}, {41, 2.4, 19651.05},
+		{39, 6.17, 10447.39}, {37, 6.04, 10213.29}, {37, 2.57, 1059.38}, {36, 1.71, 2352.87}, {36, 1.78, 6812.77},
+		{33, 0.59, 17789.85}, {30, 0.44, 83996.85}, {30, 2.74, 1349.87}, {25, 3.16, 4690.48},
+	}
+	vsopL1 = [][3]float64{
+		{628331966747, 0, 0}, {206059, 2.678235, 6283.07585}, {4303, 2.6351, 12566.1517}, {425, 1.59, 3.523},
+		{119, 5.796, 26.298}, {109, 2.966, 1577.344}, {93, 2.59, 18849.23}, {72, 1.14, 529.69}, {68, 1.87, 398.15},
+		{67, 4.41, 5507.55}, {59, 2.89, 5223.69}, {56, 2.17, 155.42}, {45, 0.4, 796.3}, {36, 0.47, 775.52},
+		{29, 2.65, 7.11}, {21, 5.34, 0.98}, {19, 1.85, 5486.78}, {19, 4.97, 213.3}, {17, 2.99, 6275.96},
+		{16, 0.03, 2544.31}, {16, 1.43, 2146.17}, {15, 1.21, 10977.08}, {12, 2.83, 1748.02}, {12, 3.26, 5088.63},
+		{12, 5.27, 1194.45}, {12, 2.08, 4694.0}, {11, 0.77, 553.57}, {10, 1.3, 6286.6}, {10, 4.24, 1349.87},
+		{9, 2.7, 242.73}, {9, 5.64, 951.72}, {8, 5.3, 2352.87}, {6, 2.65, 9437.76}, {6, 4.67, 4690.48},
+	}
+	vsopL2 = [][3]float64{
+		{52919, 0, 0}, {8720, 1.0721, 6283.0758}, {309, 0.867, 12566.152}, {27, 0.05, 3.52}, {16, 5.19, 26.3},
+		{16, 3.68, 155.42}, {10, 0.76, 18849.23}, {9, 2.06, 77713.77}, {7, 0.83, 775.52}, {5, 4.66, 1577.34},
+		{4, 1.03, 7.11}, {4, 3.44, 5573.14}, {3, 5.14, 796.3}, {3, 6.05, 5507.55}, {3, 1.19, 242.73},
+		{3, 6.12, 529.69}, {3, 0.31, 398.15}, {3, 2.28, 553.57}, {2, 4.38, 5223.69}, {2, 3.75, 0.98},
+	}
+	vsopL3 = [][3]float64{
+		{289, 5.844, 6283.076}, {35, 0, 0}, {17, 5.49, 12566.15}, {3, 5.2, 155.42}, {1, 4.72, 3.52},
+		{1, 5.3, 18849.23}, {1, 5.97, 242.73},
+	}
+	vsopL4 = [][3]float64{
+		{114, 3.142, 0}, {8, 4.13, 6283.08}, {1, 3.84, 12566.15},
+	}
+	vsopL5 = [][3]float64{
+		{1, 3.14, 0},
+	}
+)
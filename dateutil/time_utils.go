@@ -120,32 +120,32 @@ func AddYearSafe(t time.Time, years int) time.Time {
 
 // GetNowDate 获取当天日期，返回格式：yyyy-mm-dd
 func GetNowDate() string {
-	return time.Now().Format("2006-01-02")
+	return Now().Format("2006-01-02")
 }
 
 // GetNowTime 获取当时时间，返回格式：hh:mm:ss
 func GetNowTime() string {
-	return time.Now().Format("15:04:05")
+	return Now().Format("15:04:05")
 }
 
 // GetNowDateTime 获取当时日期和时间，返回格式：yyyy-mm-dd hh:mm:ss
 func GetNowDateTime() string {
-	return time.Now().Format("2006-01-02 15:04:05")
+	return Now().Format("2006-01-02 15:04:05")
 }
 
 // GetTodayStartTime 返回当天开始时间， 格式: yyyy-mm-dd 00:00:00
 func GetTodayStartTime() string {
-	return time.Now().Format("2006-01-02") + " 00:00:00"
+	return Now().Format("2006-01-02") + " 00:00:00"
 }
 
 // GetTodayEndTime 返回当天结束时间，格式: yyyy-mm-dd 23:59:59
 func GetTodayEndTime() string {
-	return time.Now().Format("2006-01-02") + " 23:59:59"
+	return Now().Format("2006-01-02") + " 23:59:59"
 }
 
 // GetZeroHourTimestamp 获取零点时间戳(timestamp of 00:00)
 func GetZeroHourTimestamp() int64 {
-	ts := time.Now().Format("2006-01-02")
+	ts := Now().Format("2006-01-02")
 	t, _ := time.Parse("2006-01-02", ts)
 	return t.UTC().Unix() - 8*3600
 }
@@ -300,14 +300,14 @@ func NowDateOrTime(format string, timezone ...string) string {
 		if err != nil {
 			return ""
 		}
-		return time.Now().In(loc).Format(tf)
+		return Now().In(loc).Format(tf)
 	}
-	return time.Now().Format(tf)
+	return Now().Format(tf)
 }
 
 // Timestamp 返回当前秒级时间戳
 func Timestamp(timezone ...string) int64 {
-	t := time.Now()
+	t := Now()
 
 	if timezone != nil && timezone[0] != "" {
 		loc, err := time.LoadLocation(timezone[0])
@@ -323,7 +323,7 @@ func Timestamp(timezone ...string) int64 {
 
 // TimestampMilli 返回当前毫秒级时间戳
 func TimestampMilli(timezone ...string) int64 {
-	t := time.Now()
+	t := Now()
 
 	if timezone != nil && timezone[0] != "" {
 		loc, err := time.LoadLocation(timezone[0])
@@ -338,7 +338,7 @@ func TimestampMilli(timezone ...string) int64 {
 
 // TimestampMicro 返回当前微秒级时间戳
 func TimestampMicro(timezone ...string) int64 {
-	t := time.Now()
+	t := Now()
 
 	if timezone != nil && timezone[0] != "" {
 		loc, err := time.LoadLocation(timezone[0])
@@ -353,7 +353,7 @@ func TimestampMicro(timezone ...string) int64 {
 
 // TimestampNano 返回当前纳秒级时间戳
 func TimestampNano(timezone ...string) int64 {
-	t := time.Now()
+	t := Now()
 
 	if timezone != nil && timezone[0] != "" {
 		loc, err := time.LoadLocation(timezone[0])
@@ -370,7 +370,7 @@ func TimestampNano(timezone ...string) int64 {
 func TrackFuncTime(pre time.Time) func() {
 	callerName := getCallerName()
 	return func() {
-		elapsed := time.Since(pre)
+		elapsed := Now().Sub(pre)
 		fmt.Printf("Function %s execution time:\t %v", callerName, elapsed)
 	}
 }
@@ -0,0 +1,390 @@
+package dateutil
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxCalendarScanDays 查找工作日时最多向前/向后扫描的天数,防止日历无工作日时死循环
+const maxCalendarScanDays = 3660
+
+// Calendar 工作日历,支持自定义周末、节假日、调休上班日和工作时间段
+type Calendar struct {
+	mu        sync.RWMutex
+	weekends  map[time.Weekday]bool
+	holidays  map[string]string   // 日期 -> 节日名称
+	workdays  map[string]struct{} // 调休上班日
+	table     *HolidayTable       // 可选的节假日表,优先级低于手动设置的日期
+	workStart time.Duration       // 每日上班时间(距零点)
+	workEnd   time.Duration       // 每日下班时间(距零点)
+}
+
+// CalendarOption 工作日历配置选项
+type CalendarOption func(*Calendar)
+
+// CalendarConfig 工作日历的JSON配置
+type CalendarConfig struct {
+	Weekends  []string `json:"weekends"`   // 周末,如 ["Saturday", "Sunday"]
+	Holidays  []string `json:"holidays"`   // 节假日,yyyy-mm-dd
+	Workdays  []string `json:"workdays"`   // 调休上班日,yyyy-mm-dd
+	WorkStart string   `json:"work_start"` // 上班时间,hh:mm
+	WorkEnd   string   `json:"work_end"`   // 下班时间,hh:mm
+}
+
+// NewCalendar 创建工作日历,默认周六、周日休息,工作时间 09:00-18:00
+func NewCalendar(opts ...CalendarOption) *Calendar {
+	c := &Calendar{
+		weekends:  map[time.Weekday]bool{time.Saturday: true, time.Sunday: true},
+		holidays:  make(map[string]string),
+		workdays:  make(map[string]struct{}),
+		workStart: 9 * time.Hour,
+		workEnd:   18 * time.Hour,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// WithWeekends 设置周末,覆盖默认的周六、周日
+func WithWeekends(days ...time.Weekday) CalendarOption {
+	return func(c *Calendar) {
+		c.weekends = make(map[time.Weekday]bool, len(days))
+		for _, d := range days {
+			c.weekends[d] = true
+		}
+	}
+}
+
+// WithHolidays 添加节假日,同一日期以最后的设置为准
+func WithHolidays(dates ...time.Time) CalendarOption {
+	return func(c *Calendar) {
+		for _, d := range dates {
+			key := d.Format(dateLayout)
+			delete(c.workdays, key)
+			c.holidays[key] = ""
+		}
+	}
+}
+
+// WithMakeupWorkdays 添加调休上班日,同一日期以最后的设置为准
+func WithMakeupWorkdays(dates ...time.Time) CalendarOption {
+	return func(c *Calendar) {
+		for _, d := range dates {
+			key := d.Format(dateLayout)
+			delete(c.holidays, key)
+			c.workdays[key] = struct{}{}
+		}
+	}
+}
+
+// WithHolidayTable 使用节假日表,如 DefaultHolidayTable() 提供的中国法定节假日
+func WithHolidayTable(table *HolidayTable) CalendarOption {
+	return func(c *Calendar) {
+		c.table = table
+	}
+}
+
+// WithWorkingHours 设置每日工作时间段,参数为距零点的时长,如 9*time.Hour 和 18*time.Hour
+func WithWorkingHours(start, end time.Duration) CalendarOption {
+	return func(c *Calendar) {
+		if start >= 0 && end > start && end <= 24*time.Hour {
+			c.workStart = start
+			c.workEnd = end
+		}
+	}
+}
+
+// LoadCalendarJSON 从JSON加载工作日历,opts 在JSON配置之前应用
+func LoadCalendarJSON(r io.Reader, opts ...CalendarOption) (*Calendar, error) {
+	var cfg CalendarConfig
+	if err := json.NewDecoder(r).Decode(&cfg); err != nil {
+		return nil, fmt.Errorf("calendar: decode failed: %w", err)
+	}
+
+	c := NewCalendar(opts...)
+	if len(cfg.Weekends) > 0 {
+		days := make([]time.Weekday, 0, len(cfg.Weekends))
+		for _, name := range cfg.Weekends {
+			d, err := parseWeekday(name)
+			if err != nil {
+				return nil, err
+			}
+			days = append(days, d)
+		}
+		WithWeekends(days...)(c)
+	}
+	for _, s := range cfg.Holidays {
+		if err := c.addDate(s, "", false); err != nil {
+			return nil, err
+		}
+	}
+	for _, s := range cfg.Workdays {
+		if err := c.addDate(s, "", true); err != nil {
+			return nil, err
+		}
+	}
+
+	if cfg.WorkStart != "" || cfg.WorkEnd != "" {
+		start, err := parseClock(cfg.WorkStart)
+		if err != nil {
+			return nil, err
+		}
+		end, err := parseClock(cfg.WorkEnd)
+		if err != nil {
+			return nil, err
+		}
+		if end <= start {
+			return nil, errors.New("calendar: work_end must be after work_start")
+		}
+		WithWorkingHours(start, end)(c)
+	}
+	return c, nil
+}
+
+// LoadCalendarCSV 从CSV加载节假日和调休上班日,每行格式为 日期,类型[,名称],
+// 类型为 holiday 或 workday,首行为表头时自动跳过
+func LoadCalendarCSV(r io.Reader, opts ...CalendarOption) (*Calendar, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	c := NewCalendar(opts...)
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("calendar: read csv failed: %w", err)
+		}
+		if len(record) < 2 {
+			return nil, fmt.Errorf("calendar: line %d: expected date,type[,name]", line)
+		}
+		if line == 1 && strings.EqualFold(record[0], "date") {
+			continue
+		}
+
+		name := ""
+		if len(record) > 2 {
+			name = record[2]
+		}
+		switch strings.ToLower(strings.TrimSpace(record[1])) {
+		case "holiday":
+			err = c.addDate(record[0], name, false)
+		case "workday":
+			err = c.addDate(record[0], name, true)
+		default:
+			err = fmt.Errorf("calendar: line %d: unknown type %q", line, record[1])
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// AddHoliday 添加节假日
+func (c *Calendar) AddHoliday(t time.Time, name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key := t.Format(dateLayout)
+	delete(c.workdays, key)
+	c.holidays[key] = name
+}
+
+// AddMakeupWorkday 添加调休上班日
+func (c *Calendar) AddMakeupWorkday(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key := t.Format(dateLayout)
+	delete(c.holidays, key)
+	c.workdays[key] = struct{}{}
+}
+
+// IsWorkday 判断日期是否为工作日,优先级: 手动设置 > 节假日表 > 周末
+func (c *Calendar) IsWorkday(t time.Time) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.isWorkday(t)
+}
+
+// NextWorkday 返回t之后的下一个工作日,保留时分秒,找不到时返回零值
+func (c *Calendar) NextWorkday(t time.Time) time.Time {
+	return c.AddWorkdays(t, 1)
+}
+
+// PrevWorkday 返回t之前的上一个工作日,保留时分秒,找不到时返回零值
+func (c *Calendar) PrevWorkday(t time.Time) time.Time {
+	return c.AddWorkdays(t, -1)
+}
+
+// AddWorkdays 加/减n个工作日(不计t当天),保留时分秒,找不到时返回零值
+func (c *Calendar) AddWorkdays(t time.Time, n int) time.Time {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	step := 1
+	if n < 0 {
+		step, n = -1, -n
+	}
+
+	cur := t
+	for scanned := 0; n > 0; scanned++ {
+		if scanned > maxCalendarScanDays {
+			return time.Time{}
+		}
+		cur = cur.AddDate(0, 0, step)
+		if c.isWorkday(cur) {
+			n--
+			scanned = 0
+		}
+	}
+	return cur
+}
+
+// WorkdaysBetween 返回 [start, end) 日期范围内的工作日数,end 早于 start 时返回负数
+func (c *Calendar) WorkdaysBetween(start, end time.Time) int {
+	sign := 1
+	if end.Before(start) {
+		start, end = end, start
+		sign = -1
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	count := 0
+	last := BeginOfDay(end)
+	for d := BeginOfDay(start); d.Before(last); d = d.AddDate(0, 0, 1) {
+		if c.isWorkday(d) {
+			count++
+		}
+	}
+	return sign * count
+}
+
+// WorkingDuration 返回 [start, end) 内落在工作日工作时间段的总时长,可用于SLA计时
+func (c *Calendar) WorkingDuration(start, end time.Time) time.Duration {
+	if !end.After(start) {
+		return 0
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var total time.Duration
+	for d := BeginOfDay(start); d.Before(end); d = d.AddDate(0, 0, 1) {
+		if !c.isWorkday(d) {
+			continue
+		}
+		from, to := atOffset(d, c.workStart), atOffset(d, c.workEnd)
+		if start.After(from) {
+			from = start
+		}
+		if end.Before(to) {
+			to = end
+		}
+		if to.After(from) {
+			total += to.Sub(from)
+		}
+	}
+	return total
+}
+
+// AddWorkingDuration 从t开始累计d的工作时长,返回到期时间,找不到时返回零值
+func (c *Calendar) AddWorkingDuration(t time.Time, d time.Duration) time.Time {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	cur := t
+	for scanned := 0; scanned <= maxCalendarScanDays; scanned++ {
+		day := BeginOfDay(cur)
+		if c.isWorkday(day) {
+			from, to := atOffset(day, c.workStart), atOffset(day, c.workEnd)
+			if cur.Before(from) {
+				cur = from
+			}
+			if cur.Before(to) {
+				avail := to.Sub(cur)
+				if d <= avail {
+					return cur.Add(d)
+				}
+				d -= avail
+				scanned = 0
+			}
+		}
+		cur = day.AddDate(0, 0, 1)
+	}
+	return time.Time{}
+}
+
+// atOffset 返回 day 当天距零点 offset 的钟面时间,夏令时切换日不受当天实际时长影响
+func atOffset(day time.Time, offset time.Duration) time.Time {
+	y, m, d := day.Date()
+	return time.Date(y, m, d, int(offset/time.Hour), int(offset%time.Hour/time.Minute),
+		int(offset%time.Minute/time.Second), int(offset%time.Second), day.Location())
+}
+
+// isWorkday 判断是否为工作日,调用方需持有读锁
+func (c *Calendar) isWorkday(t time.Time) bool {
+	key := t.Format(dateLayout)
+	if _, ok := c.workdays[key]; ok {
+		return true
+	}
+	if _, ok := c.holidays[key]; ok {
+		return false
+	}
+	if c.table != nil {
+		if info, ok := c.table.Lookup(t); ok {
+			switch info.Kind {
+			case StatutoryHoliday:
+				return false
+			case AdjustedWorkday:
+				return true
+			}
+		}
+	}
+	return !c.weekends[t.Weekday()]
+}
+
+// addDate 解析并添加节假日或调休上班日
+func (c *Calendar) addDate(s, name string, workday bool) error {
+	d, err := time.Parse(dateLayout, strings.TrimSpace(s))
+	if err != nil {
+		return fmt.Errorf("calendar: invalid date %q: %w", s, err)
+	}
+	if workday {
+		c.AddMakeupWorkday(d)
+	} else {
+		c.AddHoliday(d, name)
+	}
+	return nil
+}
+
+// parseWeekday 解析星期名称,支持英文全称/缩写和数字(0为周日)
+func parseWeekday(s string) (time.Weekday, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		name := strings.ToLower(d.String())
+		if s == name || s == name[:3] || s == fmt.Sprint(int(d)) {
+			return d, nil
+		}
+	}
+	return 0, fmt.Errorf("calendar: invalid weekday %q", s)
+}
+
+// parseClock 解析 hh:mm 格式的时间为距零点的时长
+func parseClock(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		return 0, fmt.Errorf("calendar: invalid time %q: %w", s, err)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
@@ -0,0 +1,105 @@
+package dateutil
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCalendarWorkdays(t *testing.T) {
+	day := func(s string) time.Time {
+		d, _ := time.ParseInLocation(dateLayout, s, time.Local)
+		return d
+	}
+
+	cal := NewCalendar(WithHolidayTable(DefaultHolidayTable()))
+	assert.False(t, cal.IsWorkday(day("2024-10-01")))
+	assert.True(t, cal.IsWorkday(day("2024-09-29"))) // 周日调休上班
+	assert.False(t, cal.IsWorkday(day("2024-09-28")))
+
+	// 国庆假期前一天的下一个工作日
+	assert.Equal(t, "2024-10-08", cal.NextWorkday(day("2024-09-30")).Format(dateLayout))
+	assert.Equal(t, "2024-09-30", cal.PrevWorkday(day("2024-10-08")).Format(dateLayout))
+	assert.Equal(t, "2024-10-10", cal.AddWorkdays(day("2024-09-29"), 4).Format(dateLayout))
+	assert.Equal(t, 5, cal.WorkdaysBetween(day("2024-09-27"), day("2024-10-10")))
+	assert.Equal(t, -5, cal.WorkdaysBetween(day("2024-10-10"), day("2024-09-27")))
+
+	// 手动设置优先于节假日表
+	cal.AddMakeupWorkday(day("2024-10-07"))
+	assert.True(t, cal.IsWorkday(day("2024-10-07")))
+
+	// 同一日期的选项以最后的设置为准
+	wed := day("2024-03-06")
+	assert.False(t, NewCalendar(WithMakeupWorkdays(wed), WithHolidays(wed)).IsWorkday(wed))
+	assert.True(t, NewCalendar(WithHolidays(wed), WithMakeupWorkdays(wed)).IsWorkday(wed))
+
+	// 全周休息时找不到工作日
+	none := NewCalendar(WithWeekends(time.Sunday, time.Monday, time.Tuesday, time.Wednesday,
+		time.Thursday, time.Friday, time.Saturday))
+	assert.True(t, none.NextWorkday(day("2024-01-01")).IsZero())
+}
+
+func TestCalendarWorkingDuration(t *testing.T) {
+	at := func(s string) time.Time {
+		d, _ := time.ParseInLocation("2006-01-02 15:04", s, time.Local)
+		return d
+	}
+
+	cal := NewCalendar()
+	// 周五 17:00 到下周一 10:00,只计 1 + 1 小时
+	assert.Equal(t, 2*time.Hour, cal.WorkingDuration(at("2024-03-01 17:00"), at("2024-03-04 10:00")))
+	assert.Equal(t, 9*time.Hour, cal.WorkingDuration(at("2024-03-04 00:00"), at("2024-03-05 00:00")))
+	assert.Equal(t, time.Duration(0), cal.WorkingDuration(at("2024-03-02 08:00"), at("2024-03-02 20:00")))
+
+	// SLA 8 小时,周五 15:00 开始,下周一 14:00 到期
+	assert.Equal(t, at("2024-03-04 14:00"), cal.AddWorkingDuration(at("2024-03-01 15:00"), 8*time.Hour))
+	assert.Equal(t, at("2024-03-04 09:30"), cal.AddWorkingDuration(at("2024-03-02 11:00"), 30*time.Minute))
+
+	// 夏令时切换日按钟面时间 09:00-18:00 计算
+	ny, err := time.LoadLocation("America/New_York")
+	assert.NoError(t, err)
+	nyAt := func(s string) time.Time {
+		d, _ := time.ParseInLocation("2006-01-02 15:04", s, ny)
+		return d
+	}
+	dst := NewCalendar(WithMakeupWorkdays(nyAt("2024-03-10 00:00"), nyAt("2024-11-03 00:00")))
+	assert.Equal(t, time.Hour, dst.WorkingDuration(nyAt("2024-03-10 09:00"), nyAt("2024-03-10 10:00")))
+	assert.Equal(t, 9*time.Hour, dst.WorkingDuration(nyAt("2024-03-10 00:00"), nyAt("2024-03-11 00:00")))
+	assert.Equal(t, time.Hour, dst.WorkingDuration(nyAt("2024-11-03 17:00"), nyAt("2024-11-03 19:00")))
+	assert.Equal(t, nyAt("2024-03-10 10:00"), dst.AddWorkingDuration(nyAt("2024-03-10 07:00"), time.Hour))
+	assert.Equal(t, nyAt("2024-11-03 09:30"), dst.AddWorkingDuration(nyAt("2024-11-03 00:30"), 30*time.Minute))
+	assert.Equal(t, nyAt("2024-11-04 09:30"), dst.AddWorkingDuration(nyAt("2024-11-03 17:00"), 90*time.Minute))
+}
+
+func TestLoadCalendar(t *testing.T) {
+	day := func(s string) time.Time {
+		d, _ := time.ParseInLocation(dateLayout, s, time.Local)
+		return d
+	}
+
+	cal, err := LoadCalendarJSON(strings.NewReader(`{
+		"weekends": ["Fri", "saturday"],
+		"holidays": ["2024-03-04"],
+		"workdays": ["2024-03-08"],
+		"work_start": "08:00",
+		"work_end": "16:30"
+	}`))
+	assert.NoError(t, err)
+	assert.True(t, cal.IsWorkday(day("2024-03-03"))) // 周日
+	assert.False(t, cal.IsWorkday(day("2024-03-04")))
+	assert.True(t, cal.IsWorkday(day("2024-03-08")))
+	assert.False(t, cal.IsWorkday(day("2024-03-09")))
+	assert.Equal(t, 8*time.Hour+30*time.Minute, cal.WorkingDuration(day("2024-03-05"), day("2024-03-06")))
+
+	cal, err = LoadCalendarCSV(strings.NewReader("date,type,name\n2024-03-04,holiday,年假\n2024-03-09,workday\n"))
+	assert.NoError(t, err)
+	assert.False(t, cal.IsWorkday(day("2024-03-04")))
+	assert.True(t, cal.IsWorkday(day("2024-03-09")))
+
+	_, err = LoadCalendarCSV(strings.NewReader("2024-03-04,vacation\n"))
+	assert.Error(t, err)
+	_, err = LoadCalendarJSON(strings.NewReader(`{"work_start":"18:00","work_end":"09:00"}`))
+	assert.Error(t, err)
+}
@@ -3,10 +3,16 @@ package dbutils
 import (
 	"bytes"
 	"encoding/gob"
+	"errors"
 	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/wind959/ko-utils/clock"
 	"github.com/wind959/ko-utils/dbutils/boltutil"
 	"go.etcd.io/bbolt"
 	"log"
+	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 )
@@ -109,3 +115,568 @@ func TestBolt(t *testing.T) {
 		log.Printf("Error iterating bucket: %v", err)
 	}
 }
+
+func TestBoltStore(t *testing.T) {
+	dir := t.TempDir()
+
+	// 同时打开两个数据库
+	a, err := boltutil.Open(filepath.Join(dir, "a.db"), boltutil.WithTimeout(time.Second))
+	assert.NoError(t, err)
+	b, err := boltutil.Open(filepath.Join(dir, "b.db"), boltutil.WithCodec(boltutil.JSONCodec))
+	assert.NoError(t, err)
+	defer b.Close()
+
+	users, err := boltutil.NewBucket[string, User](a, "Users", boltutil.StringKeys)
+	assert.NoError(t, err)
+	assert.NoError(t, users.Put("alice", User{Name: "Alice", Age: 30}))
+	assert.NoError(t, users.Put("bob", User{Name: "Bob", Age: 25}))
+
+	u, err := users.Get("alice")
+	assert.NoError(t, err)
+	assert.Equal(t, "Alice", u.Name)
+	_, err = users.Get("carol")
+	assert.ErrorIs(t, err, boltutil.ErrKeyNotFound)
+
+	var names []string
+	assert.NoError(t, users.ForEach(func(k string, v User) error {
+		names = append(names, k+":"+v.Name)
+		return nil
+	}))
+	assert.Equal(t, []string{"alice:Alice", "bob:Bob"}, names)
+
+	assert.NoError(t, users.Delete("bob"))
+	// 嵌套存储桶及其中的键不计入数量
+	assert.NoError(t, a.CreateBucket(boltutil.Path("Users", "archive")))
+	assert.NoError(t, a.Put(boltutil.Path("Users", "archive"), []byte("bob"), User{Name: "Bob"}))
+	n, err := users.Count()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	// JSON 编解码的数据可以直接读取
+	orders, err := boltutil.NewBucket[string, Order](b, "Orders", boltutil.StringKeys)
+	assert.NoError(t, err)
+	assert.NoError(t, orders.Put("book", Order{ItemName: "新三国", Price: 19.99, Unit: 5}))
+	assert.NoError(t, b.ForEach([]byte("Orders"), func(k, v []byte) error {
+		assert.JSONEq(t, `{"ItemName":"新三国","Price":19.99,"Unit":5}`, string(v))
+		return nil
+	}))
+	assert.ErrorIs(t, b.Put([]byte("Missing"), []byte("k"), 1), boltutil.ErrBucketNotFound)
+
+	// 关闭后可以重新打开
+	assert.NoError(t, a.Close())
+	a, err = boltutil.Open(filepath.Join(dir, "a.db"))
+	assert.NoError(t, err)
+	users, err = boltutil.NewBucket[string, User](a, "Users", boltutil.StringKeys)
+	assert.NoError(t, err)
+	u, err = users.Get("alice")
+	assert.NoError(t, err)
+	assert.Equal(t, 30, u.Age)
+	assert.NoError(t, a.Close())
+
+	// 默认实例同样支持关闭后重新打开
+	cfg := boltutil.BoltConfig{Path: filepath.Join(dir, "default.db")}
+	for i := 0; i < 2; i++ {
+		_, err = boltutil.GetDBInstance(cfg)
+		assert.NoError(t, err)
+		assert.NoError(t, boltutil.CreateBucket([]byte("Users")))
+		assert.NoError(t, boltutil.Put([]byte("Users"), []byte("alice"), User{Name: "Alice"}))
+		assert.NoError(t, boltutil.Close())
+	}
+	assert.ErrorIs(t, boltutil.Close(), boltutil.ErrDatabaseNotOpen)
+	assert.ErrorIs(t, boltutil.Put([]byte("Users"), []byte("alice"), User{}), boltutil.ErrDatabaseNotOpen)
+}
+
+func TestBoltScan(t *testing.T) {
+	s, err := boltutil.Open(filepath.Join(t.TempDir(), "scan.db"))
+	assert.NoError(t, err)
+	defer s.Close()
+
+	users, err := boltutil.NewBucket[string, User](s, "Users", boltutil.StringKeys)
+	assert.NoError(t, err)
+	for _, k := range []string{"a:1", "a:2", "a:3", "b:1", "b:2", "c:1"} {
+		assert.NoError(t, users.Put(k, User{Name: k}))
+	}
+	collect := func(keys *[]string) func(string, User) error {
+		return func(k string, _ User) error {
+			*keys = append(*keys, k)
+			return nil
+		}
+	}
+
+	var keys []string
+	assert.NoError(t, users.Prefix("a:", collect(&keys)))
+	assert.Equal(t, []string{"a:1", "a:2", "a:3"}, keys)
+
+	keys = nil
+	assert.NoError(t, users.Prefix("b:", collect(&keys), boltutil.WithReverse()))
+	assert.Equal(t, []string{"b:2", "b:1"}, keys)
+
+	keys = nil
+	assert.NoError(t, users.Range("a:2", "b:2", collect(&keys)))
+	assert.Equal(t, []string{"a:2", "a:3", "b:1"}, keys)
+
+	keys = nil
+	assert.NoError(t, users.Scan(collect(&keys), boltutil.WithReverse(), boltutil.WithLimit(2)))
+	assert.Equal(t, []string{"c:1", "b:2"}, keys)
+
+	// 分页
+	for _, reverse := range []bool{false, true} {
+		var opts []boltutil.ScanOption
+		if reverse {
+			opts = append(opts, boltutil.WithReverse())
+		}
+		var pages [][]string
+		token := ""
+		for {
+			page, err := users.List(4, token, opts...)
+			assert.NoError(t, err)
+			var ks []string
+			for _, item := range page.Items {
+				ks = append(ks, item.Key)
+			}
+			pages = append(pages, ks)
+			if token = page.NextToken; token == "" {
+				break
+			}
+		}
+		if reverse {
+			assert.Equal(t, [][]string{{"c:1", "b:2", "b:1", "a:3"}, {"a:2", "a:1"}}, pages)
+		} else {
+			assert.Equal(t, [][]string{{"a:1", "a:2", "a:3", "b:1"}, {"b:2", "c:1"}}, pages)
+		}
+	}
+	page, err := users.List(2, "", boltutil.WithPrefix([]byte("a:")))
+	assert.NoError(t, err)
+	page, err = users.List(2, page.NextToken, boltutil.WithPrefix([]byte("a:")))
+	assert.NoError(t, err)
+	assert.Len(t, page.Items, 1)
+	assert.Empty(t, page.NextToken)
+	_, err = users.List(2, page.NextToken+"!", boltutil.WithReverse())
+	assert.ErrorIs(t, err, boltutil.ErrInvalidToken)
+
+	// 有序整数与时间键
+	nums, err := boltutil.NewBucket[int64, string](s, "Nums", boltutil.Int64Keys)
+	assert.NoError(t, err)
+	for _, n := range []int64{5, -3, 100, 0, -200} {
+		assert.NoError(t, nums.Put(n, fmt.Sprint(n)))
+	}
+	var ns []int64
+	assert.NoError(t, nums.ForEach(func(k int64, _ string) error {
+		ns = append(ns, k)
+		return nil
+	}))
+	assert.Equal(t, []int64{-200, -3, 0, 5, 100}, ns)
+
+	events, err := boltutil.NewBucket[time.Time, string](s, "Events", boltutil.TimeKeys)
+	assert.NoError(t, err)
+	base := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 10; i++ {
+		assert.NoError(t, events.Put(base.Add(time.Duration(i)*time.Hour), fmt.Sprint(i)))
+	}
+	var got []string
+	assert.NoError(t, events.Range(base.Add(3*time.Hour), base.Add(6*time.Hour), func(k time.Time, v string) error {
+		got = append(got, v)
+		return nil
+	}))
+	assert.Equal(t, []string{"3", "4", "5"}, got)
+
+	_, err = boltutil.Int64Keys.DecodeKey([]byte{1, 2})
+	assert.ErrorIs(t, err, boltutil.ErrInvalidKey)
+}
+
+func TestBoltTTL(t *testing.T) {
+	fake := clock.NewFake(time.Time{})
+	s, err := boltutil.Open(filepath.Join(t.TempDir(), "ttl.db"),
+		boltutil.WithClock(fake), boltutil.WithTTLSweeper(time.Minute, 2))
+	assert.NoError(t, err)
+	defer s.Close()
+
+	sessions, err := boltutil.NewBucket[string, string](s, "Sessions", boltutil.StringKeys)
+	assert.NoError(t, err)
+	for i := 0; i < 5; i++ {
+		assert.NoError(t, sessions.PutWithTTL(fmt.Sprint("s", i), "token", 30*time.Second))
+	}
+	assert.NoError(t, sessions.PutWithTTL("keep", "token", time.Hour))
+	assert.NoError(t, sessions.Put("forever", "token"))
+
+	at, ok, err := sessions.ExpiresAt("keep")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.True(t, fake.Now().Add(time.Hour).Equal(at))
+	_, ok, err = sessions.ExpiresAt("forever")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	// 过期键立即视为不存在,但在清理前仍占用空间
+	fake.Advance(30 * time.Second)
+	_, err = sessions.Get("s0")
+	assert.ErrorIs(t, err, boltutil.ErrKeyNotFound)
+	has, err := sessions.Has("s1")
+	assert.NoError(t, err)
+	assert.False(t, has)
+	var keys []string
+	assert.NoError(t, sessions.ForEach(func(k, _ string) error {
+		keys = append(keys, k)
+		return nil
+	}))
+	assert.Equal(t, []string{"forever", "keep"}, keys)
+	expiry := map[string]time.Time{}
+	assert.NoError(t, s.ScanWithTTL([]byte("Sessions"), func(k, _ []byte, at time.Time, ok bool) error {
+		assert.Equal(t, string(k) == "keep", ok, string(k))
+		expiry[string(k)] = at
+		return nil
+	}))
+	assert.Len(t, expiry, 2)
+	assert.True(t, fake.Now().Add(time.Hour-30*time.Second).Equal(expiry["keep"]))
+	n, _ := sessions.Count()
+	assert.Equal(t, 2, n)
+	assert.Equal(t, 7, rawKeyCount(t, s, "Sessions"))
+
+	// 重新写入会清除过期时间
+	assert.NoError(t, sessions.Put("s4", "again"))
+	v, err := sessions.Get("s4")
+	assert.NoError(t, err)
+	assert.Equal(t, "again", v)
+
+	// 后台清理分批删除
+	fake.Advance(30 * time.Second)
+	assert.Eventually(t, func() bool {
+		return rawKeyCount(t, s, "Sessions") == 3
+	}, time.Second, time.Millisecond)
+
+	assert.NoError(t, sessions.Expire("keep", 0))
+	fake.Advance(2 * time.Hour)
+	removed, err := s.SweepExpired(10)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, removed)
+	_, err = sessions.Get("keep")
+	assert.NoError(t, err)
+	assert.ErrorIs(t, sessions.Expire("missing", time.Minute), boltutil.ErrKeyNotFound)
+}
+
+func TestBoltIndex(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "index.db")
+	s, err := boltutil.Open(path)
+	assert.NoError(t, err)
+
+	type Account struct {
+		Email  string
+		UserID int
+		Tags   []string
+	}
+	accounts, err := boltutil.NewBucket[string, Account](s, "Accounts", boltutil.StringKeys)
+	assert.NoError(t, err)
+	assert.NoError(t, accounts.Put("a1", Account{Email: "x@example.com", UserID: 1}))
+
+	// 注册时根据已有数据构建索引
+	assert.NoError(t, accounts.AddIndex(boltutil.Index[Account]{
+		Name:   "email",
+		Unique: true,
+		Extract: func(a Account) []string {
+			return []string{a.Email}
+		},
+	}))
+	assert.NoError(t, accounts.AddIndex(boltutil.Index[Account]{
+		Name: "user",
+		Extract: func(a Account) []string {
+			return []string{fmt.Sprint(a.UserID)}
+		},
+	}))
+	assert.NoError(t, accounts.AddIndex(boltutil.Index[Account]{
+		Name: "tag",
+		Extract: func(a Account) []string {
+			return a.Tags
+		},
+	}))
+	assert.Error(t, accounts.AddIndex(boltutil.Index[Account]{Name: "tag", Extract: func(Account) []string { return nil }}))
+
+	assert.NoError(t, accounts.Put("a2", Account{Email: "y@example.com", UserID: 1, Tags: []string{"vip", "cn"}}))
+	assert.NoError(t, accounts.Put("a3", Account{Email: "z@example.com", UserID: 2, Tags: []string{"vip"}}))
+
+	key, acc, err := accounts.FindOne("email", "x@example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, "a1", key)
+	assert.Equal(t, 1, acc.UserID)
+
+	items, err := accounts.FindBy("user", "1")
+	assert.NoError(t, err)
+	assert.Len(t, items, 2)
+	items, err = accounts.FindBy("tag", "vip")
+	assert.NoError(t, err)
+	assert.Equal(t, "a2", items[0].Key)
+	assert.Equal(t, "a3", items[1].Key)
+
+	// 唯一约束,失败时整个写入回滚
+	err = accounts.Put("a4", Account{Email: "x@example.com"})
+	assert.ErrorIs(t, err, boltutil.ErrUniqueViolation)
+	has, _ := accounts.Has("a4")
+	assert.False(t, has)
+
+	// 更新和删除会同步维护索引
+	assert.NoError(t, accounts.Put("a1", Account{Email: "x2@example.com", UserID: 2}))
+	_, _, err = accounts.FindOne("email", "x@example.com")
+	assert.ErrorIs(t, err, boltutil.ErrKeyNotFound)
+	assert.NoError(t, accounts.Put("a4", Account{Email: "x@example.com"}))
+	assert.NoError(t, accounts.Delete("a3"))
+	items, _ = accounts.FindBy("user", "2")
+	assert.Len(t, items, 1)
+
+	_, err = accounts.FindBy("missing", "1")
+	assert.ErrorIs(t, err, boltutil.ErrIndexNotFound)
+
+	// Store、Tx 和其他句柄的写入同样维护索引
+	assert.NoError(t, s.Put([]byte("Accounts"), []byte("a5"), Account{Email: "w@example.com", UserID: 9}))
+	items, _ = accounts.FindBy("user", "9")
+	assert.Len(t, items, 1)
+	assert.ErrorIs(t, s.Put([]byte("Accounts"), []byte("a6"), Account{Email: "w@example.com"}), boltutil.ErrUniqueViolation)
+	assert.NoError(t, s.Update(func(tx *boltutil.Tx) error {
+		return tx.Put([]byte("Accounts"), []byte("a5"), Account{Email: "w@example.com", UserID: 8})
+	}))
+	items, _ = accounts.FindBy("user", "9")
+	assert.Empty(t, items)
+	other, err := boltutil.NewBucket[string, Account](s, "Accounts", boltutil.StringKeys)
+	assert.NoError(t, err)
+	assert.NoError(t, other.Put("a7", Account{Email: "v@example.com", UserID: 8}))
+	items, _ = accounts.FindBy("user", "8")
+	assert.Len(t, items, 2)
+	assert.NoError(t, s.Delete([]byte("Accounts"), []byte("a5")))
+	_, _, err = accounts.FindOne("email", "w@example.com")
+	assert.ErrorIs(t, err, boltutil.ErrKeyNotFound)
+	assert.NoError(t, accounts.RebuildIndex("user"))
+	items, _ = accounts.FindBy("user", "8")
+	assert.Len(t, items, 1)
+	assert.NoError(t, s.Close())
+
+	// 重新打开后未注册索引期间的写入会丢弃索引数据,重新注册时重建
+	s, err = boltutil.Open(path)
+	assert.NoError(t, err)
+	defer s.Close()
+	assert.NoError(t, s.Put([]byte("Accounts"), []byte("a8"), Account{Email: "u@example.com"}))
+	accounts, err = boltutil.NewBucket[string, Account](s, "Accounts", boltutil.StringKeys)
+	assert.NoError(t, err)
+	assert.NoError(t, accounts.AddIndex(boltutil.Index[Account]{
+		Name:   "email",
+		Unique: true,
+		Extract: func(a Account) []string {
+			return []string{a.Email}
+		},
+	}))
+	key, _, err = accounts.FindOne("email", "u@example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, "a8", key)
+	key, _, err = accounts.FindOne("email", "x@example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, "a4", key)
+}
+
+func TestBoltTx(t *testing.T) {
+	s, err := boltutil.Open(filepath.Join(t.TempDir(), "tx.db"))
+	assert.NoError(t, err)
+	defer s.Close()
+
+	// 嵌套存储桶路径
+	orders, err := boltutil.NewBucket[string, Order](s, string(boltutil.Path("tenant", "42", "orders")), boltutil.StringKeys)
+	assert.NoError(t, err)
+	users, err := boltutil.NewBucket[string, User](s, string(boltutil.Path("tenant", "42", "users")), boltutil.StringKeys)
+	assert.NoError(t, err)
+	assert.NoError(t, s.CreateBucket(boltutil.Path("tenant", "43")))
+	names, err := s.Buckets(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"tenant"}, names)
+	names, err = s.Buckets(boltutil.Path("tenant", "42"))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"orders", "users"}, names)
+	assert.Error(t, s.CreateBucket(boltutil.Path("tenant", "", "x")))
+
+	// 跨存储桶原子写入,失败时整体回滚
+	err = s.Update(func(tx *boltutil.Tx) error {
+		if err := users.In(tx).Put("alice", User{Name: "Alice"}); err != nil {
+			return err
+		}
+		if err := orders.In(tx).Put("o1", Order{ItemName: "book"}); err != nil {
+			return err
+		}
+		return errors.New("abort")
+	})
+	assert.EqualError(t, err, "abort")
+	has, _ := users.Has("alice")
+	assert.False(t, has)
+
+	assert.NoError(t, s.Update(func(tx *boltutil.Tx) error {
+		if err := users.In(tx).Put("alice", User{Name: "Alice"}); err != nil {
+			return err
+		}
+		return tx.Put(boltutil.Path("tenant", "42", "orders"), []byte("o1"), Order{ItemName: "book", Unit: 1})
+	}))
+	assert.NoError(t, s.View(func(tx *boltutil.Tx) error {
+		o, err := orders.In(tx).Get("o1")
+		assert.NoError(t, err)
+		assert.Equal(t, 1, o.Unit)
+		var u User
+		assert.NoError(t, tx.Get(boltutil.Path("tenant", "42", "users"), []byte("alice"), &u))
+		assert.Equal(t, "Alice", u.Name)
+		assert.False(t, tx.Writable())
+		return nil
+	}))
+
+	// 并发批量写入
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			assert.NoError(t, s.Batch(func(tx *boltutil.Tx) error {
+				return orders.In(tx).Put(fmt.Sprintf("b%02d", i), Order{Unit: i})
+			}))
+		}(i)
+	}
+	wg.Wait()
+	n, _ := orders.Count()
+	assert.Equal(t, 51, n)
+
+	// 删除父存储桶
+	assert.NoError(t, s.DeleteBucket(boltutil.Path("tenant", "42")))
+	_, err = orders.Get("o1")
+	assert.ErrorIs(t, err, boltutil.ErrBucketNotFound)
+	assert.ErrorIs(t, s.DeleteBucket(boltutil.Path("tenant", "42")), boltutil.ErrBucketNotFound)
+}
+
+func TestBoltFlatBucketNames(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "flat.db")
+
+	// 按基线代码的方式直接创建名称含 "/" 的顶层存储桶并写入 gob 数据
+	raw, err := bbolt.Open(path, 0600, nil)
+	assert.NoError(t, err)
+	var buf bytes.Buffer
+	assert.NoError(t, gob.NewEncoder(&buf).Encode(User{Name: "legacy"}))
+	assert.NoError(t, raw.Update(func(tx *bbolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists([]byte("users/2024"))
+		if err != nil {
+			return err
+		}
+		return b.Put([]byte("u1"), buf.Bytes())
+	}))
+	assert.NoError(t, raw.Close())
+
+	s, err := boltutil.Open(path)
+	assert.NoError(t, err)
+	defer s.Close()
+
+	var u User
+	assert.NoError(t, s.Get([]byte("users/2024"), []byte("u1"), &u))
+	assert.Equal(t, "legacy", u.Name)
+	assert.NoError(t, s.Put([]byte("users/2024"), []byte("u2"), User{Name: "new"}))
+
+	// 普通名称中的 "/" 和空段不表示嵌套
+	assert.NoError(t, s.CreateBucket([]byte("a/b")))
+	assert.NoError(t, s.CreateBucket([]byte("a//b/")))
+	names, err := s.Buckets(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a//b/", "a/b", "users/2024"}, names)
+	assert.NoError(t, s.DB().View(func(tx *bbolt.Tx) error {
+		assert.NotNil(t, tx.Bucket([]byte("a/b")))
+		assert.Nil(t, tx.Bucket([]byte("a")))
+		assert.NotNil(t, tx.Bucket([]byte("users/2024")).Get([]byte("u2")))
+		return nil
+	}))
+	assert.NoError(t, s.DeleteBucket([]byte("a/b")))
+	assert.ErrorIs(t, s.Get(boltutil.Path("users", "2024"), []byte("u1"), &u), boltutil.ErrBucketNotFound)
+
+	// 嵌套路径与同名的普通存储桶互不影响
+	assert.NoError(t, s.CreateBucket(boltutil.Path("users", "2024")))
+	assert.NoError(t, s.Put(boltutil.Path("users", "2024"), []byte("u1"), User{Name: "nested"}))
+	assert.NoError(t, s.Get([]byte("users/2024"), []byte("u1"), &u))
+	assert.Equal(t, "legacy", u.Name)
+	assert.Equal(t, []byte("users"), boltutil.Path("users"))
+}
+
+func TestBoltBackupAndExport(t *testing.T) {
+	dir := t.TempDir()
+	fake := clock.NewFake(time.Time{})
+	s, err := boltutil.Open(filepath.Join(dir, "src.db"), boltutil.WithCodec(boltutil.JSONCodec), boltutil.WithClock(fake))
+	assert.NoError(t, err)
+	defer s.Close()
+
+	users, err := boltutil.NewBucket[string, User](s, string(boltutil.Path("tenant", "1", "users")), boltutil.StringKeys)
+	assert.NoError(t, err)
+	nums, err := boltutil.NewBucket[int64, int](s, "nums", boltutil.Int64Keys)
+	assert.NoError(t, err)
+	assert.NoError(t, s.CreateBucket([]byte("empty")))
+	for i := 0; i < 500; i++ {
+		assert.NoError(t, users.Put(fmt.Sprint("u", i), User{Name: fmt.Sprint("user", i), Age: i}))
+	}
+	assert.NoError(t, nums.Put(-1, 1))
+	assert.NoError(t, users.PutWithTTL("session", User{Name: "tmp"}, time.Hour))
+	assert.NoError(t, users.PutWithTTL("gone", User{Name: "gone"}, time.Second))
+	fake.Advance(time.Second)
+
+	// 流式备份
+	var buf bytes.Buffer
+	var calls int
+	var last, total int64
+	n, err := s.BackupTo(&buf, func(written, size int64) {
+		calls++
+		last, total = written, size
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(buf.Len()), n)
+	assert.Positive(t, calls)
+	assert.Equal(t, total, last)
+
+	// 删除大量数据后压缩
+	for i := 0; i < 450; i++ {
+		assert.NoError(t, users.Delete(fmt.Sprint("u", i)))
+	}
+	dst := filepath.Join(dir, "compact.db")
+	res, err := s.Compact(dst)
+	assert.NoError(t, err)
+	assert.Positive(t, res.Reclaimed())
+	_, err = s.Compact(dst)
+	assert.Error(t, err)
+
+	// JSON Lines 导出导入
+	buf.Reset()
+	count, err := s.ExportJSONL(&buf)
+	assert.NoError(t, err)
+	assert.Equal(t, 52, count)
+	assert.Contains(t, buf.String(), `{"bucket":"tenant","path":["1","users"],"key":"u499","value":{"Name":"user499","Email":"","Age":499}}`)
+	assert.Contains(t, buf.String(), `{"bucket":"empty"}`)
+	assert.NotContains(t, buf.String(), "gone")
+
+	d, err := boltutil.Open(filepath.Join(dir, "dst.db"), boltutil.WithCodec(boltutil.JSONCodec), boltutil.WithClock(fake))
+	assert.NoError(t, err)
+	defer d.Close()
+	count, err = d.ImportJSONL(&buf)
+	assert.NoError(t, err)
+	assert.Equal(t, 52, count)
+
+	imported, err := boltutil.NewBucket[string, User](d, string(boltutil.Path("tenant", "1", "users")), boltutil.StringKeys)
+	assert.NoError(t, err)
+	u, err := imported.Get("u450")
+	assert.NoError(t, err)
+	assert.Equal(t, 450, u.Age)
+	at, ok, err := imported.ExpiresAt("session")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.True(t, fake.Now().Add(time.Hour-time.Second).Equal(at))
+	importedNums, err := boltutil.NewBucket[int64, int](d, "nums", boltutil.Int64Keys)
+	assert.NoError(t, err)
+	v, err := importedNums.Get(-1)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, v)
+	names, _ := d.Buckets(nil)
+	assert.Equal(t, []string{"empty", "nums", "tenant"}, names)
+
+	_, err = d.ImportJSONL(strings.NewReader("{\"bucket\":\"x\",\"key_b64\":\"!!\"}\n"))
+	assert.Error(t, err)
+}
+
+// rawKeyCount 返回存储桶中实际存储的键数量,包含尚未清理的过期键
+func rawKeyCount(t *testing.T, s *boltutil.Store, bucket string) int {
+	n := 0
+	assert.NoError(t, s.DB().View(func(tx *bbolt.Tx) error {
+		n = tx.Bucket([]byte(bucket)).Stats().KeyN
+		return nil
+	}))
+	return n
+}
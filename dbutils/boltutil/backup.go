@@ -0,0 +1,209 @@
+package boltutil
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+	"unicode/utf8"
+
+	"go.etcd.io/bbolt"
+)
+
+// CompactResult 压缩结果
+type CompactResult struct {
+	SrcSize int64 // 压缩前文件大小
+	DstSize int64 // 压缩后文件大小
+}
+
+// Reclaimed 返回回收的空间
+func (r CompactResult) Reclaimed() int64 {
+	return r.SrcSize - r.DstSize
+}
+
+// Record JSON Lines 导出的一行,Key 为空表示仅声明存储桶。Bucket 为顶层存储桶,嵌套存储桶的其余各段写入 Path。
+// 键为合法UTF-8时写入 Key,否则写入 KeyBase64;值在使用 JSONCodec 时写入 Value,否则写入 ValueBase64
+type Record struct {
+	Bucket      string          `json:"bucket"`
+	Path        []string        `json:"path,omitempty"`
+	Key         string          `json:"key,omitempty"`
+	KeyBase64   string          `json:"key_b64,omitempty"`
+	Value       json.RawMessage `json:"value,omitempty"`
+	ValueBase64 string          `json:"value_b64,omitempty"`
+	ExpiresAt   *time.Time      `json:"expires_at,omitempty"`
+}
+
+// BackupTo 在只读事务中将一致的数据库快照写入 w,不阻塞其他读写。
+// progress 不为 nil 时每次写入后回调已写入字节数和总字节数
+func (s *Store) BackupTo(w io.Writer, progress func(written, total int64)) (int64, error) {
+	var n int64
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		pw := &progressWriter{w: w, total: tx.Size(), progress: progress}
+		var err error
+		n, err = tx.WriteTo(pw)
+		return err
+	})
+	return n, err
+}
+
+// Compact 将数据库压缩复制到新文件 dst,dst 不能已存在;当前数据库不变,需要时由调用方替换文件
+func (s *Store) Compact(dst string) (CompactResult, error) {
+	var result CompactResult
+	if _, err := os.Stat(dst); err == nil {
+		return result, fmt.Errorf("boltutil: compact destination %s already exists", dst)
+	}
+	info, err := os.Stat(s.Path())
+	if err != nil {
+		return result, err
+	}
+	result.SrcSize = info.Size()
+
+	db, err := bbolt.Open(dst, 0600, nil)
+	if err != nil {
+		return result, err
+	}
+	if err := bbolt.Compact(db, s.db, 64<<20); err != nil {
+		db.Close()
+		return result, err
+	}
+	if err := db.Close(); err != nil {
+		return result, err
+	}
+	if info, err = os.Stat(dst); err != nil {
+		return result, err
+	}
+	result.DstSize = info.Size()
+	return result, nil
+}
+
+// ExportJSONL 将所有存储桶(含嵌套存储桶)导出为 JSON Lines,跳过已过期的键和内部索引,返回导出的键数量
+func (s *Store) ExportJSONL(w io.Writer) (int, error) {
+	n := 0
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		now := s.clock.Now()
+		return tx.ForEach(func(name []byte, b *bbolt.Bucket) error {
+			if isInternal(name) {
+				return nil
+			}
+			return walkBucket([][]byte{name}, b, func(path [][]byte, k, v []byte) error {
+				rec := Record{Bucket: string(path[0])}
+				for _, seg := range path[1:] {
+					rec.Path = append(rec.Path, string(seg))
+				}
+				if k == nil {
+					return enc.Encode(rec)
+				}
+				if at, ok := expiryOf(tx, encodePath(path), k); ok {
+					if !now.Before(at) {
+						return nil
+					}
+					rec.ExpiresAt = &at
+				}
+				if utf8.Valid(k) {
+					rec.Key = string(k)
+				} else {
+					rec.KeyBase64 = base64.StdEncoding.EncodeToString(k)
+				}
+				if s.codec == JSONCodec && json.Valid(v) {
+					rec.Value = json.RawMessage(v)
+				} else {
+					rec.ValueBase64 = base64.StdEncoding.EncodeToString(v)
+				}
+				n++
+				return enc.Encode(rec)
+			})
+		})
+	})
+	if err != nil {
+		return n, err
+	}
+	return n, bw.Flush()
+}
+
+// ImportJSONL 在一个事务中导入 ExportJSONL 的输出,已存在的键会被覆盖,导入时已过期的键被跳过。
+// 已注册的二级索引同步维护,返回导入的键数量
+func (s *Store) ImportJSONL(r io.Reader) (int, error) {
+	n := 0
+	dec := json.NewDecoder(r)
+	err := s.db.Update(func(tx *bbolt.Tx) error {
+		now := s.clock.Now()
+		for line := 1; ; line++ {
+			var rec Record
+			if err := dec.Decode(&rec); errors.Is(err, io.EOF) {
+				return nil
+			} else if err != nil {
+				return fmt.Errorf("boltutil: import record %d: %w", line, err)
+			}
+			name := Path(append([]string{rec.Bucket}, rec.Path...)...)
+			b, err := bucketFor(tx, name, true)
+			if err != nil {
+				return err
+			}
+			if rec.Key == "" && rec.KeyBase64 == "" {
+				continue
+			}
+			key, value, err := rec.decode()
+			if err != nil {
+				return fmt.Errorf("boltutil: import record %d: %w", line, err)
+			}
+			if rec.ExpiresAt != nil && !now.Before(*rec.ExpiresAt) {
+				continue
+			}
+			if err := s.reindexTx(tx, name, b, key, value); err != nil {
+				return err
+			}
+			if err := b.Put(key, value); err != nil {
+				return err
+			}
+			if err := clearTTL(tx, name, key); err != nil {
+				return err
+			}
+			if rec.ExpiresAt != nil {
+				if err := setTTL(tx, name, key, *rec.ExpiresAt); err != nil {
+					return err
+				}
+			}
+			n++
+		}
+	})
+	return n, err
+}
+
+// decode 解析记录中的键和值
+func (r Record) decode() (key, value []byte, err error) {
+	key = []byte(r.Key)
+	if r.KeyBase64 != "" {
+		if key, err = base64.StdEncoding.DecodeString(r.KeyBase64); err != nil {
+			return nil, nil, err
+		}
+	}
+	if r.ValueBase64 != "" {
+		value, err = base64.StdEncoding.DecodeString(r.ValueBase64)
+		return key, value, err
+	}
+	return key, []byte(r.Value), nil
+}
+
+// progressWriter 统计写入字节数并回调进度
+type progressWriter struct {
+	w        io.Writer
+	written  int64
+	total    int64
+	progress func(written, total int64)
+}
+
+// Write 写入并回调进度
+func (p *progressWriter) Write(b []byte) (int, error) {
+	n, err := p.w.Write(b)
+	p.written += int64(n)
+	if p.progress != nil {
+		p.progress(p.written, p.total)
+	}
+	return n, err
+}
@@ -0,0 +1,472 @@
+package boltutil
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.etcd.io/bbolt"
+)
+
+// bucketFor 获取存储桶,name 可以是 Path 生成的嵌套路径,
+// create 为true时逐级创建,否则不存在时返回 ErrBucketNotFound
+func bucketFor(tx *bbolt.Tx, name []byte, create bool) (*bbolt.Bucket, error) {
+	var b *bbolt.Bucket
+	var err error
+	for i, part := range splitPath(name) {
+		switch {
+		case create && i == 0:
+			b, err = tx.CreateBucketIfNotExists(part)
+		case create:
+			b, err = b.CreateBucketIfNotExists(part)
+		case i == 0:
+			b = tx.Bucket(part)
+		default:
+			b = b.Bucket(part)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if b == nil {
+			return nil, fmt.Errorf("%w: %s", ErrBucketNotFound, displayName(name))
+		}
+	}
+	return b, nil
+}
+
+// current 返回默认 Store,未打开时返回 ErrDatabaseNotOpen
+func current() (*Store, error) {
+	mu.RLock()
+	defer mu.RUnlock()
+	if defaultStore == nil {
+		return nil, ErrDatabaseNotOpen
+	}
+	return defaultStore, nil
+}
+
+// openDefault 默认 Store 已打开时直接返回,否则按配置打开
+func openDefault(cfg BoltConfig) (*Store, error) {
+	mu.Lock()
+	defer mu.Unlock()
+	if defaultStore != nil {
+		return defaultStore, nil
+	}
+	if cfg.Path == "" {
+		return nil, ErrDatabaseNotOpen
+	}
+	s, err := Open(cfg.Path, WithTimeout(cfg.Timeout), WithBoltOptions(cfg.Options))
+	if err != nil {
+		return nil, err
+	}
+	defaultStore = s
+	return s, nil
+}
+
+// newScanConfig 应用扫描选项
+func newScanConfig(opts []ScanOption) *scanConfig {
+	cfg := &scanConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	return cfg
+}
+
+// scan 按配置游标遍历,after 非空时从该键之后(不含)继续,跳过嵌套存储桶
+func scan(b *bbolt.Bucket, cfg *scanConfig, after []byte, fn func(k, v []byte) error) error {
+	lower, upper := cfg.start, cfg.end
+	if cfg.prefix != nil {
+		if bytes.Compare(cfg.prefix, lower) > 0 {
+			lower = cfg.prefix
+		}
+		if end := prefixEnd(cfg.prefix); end != nil && (upper == nil || bytes.Compare(end, upper) < 0) {
+			upper = end
+		}
+	}
+
+	c := b.Cursor()
+	var k, v []byte
+	var next func() ([]byte, []byte)
+	if cfg.reverse {
+		if after != nil && (upper == nil || bytes.Compare(after, upper) < 0) {
+			upper = after
+		}
+		if upper == nil {
+			k, v = c.Last()
+		} else if k, v = c.Seek(upper); k == nil {
+			k, v = c.Last()
+		} else {
+			k, v = c.Prev()
+		}
+		next = c.Prev
+	} else {
+		switch {
+		case after != nil:
+			if k, v = c.Seek(after); bytes.Equal(k, after) {
+				k, v = c.Next()
+			}
+		case lower != nil:
+			k, v = c.Seek(lower)
+		default:
+			k, v = c.First()
+		}
+		next = c.Next
+	}
+
+	n := 0
+	for ; k != nil; k, v = next() {
+		if upper != nil && bytes.Compare(k, upper) >= 0 {
+			if cfg.reverse {
+				continue
+			}
+			break
+		}
+		if lower != nil && bytes.Compare(k, lower) < 0 {
+			if cfg.reverse {
+				break
+			}
+			continue
+		}
+		if v == nil || (cfg.skip != nil && cfg.skip(k)) {
+			continue // 嵌套存储桶或已过期
+		}
+		if err := fn(k, v); err != nil {
+			return err
+		}
+		if n++; cfg.limit > 0 && n >= cfg.limit {
+			break
+		}
+	}
+	return nil
+}
+
+// prefixEnd 返回大于所有以 prefix 开头的键的最小键,prefix 全为0xff时返回 nil
+func prefixEnd(prefix []byte) []byte {
+	end := append([]byte(nil), prefix...)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i]++
+			return end[:i+1]
+		}
+	}
+	return nil
+}
+
+// ttlIndex 返回存储桶的过期索引: keys 为 键->过期时间, queue 为 过期时间+键->键,
+// create 为false且索引不存在时返回 nil
+func ttlIndex(tx *bbolt.Tx, bucket []byte, create bool) (keys, queue *bbolt.Bucket, err error) {
+	if !create {
+		root := tx.Bucket([]byte(ttlBucket))
+		if root == nil {
+			return nil, nil, nil
+		}
+		idx := root.Bucket(bucket)
+		if idx == nil {
+			return nil, nil, nil
+		}
+		return idx.Bucket([]byte("keys")), idx.Bucket([]byte("queue")), nil
+	}
+	root, err := tx.CreateBucketIfNotExists([]byte(ttlBucket))
+	if err != nil {
+		return nil, nil, err
+	}
+	idx, err := root.CreateBucketIfNotExists(bucket)
+	if err != nil {
+		return nil, nil, err
+	}
+	if keys, err = idx.CreateBucketIfNotExists([]byte("keys")); err != nil {
+		return nil, nil, err
+	}
+	queue, err = idx.CreateBucketIfNotExists([]byte("queue"))
+	return keys, queue, err
+}
+
+// setTTL 记录键的过期时间
+func setTTL(tx *bbolt.Tx, bucket, key []byte, at time.Time) error {
+	keys, queue, err := ttlIndex(tx, bucket, true)
+	if err != nil {
+		return err
+	}
+	exp := TimeKeys.EncodeKey(at)
+	if err := keys.Put(key, exp); err != nil {
+		return err
+	}
+	return queue.Put(append(exp, key...), key)
+}
+
+// clearTTL 清除键的过期时间
+func clearTTL(tx *bbolt.Tx, bucket, key []byte) error {
+	keys, queue, _ := ttlIndex(tx, bucket, false)
+	if keys == nil {
+		return nil
+	}
+	exp := keys.Get(key)
+	if exp == nil {
+		return nil
+	}
+	if err := queue.Delete(append(append([]byte(nil), exp...), key...)); err != nil {
+		return err
+	}
+	return keys.Delete(key)
+}
+
+// dropTTL 删除存储桶及其嵌套存储桶的过期索引
+func dropTTL(tx *bbolt.Tx, bucket []byte) error {
+	return dropNested(tx.Bucket([]byte(ttlBucket)), bucket)
+}
+
+// expiryOf 返回键的过期时间,未设置时 ok 为false
+func expiryOf(tx *bbolt.Tx, bucket, key []byte) (time.Time, bool) {
+	keys, _, _ := ttlIndex(tx, bucket, false)
+	if keys == nil {
+		return time.Time{}, false
+	}
+	exp := keys.Get(key)
+	if exp == nil {
+		return time.Time{}, false
+	}
+	at, err := TimeKeys.DecodeKey(exp)
+	return at, err == nil
+}
+
+// isExpired 判断键在 now 时是否已过期
+func isExpired(tx *bbolt.Tx, bucket, key []byte, now time.Time) bool {
+	at, ok := expiryOf(tx, bucket, key)
+	return ok && !now.Before(at)
+}
+
+// expiredFilter 返回判断键是否过期的函数,存储桶没有过期索引时返回 nil
+func expiredFilter(tx *bbolt.Tx, bucket []byte, now time.Time) func(k []byte) bool {
+	keys, _, _ := ttlIndex(tx, bucket, false)
+	if keys == nil {
+		return nil
+	}
+	return func(k []byte) bool {
+		exp := keys.Get(k)
+		if exp == nil {
+			return false
+		}
+		at, err := TimeKeys.DecodeKey(exp)
+		return err == nil && !now.Before(at)
+	}
+}
+
+// sweepTx 在事务中删除最多 limit 个已过期的键
+func sweepTx(tx *bbolt.Tx, now time.Time, limit int) (int, error) {
+	root := tx.Bucket([]byte(ttlBucket))
+	if root == nil {
+		return 0, nil
+	}
+	var names [][]byte
+	_ = root.ForEach(func(k, v []byte) error {
+		if v == nil {
+			names = append(names, append([]byte(nil), k...))
+		}
+		return nil
+	})
+
+	deadline := TimeKeys.EncodeKey(now)
+	n := 0
+	for _, name := range names {
+		keys, queue, _ := ttlIndex(tx, name, false)
+		if keys == nil {
+			continue
+		}
+		// 先收集再删除,避免删除时游标跳过元素
+		var entries, expired [][]byte
+		c := queue.Cursor()
+		for k, v := c.First(); k != nil && n+len(entries) < limit; k, v = c.Next() {
+			if bytes.Compare(k[:8], deadline) > 0 {
+				break
+			}
+			entries = append(entries, append([]byte(nil), k...))
+			expired = append(expired, append([]byte(nil), v...))
+		}
+		data, _ := bucketFor(tx, name, false)
+		for i, key := range expired {
+			if data != nil {
+				if err := data.Delete(key); err != nil {
+					return n, err
+				}
+			}
+			if err := keys.Delete(key); err != nil {
+				return n, err
+			}
+			if err := queue.Delete(entries[i]); err != nil {
+				return n, err
+			}
+			n++
+		}
+		if n >= limit {
+			break
+		}
+	}
+	return n, nil
+}
+
+// indexBucket 返回索引数据存储桶,create 为false且不存在时返回 nil
+func indexBucket(tx *bbolt.Tx, bucket []byte, name string, create bool) (*bbolt.Bucket, error) {
+	if !create {
+		root := tx.Bucket([]byte(indexBucketName))
+		if root == nil {
+			return nil, nil
+		}
+		idx := root.Bucket(bucket)
+		if idx == nil {
+			return nil, nil
+		}
+		return idx.Bucket([]byte(name)), nil
+	}
+	root, err := tx.CreateBucketIfNotExists([]byte(indexBucketName))
+	if err != nil {
+		return nil, err
+	}
+	idx, err := root.CreateBucketIfNotExists(bucket)
+	if err != nil {
+		return nil, err
+	}
+	return idx.CreateBucketIfNotExists([]byte(name))
+}
+
+// indexPrefix 索引项前缀: 取值长度(uvarint)+取值,之后拼接主键,长度前缀保证取值互不为前缀
+func indexPrefix(value string) []byte {
+	prefix := binary.AppendUvarint(nil, uint64(len(value)))
+	return append(prefix, value...)
+}
+
+// dropIndex 删除单个索引的数据
+func dropIndex(tx *bbolt.Tx, bucket []byte, name string) error {
+	root := tx.Bucket([]byte(indexBucketName))
+	if root == nil {
+		return nil
+	}
+	idx := root.Bucket(bucket)
+	if idx == nil || idx.Bucket([]byte(name)) == nil {
+		return nil
+	}
+	return idx.DeleteBucket([]byte(name))
+}
+
+// dropIndexes 删除存储桶及其嵌套存储桶的所有索引数据
+func dropIndexes(tx *bbolt.Tx, bucket []byte) error {
+	return dropNested(tx.Bucket([]byte(indexBucketName)), bucket)
+}
+
+// dropNested 删除内部存储桶 root 中名为 bucket 或属于其嵌套存储桶的子桶
+func dropNested(root *bbolt.Bucket, bucket []byte) error {
+	if root == nil {
+		return nil
+	}
+	prefix := encodePath(splitPath(bucket))
+	var names [][]byte
+	_ = root.ForEach(func(k, v []byte) error {
+		if v == nil && (bytes.Equal(k, bucket) || (len(k) > len(prefix) && bytes.HasPrefix(k, prefix))) {
+			names = append(names, append([]byte(nil), k...))
+		}
+		return nil
+	})
+	for _, name := range names {
+		if err := root.DeleteBucket(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteTx 删除键及其索引项和过期时间
+func (s *Store) deleteTx(tx *bbolt.Tx, bucket, key []byte) error {
+	b, err := bucketFor(tx, bucket, false)
+	if err != nil {
+		return err
+	}
+	if err := s.reindexTx(tx, bucket, b, key, nil); err != nil {
+		return err
+	}
+	if err := clearTTL(tx, bucket, key); err != nil {
+		return err
+	}
+	return b.Delete(key)
+}
+
+// deleteBucketTx 删除存储桶(支持嵌套路径)及其索引和过期数据
+func deleteBucketTx(tx *bbolt.Tx, name []byte) error {
+	var err error
+	parts := splitPath(name)
+	last := parts[len(parts)-1]
+	if len(parts) == 1 {
+		err = tx.DeleteBucket(last)
+	} else {
+		parent, perr := bucketFor(tx, encodePath(parts[:len(parts)-1]), false)
+		if perr != nil {
+			return perr
+		}
+		err = parent.DeleteBucket(last)
+	}
+	if errors.Is(err, bbolt.ErrBucketNotFound) {
+		return fmt.Errorf("%w: %s", ErrBucketNotFound, displayName(name))
+	}
+	if err != nil {
+		return err
+	}
+	if err := dropIndexes(tx, name); err != nil {
+		return err
+	}
+	return dropTTL(tx, name)
+}
+
+// encodePath 编码嵌套路径: 标记字节后依次为各段的长度(uvarint)和内容,只有一段时即为该段本身
+func encodePath(parts [][]byte) []byte {
+	if len(parts) == 1 {
+		return parts[0]
+	}
+	buf := []byte{pathMarker}
+	for _, p := range parts {
+		buf = binary.AppendUvarint(buf, uint64(len(p)))
+		buf = append(buf, p...)
+	}
+	return buf
+}
+
+// splitPath 解析存储桶名称,Path 生成的嵌套路径返回各段,其余名称原样作为一段
+func splitPath(name []byte) [][]byte {
+	if len(name) == 0 || name[0] != pathMarker {
+		return [][]byte{name}
+	}
+	var parts [][]byte
+	for b := name[1:]; len(b) > 0; {
+		n, size := binary.Uvarint(b)
+		if size <= 0 || uint64(len(b)-size) < n {
+			return [][]byte{name}
+		}
+		parts = append(parts, b[size:size+int(n)])
+		b = b[size+int(n):]
+	}
+	if len(parts) < 2 {
+		return [][]byte{name}
+	}
+	return parts
+}
+
+// displayName 返回用于错误信息的存储桶名称,嵌套路径以 "/" 连接
+func displayName(name []byte) string {
+	return string(bytes.Join(splitPath(name), []byte("/")))
+}
+
+// isInternal 判断是否为内部使用的顶层存储桶
+func isInternal(name []byte) bool {
+	return string(name) == ttlBucket || string(name) == indexBucketName
+}
+
+// walkBucket 深度优先遍历存储桶,先以 k 为 nil 回调存储桶本身,再回调其中的键值,path 为从顶层开始的各段名称
+func walkBucket(path [][]byte, b *bbolt.Bucket, fn func(path [][]byte, k, v []byte) error) error {
+	if err := fn(path, nil, nil); err != nil {
+		return err
+	}
+	return b.ForEach(func(k, v []byte) error {
+		if v == nil {
+			return walkBucket(append(path[:len(path):len(path)], k), b.Bucket(k), fn)
+		}
+		return fn(path, k, v)
+	})
+}
@@ -0,0 +1,119 @@
+package boltutil
+
+import (
+	"fmt"
+	"sync"
+
+	"go.etcd.io/bbolt"
+)
+
+// Bucket 类型化的存储桶句柄,键通过 KeyCodec 编码,值通过 Codec 编码
+type Bucket[K any, V any] struct {
+	store   *Store
+	name    []byte
+	keys    KeyCodec[K]
+	codec   Codec
+	mu      sync.RWMutex
+	indexes []Index[V]
+}
+
+// bucketConfig 存储桶配置
+type bucketConfig struct {
+	codec Codec
+}
+
+// BucketOption 存储桶配置选项
+type BucketOption func(*bucketConfig)
+
+// WithBucketCodec 为存储桶单独设置值编解码器,默认使用 Store 的编解码器
+func WithBucketCodec(codec Codec) BucketOption {
+	return func(c *bucketConfig) {
+		if codec != nil {
+			c.codec = codec
+		}
+	}
+}
+
+// NewBucket 创建类型化存储桶句柄,存储桶不存在时自动创建;嵌套存储桶使用 string(Path(...)) 作为名称
+func NewBucket[K any, V any](s *Store, name string, keys KeyCodec[K], opts ...BucketOption) (*Bucket[K, V], error) {
+	cfg := &bucketConfig{codec: s.codec}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if err := s.CreateBucket([]byte(name)); err != nil {
+		return nil, err
+	}
+	return &Bucket[K, V]{store: s, name: []byte(name), keys: keys, codec: cfg.codec}, nil
+}
+
+// Name 返回存储桶名称
+func (b *Bucket[K, V]) Name() string {
+	return string(b.name)
+}
+
+// Store 返回所属的 Store
+func (b *Bucket[K, V]) Store() *Store {
+	return b.store
+}
+
+// Put 存储键值,会清除键原有的过期时间
+func (b *Bucket[K, V]) Put(key K, value V) error {
+	return b.PutWithTTL(key, value, 0)
+}
+
+// Get 获取值,键不存在时返回 ErrKeyNotFound
+func (b *Bucket[K, V]) Get(key K) (V, error) {
+	var value V
+	err := b.store.db.View(func(tx *bbolt.Tx) error {
+		var err error
+		value, err = b.getTx(tx, key)
+		return err
+	})
+	return value, err
+}
+
+// Has 判断键是否存在
+func (b *Bucket[K, V]) Has(key K) (bool, error) {
+	var found bool
+	err := b.store.db.View(func(tx *bbolt.Tx) error {
+		var err error
+		found, err = b.hasTx(tx, key)
+		return err
+	})
+	return found, err
+}
+
+// Delete 删除键,键不存在时不报错
+func (b *Bucket[K, V]) Delete(key K) error {
+	return b.store.db.Update(func(tx *bbolt.Tx) error {
+		return b.deleteTx(tx, key)
+	})
+}
+
+// ForEach 按键的字节序遍历未过期的键,fn 返回错误时停止遍历并返回该错误
+func (b *Bucket[K, V]) ForEach(fn func(key K, value V) error) error {
+	return b.Scan(fn)
+}
+
+// Count 返回未过期的键数量,不含嵌套存储桶,需要遍历整个存储桶
+func (b *Bucket[K, V]) Count() (int, error) {
+	n := 0
+	err := b.store.Scan(b.name, func(_, _ []byte) error {
+		n++
+		return nil
+	})
+	return n, err
+}
+
+// decode 解码键值
+func (b *Bucket[K, V]) decode(k, v []byte) (K, V, error) {
+	var value V
+	key, err := b.keys.DecodeKey(k)
+	if err != nil {
+		return key, value, fmt.Errorf("decoding key failed: %w", err)
+	}
+	if err := b.codec.Unmarshal(v, &value); err != nil {
+		return key, value, fmt.Errorf("decoding failed: %w", err)
+	}
+	return key, value, nil
+}
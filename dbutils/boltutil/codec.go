@@ -0,0 +1,138 @@
+package boltutil
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Codec 值编解码器
+type Codec interface {
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+// KeyCodec 键编解码器,编码结果的字节序决定了键在桶中的排列顺序
+type KeyCodec[K any] interface {
+	EncodeKey(key K) []byte
+	DecodeKey(data []byte) (K, error)
+}
+
+// gobCodec gob编解码,默认编解码器,与早期版本写入的数据兼容
+type gobCodec struct{}
+
+// jsonCodec JSON编解码,便于其他语言读取
+type jsonCodec struct{}
+
+// stringKeyCodec 字符串键
+type stringKeyCodec struct{}
+
+// bytesKeyCodec 字节切片键
+type bytesKeyCodec struct{}
+
+// int64KeyCodec 有序整数键,8字节大端并翻转符号位,负数排在正数之前
+type int64KeyCodec struct{}
+
+// uint64KeyCodec 有序无符号整数键,8字节大端
+type uint64KeyCodec struct{}
+
+// timeKeyCodec 有序时间键,按纳秒时间戳编码,精度到纳秒,不保留时区
+type timeKeyCodec struct{}
+
+var (
+	GobCodec  Codec = gobCodec{}
+	JSONCodec Codec = jsonCodec{}
+
+	StringKeys KeyCodec[string]    = stringKeyCodec{}
+	BytesKeys  KeyCodec[[]byte]    = bytesKeyCodec{}
+	Int64Keys  KeyCodec[int64]     = int64KeyCodec{}
+	Uint64Keys KeyCodec[uint64]    = uint64KeyCodec{}
+	TimeKeys   KeyCodec[time.Time] = timeKeyCodec{}
+)
+
+// Marshal gob编码
+func (gobCodec) Marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Unmarshal gob解码
+func (gobCodec) Unmarshal(data []byte, v interface{}) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
+
+// Marshal JSON编码
+func (jsonCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+// Unmarshal JSON解码
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+// EncodeKey 编码字符串键
+func (stringKeyCodec) EncodeKey(key string) []byte {
+	return []byte(key)
+}
+
+// DecodeKey 解码字符串键
+func (stringKeyCodec) DecodeKey(data []byte) (string, error) {
+	return string(data), nil
+}
+
+// EncodeKey 编码字节切片键
+func (bytesKeyCodec) EncodeKey(key []byte) []byte {
+	return key
+}
+
+// DecodeKey 解码字节切片键,返回副本,可在事务外使用
+func (bytesKeyCodec) DecodeKey(data []byte) ([]byte, error) {
+	return append([]byte(nil), data...), nil
+}
+
+// EncodeKey 编码有序整数键
+func (int64KeyCodec) EncodeKey(key int64) []byte {
+	return binary.BigEndian.AppendUint64(nil, uint64(key)^(1<<63))
+}
+
+// DecodeKey 解码有序整数键
+func (int64KeyCodec) DecodeKey(data []byte) (int64, error) {
+	if len(data) != 8 {
+		return 0, fmt.Errorf("%w: want 8 bytes, got %d", ErrInvalidKey, len(data))
+	}
+	return int64(binary.BigEndian.Uint64(data) ^ (1 << 63)), nil
+}
+
+// EncodeKey 编码有序无符号整数键
+func (uint64KeyCodec) EncodeKey(key uint64) []byte {
+	return binary.BigEndian.AppendUint64(nil, key)
+}
+
+// DecodeKey 解码有序无符号整数键
+func (uint64KeyCodec) DecodeKey(data []byte) (uint64, error) {
+	if len(data) != 8 {
+		return 0, fmt.Errorf("%w: want 8 bytes, got %d", ErrInvalidKey, len(data))
+	}
+	return binary.BigEndian.Uint64(data), nil
+}
+
+// EncodeKey 编码时间键,有效范围为1678年至2262年
+func (timeKeyCodec) EncodeKey(key time.Time) []byte {
+	return Int64Keys.EncodeKey(key.UnixNano())
+}
+
+// DecodeKey 解码时间键,返回本地时区的时间
+func (timeKeyCodec) DecodeKey(data []byte) (time.Time, error) {
+	n, err := Int64Keys.DecodeKey(data)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(0, n), nil
+}
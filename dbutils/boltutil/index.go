@@ -0,0 +1,293 @@
+package boltutil
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"slices"
+
+	"go.etcd.io/bbolt"
+)
+
+// indexBucketName 二级索引存储桶,结构为 __index__/数据存储桶/索引名
+const indexBucketName = "__index__"
+
+var (
+	ErrIndexNotFound   = errors.New("boltutil: index not found")
+	ErrUniqueViolation = errors.New("boltutil: unique index violation")
+)
+
+// Index 二级索引定义,Extract 返回值的索引取值,返回空表示该值不参与索引
+type Index[V any] struct {
+	Name    string
+	Unique  bool
+	Extract func(value V) []string
+}
+
+// indexer 类型擦除后的索引定义,注册到 Store 后由所有写入路径维护
+type indexer struct {
+	name    string
+	unique  bool
+	extract func(data []byte) ([]string, error)
+}
+
+// AddIndex 注册二级索引。注册后通过 Store、Tx、Bucket 以及 ImportJSONL 的写入和删除都会在同一事务中维护索引。
+// 索引数据不存在时会根据现有数据立即构建;每次打开数据库后都需要重新注册,
+// 未注册期间写入该存储桶会丢弃其索引数据,重新注册时自动重建
+func (b *Bucket[K, V]) AddIndex(idx Index[V]) error {
+	if idx.Name == "" || idx.Extract == nil {
+		return fmt.Errorf("boltutil: index name and extractor are required")
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, existing := range b.indexes {
+		if existing.Name == idx.Name {
+			return fmt.Errorf("boltutil: index %s already registered", idx.Name)
+		}
+	}
+	codec := b.codec
+	ix := indexer{name: idx.Name, unique: idx.Unique, extract: func(data []byte) ([]string, error) {
+		var v V
+		if err := codec.Unmarshal(data, &v); err != nil {
+			return nil, fmt.Errorf("decoding failed: %w", err)
+		}
+		return idx.Extract(v), nil
+	}}
+
+	// 在写事务内注册: bbolt 写事务串行执行,之后开始的写入都能看到该索引
+	err := b.store.db.Update(func(tx *bbolt.Tx) error {
+		b.store.registerIndex(b.name, ix)
+		if ib, _ := indexBucket(tx, b.name, idx.Name, false); ib != nil {
+			return nil
+		}
+		return b.store.buildIndex(tx, b.name, ix)
+	})
+	if err != nil {
+		b.store.unregisterIndex(b.name, idx.Name)
+		return err
+	}
+	b.indexes = append(b.indexes, idx)
+	return nil
+}
+
+// FindBy 按索引取值查询,结果按主键的字节序排列
+func (b *Bucket[K, V]) FindBy(index, value string) ([]Item[K, V], error) {
+	idx, err := b.index(index)
+	if err != nil {
+		return nil, err
+	}
+	var items []Item[K, V]
+	err = b.store.db.View(func(tx *bbolt.Tx) error {
+		bkt, err := bucketFor(tx, b.name, false)
+		if err != nil {
+			return err
+		}
+		ib, _ := indexBucket(tx, b.name, idx.Name, false)
+		if ib == nil {
+			return nil
+		}
+		expired := expiredFilter(tx, b.name, b.store.clock.Now())
+		prefix := indexPrefix(value)
+		c := ib.Cursor()
+		for k, pk := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, pk = c.Next() {
+			data := bkt.Get(pk)
+			if data == nil || (expired != nil && expired(pk)) {
+				continue // 已删除或已过期,索引项待清理
+			}
+			key, v, err := b.decode(pk, data)
+			if err != nil {
+				return err
+			}
+			if !slices.Contains(idx.Extract(v), value) {
+				continue // 绕过索引写入导致的陈旧索引项
+			}
+			items = append(items, Item[K, V]{Key: key, Value: v})
+		}
+		return nil
+	})
+	return items, err
+}
+
+// FindOne 按索引取值查询第一条,没有结果时返回 ErrKeyNotFound
+func (b *Bucket[K, V]) FindOne(index, value string) (K, V, error) {
+	var (
+		key K
+		v   V
+	)
+	items, err := b.FindBy(index, value)
+	if err != nil {
+		return key, v, err
+	}
+	if len(items) == 0 {
+		return key, v, ErrKeyNotFound
+	}
+	return items[0].Key, items[0].Value, nil
+}
+
+// RebuildIndex 根据现有数据重建索引,用于清理过期或删除后残留的索引项
+func (b *Bucket[K, V]) RebuildIndex(index string) error {
+	if _, err := b.index(index); err != nil {
+		return err
+	}
+	ix, ok := b.store.indexer(b.name, index)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrIndexNotFound, index)
+	}
+	return b.store.db.Update(func(tx *bbolt.Tx) error {
+		if err := dropIndex(tx, b.name, index); err != nil {
+			return err
+		}
+		return b.store.buildIndex(tx, b.name, ix)
+	})
+}
+
+// index 按名称查找已注册的索引
+func (b *Bucket[K, V]) index(name string) (Index[V], error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for _, idx := range b.indexes {
+		if idx.Name == name {
+			return idx, nil
+		}
+	}
+	return Index[V]{}, fmt.Errorf("%w: %s", ErrIndexNotFound, name)
+}
+
+// registerIndex 注册存储桶的索引,同名索引会被替换
+func (s *Store) registerIndex(bucket []byte, ix indexer) {
+	s.indexMu.Lock()
+	defer s.indexMu.Unlock()
+	if s.indexers == nil {
+		s.indexers = map[string][]indexer{}
+	}
+	list := slices.DeleteFunc(slices.Clone(s.indexers[string(bucket)]), func(e indexer) bool { return e.name == ix.name })
+	s.indexers[string(bucket)] = append(list, ix)
+}
+
+// unregisterIndex 取消注册存储桶的索引
+func (s *Store) unregisterIndex(bucket []byte, name string) {
+	s.indexMu.Lock()
+	defer s.indexMu.Unlock()
+	s.indexers[string(bucket)] = slices.DeleteFunc(slices.Clone(s.indexers[string(bucket)]), func(e indexer) bool { return e.name == name })
+}
+
+// indexer 按名称查找存储桶已注册的索引
+func (s *Store) indexer(bucket []byte, name string) (indexer, bool) {
+	for _, ix := range s.bucketIndexers(bucket) {
+		if ix.name == name {
+			return ix, true
+		}
+	}
+	return indexer{}, false
+}
+
+// bucketIndexers 返回存储桶已注册的索引
+func (s *Store) bucketIndexers(bucket []byte) []indexer {
+	s.indexMu.RLock()
+	defer s.indexMu.RUnlock()
+	return s.indexers[string(bucket)]
+}
+
+// buildIndex 在事务中根据现有未过期数据构建索引
+func (s *Store) buildIndex(tx *bbolt.Tx, bucket []byte, ix indexer) error {
+	bkt, err := bucketFor(tx, bucket, false)
+	if err != nil {
+		return err
+	}
+	ib, err := indexBucket(tx, bucket, ix.name, true)
+	if err != nil {
+		return err
+	}
+	cfg := &scanConfig{skip: expiredFilter(tx, bucket, s.clock.Now())}
+	return scan(bkt, cfg, nil, func(k, data []byte) error {
+		values, err := ix.extract(data)
+		if err != nil {
+			return err
+		}
+		return s.addEntries(tx, bucket, bkt, ib, ix, k, values)
+	})
+}
+
+// reindexTx 在写入或删除主键 k 之前更新存储桶的所有索引,data 为 nil 表示删除。
+// 存在索引数据但本 Store 未注册的索引无法维护,直接丢弃,重新注册时会重建
+func (s *Store) reindexTx(tx *bbolt.Tx, bucket []byte, bkt *bbolt.Bucket, k, data []byte) error {
+	indexers := s.bucketIndexers(bucket)
+	if err := dropUnregistered(tx, bucket, indexers); err != nil {
+		return err
+	}
+	if len(indexers) == 0 {
+		return nil
+	}
+	old := bkt.Get(k)
+	for _, ix := range indexers {
+		ib, err := indexBucket(tx, bucket, ix.name, true)
+		if err != nil {
+			return err
+		}
+		if old != nil {
+			if values, err := ix.extract(old); err == nil {
+				for _, iv := range values {
+					if err := ib.Delete(append(indexPrefix(iv), k...)); err != nil {
+						return err
+					}
+				}
+			}
+		}
+		if data != nil {
+			values, err := ix.extract(data)
+			if err != nil {
+				return fmt.Errorf("boltutil: index %s: %w", ix.name, err)
+			}
+			if err := s.addEntries(tx, bucket, bkt, ib, ix, k, values); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// addEntries 写入索引项,唯一索引已被其他有效主键占用时返回 ErrUniqueViolation
+func (s *Store) addEntries(tx *bbolt.Tx, bucket []byte, bkt, ib *bbolt.Bucket, ix indexer, k []byte, values []string) error {
+	now := s.clock.Now()
+	for _, iv := range values {
+		prefix := indexPrefix(iv)
+		if ix.unique {
+			c := ib.Cursor()
+			for ek, pk := c.Seek(prefix); ek != nil && bytes.HasPrefix(ek, prefix); ek, pk = c.Next() {
+				if bytes.Equal(pk, k) || bkt.Get(pk) == nil || isExpired(tx, bucket, pk, now) {
+					continue
+				}
+				return fmt.Errorf("%w: %s=%q", ErrUniqueViolation, ix.name, iv)
+			}
+		}
+		if err := ib.Put(append(prefix, k...), k); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dropUnregistered 删除存储桶中未注册索引的数据
+func dropUnregistered(tx *bbolt.Tx, bucket []byte, indexers []indexer) error {
+	root := tx.Bucket([]byte(indexBucketName))
+	if root == nil {
+		return nil
+	}
+	idx := root.Bucket(bucket)
+	if idx == nil {
+		return nil
+	}
+	var stale [][]byte
+	_ = idx.ForEach(func(name, v []byte) error {
+		if v == nil && !slices.ContainsFunc(indexers, func(ix indexer) bool { return ix.name == string(name) }) {
+			stale = append(stale, append([]byte(nil), name...))
+		}
+		return nil
+	})
+	for _, name := range stale {
+		if err := idx.DeleteBucket(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+package boltutil
+
+import (
+	"encoding/base64"
+
+	"go.etcd.io/bbolt"
+)
+
+// scanConfig 扫描配置
+type scanConfig struct {
+	prefix  []byte
+	start   []byte // 包含
+	end     []byte // 不包含
+	reverse bool
+	limit   int
+	skip    func(k []byte) bool // 跳过过期键
+}
+
+// ScanOption 扫描选项,键均为编码后的字节,类型化键可用 KeyCodec.EncodeKey 转换
+type ScanOption func(*scanConfig)
+
+// Item 键值对
+type Item[K any, V any] struct {
+	Key   K
+	Value V
+}
+
+// Page 分页结果,NextToken 为空表示没有更多数据
+type Page[K any, V any] struct {
+	Items     []Item[K, V]
+	NextToken string
+}
+
+// WithPrefix 只扫描以 prefix 开头的键
+func WithPrefix(prefix []byte) ScanOption {
+	return func(c *scanConfig) {
+		c.prefix = prefix
+	}
+}
+
+// WithRange 只扫描 [start, end) 区间内的键,nil 表示不限
+func WithRange(start, end []byte) ScanOption {
+	return func(c *scanConfig) {
+		c.start = start
+		c.end = end
+	}
+}
+
+// WithReverse 按键从大到小扫描
+func WithReverse() ScanOption {
+	return func(c *scanConfig) {
+		c.reverse = true
+	}
+}
+
+// WithLimit 最多返回 n 条,n<=0 表示不限
+func WithLimit(n int) ScanOption {
+	return func(c *scanConfig) {
+		c.limit = n
+	}
+}
+
+// Scan 按选项扫描存储桶,跳过已过期的键,k 和 v 仅在回调内有效
+func (s *Store) Scan(bucket []byte, fn func(k, v []byte) error, opts ...ScanOption) error {
+	cfg := newScanConfig(opts)
+	return s.db.View(func(tx *bbolt.Tx) error {
+		b, err := bucketFor(tx, bucket, false)
+		if err != nil {
+			return err
+		}
+		cfg.skip = expiredFilter(tx, bucket, s.clock.Now())
+		return scan(b, cfg, nil, fn)
+	})
+}
+
+// Scan 按选项扫描存储桶
+func (b *Bucket[K, V]) Scan(fn func(key K, value V) error, opts ...ScanOption) error {
+	return b.store.Scan(b.name, func(k, v []byte) error {
+		key, value, err := b.decode(k, v)
+		if err != nil {
+			return err
+		}
+		return fn(key, value)
+	}, opts...)
+}
+
+// Prefix 扫描编码后以 prefix 编码结果开头的键,适用于字符串和字节键
+func (b *Bucket[K, V]) Prefix(prefix K, fn func(key K, value V) error, opts ...ScanOption) error {
+	return b.Scan(fn, append(opts, WithPrefix(b.keys.EncodeKey(prefix)))...)
+}
+
+// Range 扫描 [start, end) 区间内的键
+func (b *Bucket[K, V]) Range(start, end K, fn func(key K, value V) error, opts ...ScanOption) error {
+	return b.Scan(fn, append(opts, WithRange(b.keys.EncodeKey(start), b.keys.EncodeKey(end)))...)
+}
+
+// List 分页列出键值对,token 为上一页返回的 NextToken,首页传空字符串
+func (b *Bucket[K, V]) List(pageSize int, token string, opts ...ScanOption) (Page[K, V], error) {
+	var page Page[K, V]
+	cfg := newScanConfig(opts)
+	after, err := decodeToken(token, cfg.reverse)
+	if err != nil {
+		return page, err
+	}
+	if pageSize <= 0 {
+		pageSize = 100
+	}
+	cfg.limit = pageSize + 1 // 多取一条判断是否还有下一页
+
+	var last []byte
+	err = b.store.db.View(func(tx *bbolt.Tx) error {
+		bkt, err := bucketFor(tx, b.name, false)
+		if err != nil {
+			return err
+		}
+		cfg.skip = expiredFilter(tx, b.name, b.store.clock.Now())
+		return scan(bkt, cfg, after, func(k, v []byte) error {
+			if len(page.Items) == pageSize {
+				page.NextToken = encodeToken(last, cfg.reverse)
+				return nil
+			}
+			key, value, err := b.decode(k, v)
+			if err != nil {
+				return err
+			}
+			page.Items = append(page.Items, Item[K, V]{Key: key, Value: value})
+			last = append(last[:0], k...)
+			return nil
+		})
+	})
+	return page, err
+}
+
+// encodeToken 将方向和最后一个键编码为不透明的翻页令牌
+func encodeToken(key []byte, reverse bool) string {
+	dir := byte('f')
+	if reverse {
+		dir = 'r'
+	}
+	return base64.RawURLEncoding.EncodeToString(append([]byte{dir}, key...))
+}
+
+// decodeToken 解析翻页令牌,扫描方向与令牌不一致时返回 ErrInvalidToken
+func decodeToken(token string, reverse bool) ([]byte, error) {
+	if token == "" {
+		return nil, nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil || len(data) < 2 {
+		return nil, ErrInvalidToken
+	}
+	if (data[0] == 'r') != reverse || (data[0] != 'r' && data[0] != 'f') {
+		return nil, ErrInvalidToken
+	}
+	return data[1:], nil
+}
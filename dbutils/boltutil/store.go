@@ -0,0 +1,230 @@
+package boltutil
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/wind959/ko-utils/clock"
+	"go.etcd.io/bbolt"
+)
+
+var (
+	ErrDatabaseNotOpen = errors.New("boltutil: database is not open")
+	ErrBucketNotFound  = errors.New("boltutil: bucket not found")
+	ErrKeyNotFound     = errors.New("boltutil: key not found")
+	ErrInvalidKey      = errors.New("boltutil: invalid key")
+	ErrInvalidToken    = errors.New("boltutil: invalid page token")
+)
+
+// Store bbolt数据库实例,可以同时打开多个
+type Store struct {
+	db        *bbolt.DB
+	codec     Codec
+	clock     clock.Clock
+	stop      chan struct{} // 关闭后台清理
+	done      chan struct{}
+	closeOnce sync.Once
+
+	indexMu  sync.RWMutex
+	indexers map[string][]indexer // 按存储桶名称注册的索引,所有写入路径都会维护
+}
+
+// storeConfig Store 配置
+type storeConfig struct {
+	timeout       time.Duration
+	options       *bbolt.Options
+	codec         Codec
+	clock         clock.Clock
+	sweepInterval time.Duration
+	sweepBatch    int
+}
+
+// Option Store 配置选项
+type Option func(*storeConfig)
+
+// WithTimeout 设置获取文件锁的超时时间
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *storeConfig) {
+		c.timeout = timeout
+	}
+}
+
+// WithBoltOptions 设置bbolt原生选项,Timeout 为0时使用 WithTimeout 的值
+func WithBoltOptions(options *bbolt.Options) Option {
+	return func(c *storeConfig) {
+		c.options = options
+	}
+}
+
+// WithCodec 设置值编解码器,默认 GobCodec
+func WithCodec(codec Codec) Option {
+	return func(c *storeConfig) {
+		if codec != nil {
+			c.codec = codec
+		}
+	}
+}
+
+// WithClock 设置时钟,用于判断键是否过期,测试中可传入 clock.NewFake
+func WithClock(c clock.Clock) Option {
+	return func(cfg *storeConfig) {
+		cfg.clock = clock.OrReal(c)
+	}
+}
+
+// WithTTLSweeper 启动后台清理,每隔 interval 分批删除过期键,每个事务最多删除 batchSize 个
+func WithTTLSweeper(interval time.Duration, batchSize int) Option {
+	return func(c *storeConfig) {
+		c.sweepInterval = interval
+		c.sweepBatch = batchSize
+	}
+}
+
+// Open 打开数据库文件,文件不存在时自动创建
+func Open(path string, opts ...Option) (*Store, error) {
+	cfg := &storeConfig{codec: GobCodec, clock: clock.Real}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	options := &bbolt.Options{}
+	if cfg.options != nil {
+		copied := *cfg.options
+		options = &copied
+	}
+	if options.Timeout == 0 {
+		options.Timeout = cfg.timeout
+	}
+
+	db, err := bbolt.Open(path, 0600, options)
+	if err != nil {
+		return nil, err
+	}
+	s := &Store{db: db, codec: cfg.codec, clock: cfg.clock}
+	if cfg.sweepInterval > 0 {
+		s.stop = make(chan struct{})
+		s.done = make(chan struct{})
+		// 定时器在启动goroutine前创建,保证从打开时开始计时
+		go s.sweepLoop(s.clock.NewTimer(cfg.sweepInterval), cfg.sweepInterval, cfg.sweepBatch)
+	}
+	return s, nil
+}
+
+// DB 返回底层的bbolt实例
+func (s *Store) DB() *bbolt.DB {
+	return s.db
+}
+
+// Path 返回数据库文件路径
+func (s *Store) Path() string {
+	return s.db.Path()
+}
+
+// Codec 返回值编解码器
+func (s *Store) Codec() Codec {
+	return s.codec
+}
+
+// Close 停止后台清理并关闭数据库
+func (s *Store) Close() error {
+	s.closeOnce.Do(func() {
+		if s.stop != nil {
+			close(s.stop)
+			<-s.done
+		}
+	})
+	return s.db.Close()
+}
+
+// pathMarker 嵌套路径编码的首字节,以该字节开头的存储桶名称保留给 Path
+const pathMarker = 0x00
+
+// Path 返回嵌套存储桶 segments[0]→segments[1]→… 的名称,可传给所有接受存储桶名称的方法;
+// 只有一段时即为该段本身。普通名称中的 "/" 没有特殊含义
+func Path(segments ...string) []byte {
+	parts := make([][]byte, len(segments))
+	for i, seg := range segments {
+		parts[i] = []byte(seg)
+	}
+	return encodePath(parts)
+}
+
+// CreateBucket 创建存储桶,已存在时不报错,Path 生成的嵌套路径会逐级创建
+func (s *Store) CreateBucket(name []byte) error {
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		_, err := bucketFor(tx, name, true)
+		return err
+	})
+}
+
+// DeleteBucket 删除存储桶,支持 Path 生成的嵌套路径
+func (s *Store) DeleteBucket(name []byte) error {
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		return deleteBucketTx(tx, name)
+	})
+}
+
+// Buckets 列出 parent 下的子存储桶名称,parent 为空时列出顶层存储桶(不含内部存储桶),
+// parent 可以是 Path 生成的嵌套路径
+func (s *Store) Buckets(parent []byte) ([]string, error) {
+	var names []string
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		collect := func(k, v []byte) error {
+			if v == nil {
+				names = append(names, string(k))
+			}
+			return nil
+		}
+		if len(parent) == 0 {
+			return tx.ForEach(func(name []byte, _ *bbolt.Bucket) error {
+				if !isInternal(name) {
+					names = append(names, string(name))
+				}
+				return nil
+			})
+		}
+		b, err := bucketFor(tx, parent, false)
+		if err != nil {
+			return err
+		}
+		return b.ForEach(collect)
+	})
+	return names, err
+}
+
+// Put 存储数据(自动序列化),存储桶不存在时返回 ErrBucketNotFound,会清除键原有的过期时间
+func (s *Store) Put(bucket, key []byte, value interface{}) error {
+	return s.PutWithTTL(bucket, key, value, 0)
+}
+
+// Get 获取数据(自动反序列化),键不存在时返回 ErrKeyNotFound
+func (s *Store) Get(bucket, key []byte, value interface{}) error {
+	return s.db.View(func(tx *bbolt.Tx) error {
+		return s.getTx(tx, bucket, key, value)
+	})
+}
+
+// Delete 删除数据
+func (s *Store) Delete(bucket, key []byte) error {
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		return s.deleteTx(tx, bucket, key)
+	})
+}
+
+// ForEach 遍历存储桶中未过期的键值对,k 和 v 仅在回调内有效
+func (s *Store) ForEach(bucket []byte, fn func(k, v []byte) error) error {
+	return s.Scan(bucket, fn)
+}
+
+// Backup 备份数据库到文件
+func (s *Store) Backup(path string) error {
+	return s.db.View(func(tx *bbolt.Tx) error {
+		return tx.CopyFile(path, 0600)
+	})
+}
+
+// Stats 获取数据库统计信息
+func (s *Store) Stats() bbolt.Stats {
+	return s.db.Stats()
+}
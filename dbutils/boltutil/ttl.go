@@ -0,0 +1,152 @@
+package boltutil
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/wind959/ko-utils/clock"
+	"go.etcd.io/bbolt"
+)
+
+// ttlBucket 过期索引存储桶,每个数据存储桶对应其中一个同名子桶
+const ttlBucket = "__ttl__"
+
+// PutWithTTL 存储数据并设置存活时长,ttl<=0 表示永不过期
+func (s *Store) PutWithTTL(bucket, key []byte, value interface{}, ttl time.Duration) error {
+	data, err := s.codec.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("encoding failed: %w", err)
+	}
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		return s.putTx(tx, bucket, key, data, ttl)
+	})
+}
+
+// Expire 重新设置键的存活时长,ttl<=0 表示取消过期,键不存在或已过期时返回 ErrKeyNotFound
+func (s *Store) Expire(bucket, key []byte, ttl time.Duration) error {
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		b, err := bucketFor(tx, bucket, false)
+		if err != nil {
+			return err
+		}
+		now := s.clock.Now()
+		if b.Get(key) == nil || isExpired(tx, bucket, key, now) {
+			return ErrKeyNotFound
+		}
+		if err := clearTTL(tx, bucket, key); err != nil {
+			return err
+		}
+		if ttl <= 0 {
+			return nil
+		}
+		return setTTL(tx, bucket, key, now.Add(ttl))
+	})
+}
+
+// ExpiresAt 返回键的过期时间,ok 为false表示永不过期,键不存在或已过期时返回 ErrKeyNotFound
+func (s *Store) ExpiresAt(bucket, key []byte) (at time.Time, ok bool, err error) {
+	err = s.db.View(func(tx *bbolt.Tx) error {
+		b, err := bucketFor(tx, bucket, false)
+		if err != nil {
+			return err
+		}
+		if b.Get(key) == nil {
+			return ErrKeyNotFound
+		}
+		at, ok = expiryOf(tx, bucket, key)
+		if ok && !s.clock.Now().Before(at) {
+			return ErrKeyNotFound
+		}
+		return nil
+	})
+	return at, ok, err
+}
+
+// ScanWithTTL 与 Scan 相同,但在同一只读事务中一并返回每个键的过期时间,ok 为false表示永不过期
+func (s *Store) ScanWithTTL(bucket []byte, fn func(k, v []byte, at time.Time, ok bool) error, opts ...ScanOption) error {
+	cfg := newScanConfig(opts)
+	return s.db.View(func(tx *bbolt.Tx) error {
+		b, err := bucketFor(tx, bucket, false)
+		if err != nil {
+			return err
+		}
+		cfg.skip = expiredFilter(tx, bucket, s.clock.Now())
+		return scan(b, cfg, nil, func(k, v []byte) error {
+			at, ok := expiryOf(tx, bucket, k)
+			return fn(k, v, at, ok)
+		})
+	})
+}
+
+// SweepExpired 删除所有存储桶中已过期的键,每个事务最多删除 batchSize 个,返回删除的数量
+func (s *Store) SweepExpired(batchSize int) (int, error) {
+	if batchSize <= 0 {
+		batchSize = 1000
+	}
+	total := 0
+	for {
+		n := 0
+		err := s.db.Update(func(tx *bbolt.Tx) error {
+			var err error
+			n, err = sweepTx(tx, s.clock.Now(), batchSize)
+			return err
+		})
+		total += n
+		if err != nil || n < batchSize {
+			return total, err
+		}
+	}
+}
+
+// PutWithTTL 存储键值并设置存活时长,ttl<=0 表示永不过期
+func (b *Bucket[K, V]) PutWithTTL(key K, value V, ttl time.Duration) error {
+	return b.store.db.Update(func(tx *bbolt.Tx) error {
+		return b.putTx(tx, key, value, ttl)
+	})
+}
+
+// Expire 重新设置键的存活时长,ttl<=0 表示取消过期
+func (b *Bucket[K, V]) Expire(key K, ttl time.Duration) error {
+	return b.store.Expire(b.name, b.keys.EncodeKey(key), ttl)
+}
+
+// ExpiresAt 返回键的过期时间,ok 为false表示永不过期
+func (b *Bucket[K, V]) ExpiresAt(key K) (time.Time, bool, error) {
+	return b.store.ExpiresAt(b.name, b.keys.EncodeKey(key))
+}
+
+// putTx 在事务中写入已编码的值,并维护二级索引和过期索引
+func (s *Store) putTx(tx *bbolt.Tx, bucket, key, data []byte, ttl time.Duration) error {
+	b, err := bucketFor(tx, bucket, false)
+	if err != nil {
+		return err
+	}
+	if err := s.reindexTx(tx, bucket, b, key, data); err != nil {
+		return err
+	}
+	if err := b.Put(key, data); err != nil {
+		return err
+	}
+	if err := clearTTL(tx, bucket, key); err != nil {
+		return err
+	}
+	if ttl <= 0 {
+		return nil
+	}
+	return setTTL(tx, bucket, key, s.clock.Now().Add(ttl))
+}
+
+// sweepLoop 后台定时清理过期键
+func (s *Store) sweepLoop(timer clock.Timer, interval time.Duration, batchSize int) {
+	defer close(s.done)
+	defer timer.Stop()
+	for {
+		select {
+		case <-timer.C():
+			_, _ = s.SweepExpired(batchSize)
+			timer.Reset(interval)
+		case <-s.stop:
+			return
+		}
+	}
+}
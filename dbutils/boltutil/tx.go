@@ -0,0 +1,186 @@
+package boltutil
+
+import (
+	"fmt"
+	"time"
+
+	"go.etcd.io/bbolt"
+)
+
+// Tx 事务包装,提供与 Store 相同的读写方法,只能在 Update、View 或 Batch 的回调内使用
+type Tx struct {
+	tx    *bbolt.Tx
+	store *Store
+}
+
+// TxBucket 绑定到事务的类型化存储桶
+type TxBucket[K any, V any] struct {
+	bucket *Bucket[K, V]
+	tx     *Tx
+}
+
+// Update 在读写事务中执行 fn,fn 返回错误时回滚,可以跨多个存储桶原子写入
+func (s *Store) Update(fn func(tx *Tx) error) error {
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		return fn(&Tx{tx: tx, store: s})
+	})
+}
+
+// View 在只读事务中执行 fn
+func (s *Store) View(fn func(tx *Tx) error) error {
+	return s.db.View(func(tx *bbolt.Tx) error {
+		return fn(&Tx{tx: tx, store: s})
+	})
+}
+
+// Batch 与其他并发调用合并到同一个读写事务中执行,适合大量goroutine同时写入。
+// 批次中某个 fn 失败时其余 fn 会重新执行,因此 fn 必须是幂等的
+func (s *Store) Batch(fn func(tx *Tx) error) error {
+	return s.db.Batch(func(tx *bbolt.Tx) error {
+		return fn(&Tx{tx: tx, store: s})
+	})
+}
+
+// Bolt 返回底层的bbolt事务
+func (t *Tx) Bolt() *bbolt.Tx {
+	return t.tx
+}
+
+// Writable 判断是否为读写事务
+func (t *Tx) Writable() bool {
+	return t.tx.Writable()
+}
+
+// CreateBucket 创建存储桶,支持 Path 生成的嵌套路径
+func (t *Tx) CreateBucket(name []byte) error {
+	_, err := bucketFor(t.tx, name, true)
+	return err
+}
+
+// DeleteBucket 删除存储桶,支持 Path 生成的嵌套路径
+func (t *Tx) DeleteBucket(name []byte) error {
+	return deleteBucketTx(t.tx, name)
+}
+
+// Put 存储数据(自动序列化)
+func (t *Tx) Put(bucket, key []byte, value interface{}) error {
+	return t.PutWithTTL(bucket, key, value, 0)
+}
+
+// PutWithTTL 存储数据并设置存活时长,ttl<=0 表示永不过期
+func (t *Tx) PutWithTTL(bucket, key []byte, value interface{}, ttl time.Duration) error {
+	data, err := t.store.codec.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("encoding failed: %w", err)
+	}
+	return t.store.putTx(t.tx, bucket, key, data, ttl)
+}
+
+// Get 获取数据(自动反序列化),键不存在时返回 ErrKeyNotFound
+func (t *Tx) Get(bucket, key []byte, value interface{}) error {
+	return t.store.getTx(t.tx, bucket, key, value)
+}
+
+// Delete 删除数据
+func (t *Tx) Delete(bucket, key []byte) error {
+	return t.store.deleteTx(t.tx, bucket, key)
+}
+
+// Scan 按选项扫描存储桶,跳过已过期的键
+func (t *Tx) Scan(bucket []byte, fn func(k, v []byte) error, opts ...ScanOption) error {
+	b, err := bucketFor(t.tx, bucket, false)
+	if err != nil {
+		return err
+	}
+	cfg := newScanConfig(opts)
+	cfg.skip = expiredFilter(t.tx, bucket, t.store.clock.Now())
+	return scan(b, cfg, nil, fn)
+}
+
+// ForEach 遍历存储桶中未过期的键值对
+func (t *Tx) ForEach(bucket []byte, fn func(k, v []byte) error) error {
+	return t.Scan(bucket, fn)
+}
+
+// In 将类型化存储桶绑定到事务,事务必须属于同一个 Store
+func (b *Bucket[K, V]) In(tx *Tx) *TxBucket[K, V] {
+	return &TxBucket[K, V]{bucket: b, tx: tx}
+}
+
+// Put 存储键值,并维护索引
+func (tb *TxBucket[K, V]) Put(key K, value V) error {
+	return tb.bucket.putTx(tb.tx.tx, key, value, 0)
+}
+
+// PutWithTTL 存储键值并设置存活时长
+func (tb *TxBucket[K, V]) PutWithTTL(key K, value V, ttl time.Duration) error {
+	return tb.bucket.putTx(tb.tx.tx, key, value, ttl)
+}
+
+// Get 获取值,键不存在时返回 ErrKeyNotFound
+func (tb *TxBucket[K, V]) Get(key K) (V, error) {
+	return tb.bucket.getTx(tb.tx.tx, key)
+}
+
+// Has 判断键是否存在
+func (tb *TxBucket[K, V]) Has(key K) (bool, error) {
+	return tb.bucket.hasTx(tb.tx.tx, key)
+}
+
+// Delete 删除键,并维护索引
+func (tb *TxBucket[K, V]) Delete(key K) error {
+	return tb.bucket.deleteTx(tb.tx.tx, key)
+}
+
+// getTx 在事务中读取并解码
+func (s *Store) getTx(tx *bbolt.Tx, bucket, key []byte, value interface{}) error {
+	b, err := bucketFor(tx, bucket, false)
+	if err != nil {
+		return err
+	}
+	data := b.Get(key)
+	if data == nil || isExpired(tx, bucket, key, s.clock.Now()) {
+		return ErrKeyNotFound
+	}
+	return s.codec.Unmarshal(data, value)
+}
+
+// putTx 在事务中编码并写入,同时维护索引和过期时间
+func (b *Bucket[K, V]) putTx(tx *bbolt.Tx, key K, value V, ttl time.Duration) error {
+	data, err := b.codec.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("encoding failed: %w", err)
+	}
+	return b.store.putTx(tx, b.name, b.keys.EncodeKey(key), data, ttl)
+}
+
+// getTx 在事务中读取并解码
+func (b *Bucket[K, V]) getTx(tx *bbolt.Tx, key K) (V, error) {
+	var value V
+	bkt, err := bucketFor(tx, b.name, false)
+	if err != nil {
+		return value, err
+	}
+	k := b.keys.EncodeKey(key)
+	data := bkt.Get(k)
+	if data == nil || isExpired(tx, b.name, k, b.store.clock.Now()) {
+		return value, ErrKeyNotFound
+	}
+	err = b.codec.Unmarshal(data, &value)
+	return value, err
+}
+
+// hasTx 在事务中判断键是否存在且未过期
+func (b *Bucket[K, V]) hasTx(tx *bbolt.Tx, key K) (bool, error) {
+	bkt, err := bucketFor(tx, b.name, false)
+	if err != nil {
+		return false, err
+	}
+	k := b.keys.EncodeKey(key)
+	return bkt.Get(k) != nil && !isExpired(tx, b.name, k, b.store.clock.Now()), nil
+}
+
+// deleteTx 在事务中删除键,并维护索引
+func (b *Bucket[K, V]) deleteTx(tx *bbolt.Tx, key K) error {
+	return b.store.deleteTx(tx, b.name, b.keys.EncodeKey(key))
+}
@@ -0,0 +1,182 @@
+package sqliteutil
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+var (
+	ErrNotInitialized   = errors.New("sqliteutil: database not initialized")
+	ErrAlreadyAttached  = errors.New("sqliteutil: schema already attached")
+	ErrNotAttached      = errors.New("sqliteutil: schema not attached")
+	ErrAttachmentFailed = errors.New("sqliteutil: attach database failed")
+)
+
+// Attachment 附加数据库,查询时通过 schema.table 访问
+type Attachment struct {
+	Schema string // 附加后的库名
+	Path   string // 数据库文件或 DSN
+}
+
+// WithAttach 初始化时附加数据库,连接池中的每个连接都会执行 ATTACH
+func WithAttach(schema, path string) Option {
+	return func(c *Config) {
+		c.Attachments = append(c.Attachments, Attachment{Schema: schema, Path: path})
+	}
+}
+
+// Attach 运行时附加数据库,之后可以跨库查询,如 SELECT * FROM main.users JOIN audit.logs ...。
+// ATTACH 只作用于单个连接,因此会关闭空闲连接,新建的连接自动附加;调用时正在使用的连接(如未关闭的 Rows)
+// 归还连接池时被丢弃,不会以旧的附加状态被复用
+func (m *DBManager) Attach(ctx context.Context, schema, path string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.db == nil {
+		return ErrNotInitialized
+	}
+
+	m.attachMu.Lock()
+	for _, a := range m.attached {
+		if a.Schema == schema {
+			m.attachMu.Unlock()
+			return fmt.Errorf("%w: %s", ErrAlreadyAttached, schema)
+		}
+	}
+	m.attached = append(m.attached, Attachment{Schema: schema, Path: path})
+	m.attachGen.Add(1)
+	m.attachMu.Unlock()
+
+	m.resetPool()
+	if err := m.checkAttached(ctx, schema); err != nil {
+		m.removeAttachment(schema)
+		m.resetPool()
+		return err
+	}
+	return nil
+}
+
+// Detach 分离运行时或初始化时附加的数据库
+func (m *DBManager) Detach(ctx context.Context, schema string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.db == nil {
+		return ErrNotInitialized
+	}
+	if !m.removeAttachment(schema) {
+		return fmt.Errorf("%w: %s", ErrNotAttached, schema)
+	}
+	m.resetPool()
+	return m.db.PingContext(ctx)
+}
+
+// Attached 返回当前附加的数据库
+func (m *DBManager) Attached() []Attachment {
+	m.attachMu.Lock()
+	defer m.attachMu.Unlock()
+	return append([]Attachment(nil), m.attached...)
+}
+
+// connector 绑定连接钩子的连接器,无需全局注册驱动
+type connector struct {
+	dsn    string
+	driver *sqlite3.SQLiteDriver
+	gen    *atomic.Int64 // 附加列表的版本号
+}
+
+// Connect 建立新连接,连接钩子在其中执行。版本号在钩子读取附加列表之前记录,
+// 并发 Attach/Detach 时新连接只会被误判为过期,不会带着旧的附加状态被复用
+func (c *connector) Connect(context.Context) (driver.Conn, error) {
+	gen := c.gen.Load()
+	conn, err := c.driver.Open(c.dsn)
+	if err != nil {
+		return nil, err
+	}
+	return &managedConn{SQLiteConn: conn.(*sqlite3.SQLiteConn), gen: gen, current: c.gen}, nil
+}
+
+// Driver 返回底层驱动
+func (c *connector) Driver() driver.Driver {
+	return c.driver
+}
+
+// managedConn 记录建立时附加列表版本号的连接,版本号过期后由连接池丢弃
+type managedConn struct {
+	*sqlite3.SQLiteConn
+	gen     int64
+	current *atomic.Int64
+}
+
+// IsValid 连接归还连接池时调用,附加列表变化后返回 false
+func (c *managedConn) IsValid() bool {
+	return c.gen == c.current.Load()
+}
+
+// ResetSession 复用空闲连接前调用,附加列表变化后返回 driver.ErrBadConn,连接池会改用新连接
+func (c *managedConn) ResetSession(context.Context) error {
+	if !c.IsValid() {
+		return driver.ErrBadConn
+	}
+	return nil
+}
+
+// openDB 打开数据库,每个新连接建立后执行 hook,gen 变化后旧连接不再复用
+func openDB(dataSourceName string, hook func(*sqlite3.SQLiteConn) error, gen *atomic.Int64) *sql.DB {
+	return sql.OpenDB(&connector{dsn: dataSourceName, driver: &sqlite3.SQLiteDriver{ConnectHook: hook}, gen: gen})
+}
+
+// connectHook 新连接建立后执行 PRAGMA 并附加数据库
+func (m *DBManager) connectHook(conn *sqlite3.SQLiteConn) error {
+	if err := execPragmas(conn, m.pragmas); err != nil {
+		return err
+	}
+	for _, a := range m.Attached() {
+		if _, err := conn.Exec("ATTACH DATABASE ? AS "+QuoteIdent(a.Schema), []driver.Value{a.Path}); err != nil {
+			return fmt.Errorf("%w: %s: %v", ErrAttachmentFailed, a.Schema, err)
+		}
+	}
+	return nil
+}
+
+// resetPool 关闭所有空闲连接,及时释放已分离数据库的文件句柄;正在使用的连接由版本号淘汰
+func (m *DBManager) resetPool() {
+	m.db.SetMaxIdleConns(0)
+	m.db.SetMaxIdleConns(m.config.MaxIdleConns)
+	if m.writer != nil {
+		m.writer.SetMaxIdleConns(0)
+		m.writer.SetMaxIdleConns(1)
+	}
+}
+
+// checkAttached 确认 schema 已附加到连接上
+func (m *DBManager) checkAttached(ctx context.Context, schema string) error {
+	var n int
+	if err := m.db.QueryRowContext(ctx, "SELECT count(*) FROM pragma_database_list WHERE name = ?", schema).Scan(&n); err != nil {
+		return err
+	}
+	if n == 0 {
+		return fmt.Errorf("%w: %s", ErrAttachmentFailed, schema)
+	}
+	return nil
+}
+
+// removeAttachment 从附加列表中移除 schema
+func (m *DBManager) removeAttachment(schema string) bool {
+	m.attachMu.Lock()
+	defer m.attachMu.Unlock()
+	for i, a := range m.attached {
+		if a.Schema == schema {
+			m.attached = append(m.attached[:i], m.attached[i+1:]...)
+			m.attachGen.Add(1)
+			return true
+		}
+	}
+	return false
+}
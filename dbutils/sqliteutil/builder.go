@@ -0,0 +1,745 @@
+package sqliteutil
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// ErrInvalidStatement 构造器参数不完整或标识符非法
+var ErrInvalidStatement = errors.New("sqliteutil: invalid statement")
+
+// Builder SQL构造器,Build 返回带 ? 占位符的语句和绑定参数
+type Builder interface {
+	Build() (string, []interface{}, error)
+}
+
+// Cond WHERE 条件,值始终通过参数绑定
+type Cond interface {
+	build(w *sqlWriter)
+}
+
+// sqlWriter 拼接SQL和参数,记录第一个错误
+type sqlWriter struct {
+	sb   strings.Builder
+	args []interface{}
+	err  error
+}
+
+// compare 比较条件
+type compare struct {
+	column string
+	op     string
+	value  interface{}
+}
+
+// inCond IN / NOT IN 条件
+type inCond struct {
+	column string
+	not    bool
+	values []interface{}
+}
+
+// nullCond IS NULL / IS NOT NULL 条件
+type nullCond struct {
+	column string
+	not    bool
+}
+
+// betweenCond BETWEEN 条件
+type betweenCond struct {
+	column    string
+	low, high interface{}
+}
+
+// group AND / OR 条件组
+type group struct {
+	op    string
+	conds []Cond
+}
+
+// notCond 取反条件
+type notCond struct {
+	cond Cond
+}
+
+// Expression SQL表达式片段,可作为查询列或条件;只应包含常量,不能拼接外部输入
+type Expression struct {
+	sql  string
+	args []interface{}
+}
+
+// Eq 等于
+func Eq(column string, value interface{}) Cond { return compare{column, "=", value} }
+
+// Ne 不等于
+func Ne(column string, value interface{}) Cond { return compare{column, "<>", value} }
+
+// Gt 大于
+func Gt(column string, value interface{}) Cond { return compare{column, ">", value} }
+
+// Gte 大于等于
+func Gte(column string, value interface{}) Cond { return compare{column, ">=", value} }
+
+// Lt 小于
+func Lt(column string, value interface{}) Cond { return compare{column, "<", value} }
+
+// Lte 小于等于
+func Lte(column string, value interface{}) Cond { return compare{column, "<=", value} }
+
+// Like 模糊匹配,pattern 作为参数绑定,% 和 _ 需要调用方自行转义
+func Like(column, pattern string) Cond { return compare{column, "LIKE", pattern} }
+
+// In 属于,values 为空时条件恒为假
+func In(column string, values ...interface{}) Cond { return inCond{column: column, values: values} }
+
+// NotIn 不属于,values 为空时条件恒为真
+func NotIn(column string, values ...interface{}) Cond {
+	return inCond{column: column, not: true, values: values}
+}
+
+// IsNull 为空
+func IsNull(column string) Cond { return nullCond{column: column} }
+
+// NotNull 不为空
+func NotNull(column string) Cond { return nullCond{column: column, not: true} }
+
+// Between 在 [low, high] 区间内
+func Between(column string, low, high interface{}) Cond { return betweenCond{column, low, high} }
+
+// And 所有条件同时成立
+func And(conds ...Cond) Cond { return group{"AND", conds} }
+
+// Or 任一条件成立
+func Or(conds ...Cond) Cond { return group{"OR", conds} }
+
+// Not 条件取反
+func Not(cond Cond) Cond { return notCond{cond} }
+
+// Raw 原始条件片段,使用 ? 占位符绑定参数,占位符数量必须与参数一致;sql 只能是常量,不能拼接外部输入
+func Raw(sql string, args ...interface{}) Cond { return Expr(sql, args...) }
+
+// Expr 创建表达式,如 Expr("count(*)")、Expr("count(*) > ?", 1),规则同 Raw;
+// 用于 SelectBuilder.SelectExpr 和 Having 等不能只用列名表达的场景
+func Expr(sql string, args ...interface{}) Expression { return Expression{sql, args} }
+
+// build 生成比较条件
+func (c compare) build(w *sqlWriter) {
+	w.ident(c.column)
+	w.sb.WriteString(" " + c.op + " ")
+	w.bind(c.value)
+}
+
+// build 生成 IN 条件
+func (c inCond) build(w *sqlWriter) {
+	if len(c.values) == 0 {
+		if c.not {
+			w.sb.WriteString("1 = 1")
+		} else {
+			w.sb.WriteString("1 = 0")
+		}
+		return
+	}
+	w.ident(c.column)
+	if c.not {
+		w.sb.WriteString(" NOT")
+	}
+	w.sb.WriteString(" IN (")
+	for i, v := range c.values {
+		if i > 0 {
+			w.sb.WriteString(", ")
+		}
+		w.bind(v)
+	}
+	w.sb.WriteString(")")
+}
+
+// build 生成空值条件
+func (c nullCond) build(w *sqlWriter) {
+	w.ident(c.column)
+	if c.not {
+		w.sb.WriteString(" IS NOT NULL")
+	} else {
+		w.sb.WriteString(" IS NULL")
+	}
+}
+
+// build 生成区间条件
+func (c betweenCond) build(w *sqlWriter) {
+	w.ident(c.column)
+	w.sb.WriteString(" BETWEEN ")
+	w.bind(c.low)
+	w.sb.WriteString(" AND ")
+	w.bind(c.high)
+}
+
+// build 生成条件组,空组恒为真
+func (g group) build(w *sqlWriter) {
+	if len(g.conds) == 0 {
+		w.sb.WriteString("1 = 1")
+		return
+	}
+	w.sb.WriteString("(")
+	for i, c := range g.conds {
+		if i > 0 {
+			w.sb.WriteString(" " + g.op + " ")
+		}
+		c.build(w)
+	}
+	w.sb.WriteString(")")
+}
+
+// build 生成取反条件
+func (c notCond) build(w *sqlWriter) {
+	w.sb.WriteString("NOT (")
+	c.cond.build(w)
+	w.sb.WriteString(")")
+}
+
+// build 生成表达式,外加括号
+func (c Expression) build(w *sqlWriter) {
+	if strings.Count(c.sql, "?") != len(c.args) {
+		w.fail("placeholder count of %q does not match %d args", c.sql, len(c.args))
+		return
+	}
+	w.sb.WriteString("(" + c.sql + ")")
+	w.args = append(w.args, c.args...)
+}
+
+// SelectBuilder 查询构造器
+type SelectBuilder struct {
+	columns []selectColumn
+	table   string
+	joins   []join
+	where   []Cond
+	groupBy []string
+	having  []Cond
+	orderBy []order
+	limit   int
+	offset  int
+}
+
+// selectColumn 查询列,expr 非空时为表达式列
+type selectColumn struct {
+	name  string
+	expr  *Expression
+	alias string
+}
+
+// join 连接子句
+type join struct {
+	kind        string
+	table       string
+	left, right string
+}
+
+// order 排序子句
+type order struct {
+	column string
+	desc   bool
+}
+
+// Select 创建查询构造器,不传列时查询 *;列名支持 "t.col" 和 "col AS alias",聚合等表达式使用 SelectExpr
+func Select(columns ...string) *SelectBuilder {
+	b := &SelectBuilder{limit: -1}
+	for _, c := range columns {
+		b.columns = append(b.columns, selectColumn{name: c})
+	}
+	return b
+}
+
+// SelectExpr 添加表达式列,alias 为空时不设置别名
+func (b *SelectBuilder) SelectExpr(e Expression, alias string) *SelectBuilder {
+	b.columns = append(b.columns, selectColumn{expr: &e, alias: alias})
+	return b
+}
+
+// From 设置表名,支持 "table AS alias"
+func (b *SelectBuilder) From(table string) *SelectBuilder {
+	b.table = table
+	return b
+}
+
+// Join 内连接,on 条件为 left = right 两列相等
+func (b *SelectBuilder) Join(table, left, right string) *SelectBuilder {
+	b.joins = append(b.joins, join{"JOIN", table, left, right})
+	return b
+}
+
+// LeftJoin 左连接
+func (b *SelectBuilder) LeftJoin(table, left, right string) *SelectBuilder {
+	b.joins = append(b.joins, join{"LEFT JOIN", table, left, right})
+	return b
+}
+
+// Where 添加条件,多次调用之间为 AND 关系
+func (b *SelectBuilder) Where(conds ...Cond) *SelectBuilder {
+	b.where = append(b.where, conds...)
+	return b
+}
+
+// GroupBy 分组
+func (b *SelectBuilder) GroupBy(columns ...string) *SelectBuilder {
+	b.groupBy = append(b.groupBy, columns...)
+	return b
+}
+
+// Having 分组过滤条件
+func (b *SelectBuilder) Having(conds ...Cond) *SelectBuilder {
+	b.having = append(b.having, conds...)
+	return b
+}
+
+// OrderBy 升序排序
+func (b *SelectBuilder) OrderBy(columns ...string) *SelectBuilder {
+	for _, c := range columns {
+		b.orderBy = append(b.orderBy, order{column: c})
+	}
+	return b
+}
+
+// OrderByDesc 降序排序
+func (b *SelectBuilder) OrderByDesc(columns ...string) *SelectBuilder {
+	for _, c := range columns {
+		b.orderBy = append(b.orderBy, order{column: c, desc: true})
+	}
+	return b
+}
+
+// Limit 限制返回行数
+func (b *SelectBuilder) Limit(n int) *SelectBuilder {
+	b.limit = n
+	return b
+}
+
+// Offset 跳过的行数,未设置 Limit 时不限制行数
+func (b *SelectBuilder) Offset(n int) *SelectBuilder {
+	b.offset = n
+	return b
+}
+
+// Build 生成查询语句
+func (b *SelectBuilder) Build() (string, []interface{}, error) {
+	w := &sqlWriter{}
+	if b.table == "" {
+		return "", nil, fmt.Errorf("%w: select without table", ErrInvalidStatement)
+	}
+	w.sb.WriteString("SELECT ")
+	if len(b.columns) == 0 {
+		w.sb.WriteString("*")
+	}
+	for i, c := range b.columns {
+		if i > 0 {
+			w.sb.WriteString(", ")
+		}
+		if c.expr == nil {
+			w.column(c.name)
+			continue
+		}
+		c.expr.build(w)
+		if c.alias != "" {
+			w.sb.WriteString(" AS ")
+			w.ident(c.alias)
+		}
+	}
+	w.sb.WriteString(" FROM ")
+	w.column(b.table)
+	for _, j := range b.joins {
+		w.sb.WriteString(" " + j.kind + " ")
+		w.column(j.table)
+		w.sb.WriteString(" ON ")
+		w.ident(j.left)
+		w.sb.WriteString(" = ")
+		w.ident(j.right)
+	}
+	w.where(" WHERE ", b.where)
+	if len(b.groupBy) > 0 {
+		w.sb.WriteString(" GROUP BY ")
+		w.idents(b.groupBy)
+	}
+	w.where(" HAVING ", b.having)
+	for i, o := range b.orderBy {
+		if i == 0 {
+			w.sb.WriteString(" ORDER BY ")
+		} else {
+			w.sb.WriteString(", ")
+		}
+		w.ident(o.column)
+		if o.desc {
+			w.sb.WriteString(" DESC")
+		}
+	}
+	if b.limit >= 0 || b.offset > 0 {
+		w.sb.WriteString(" LIMIT ")
+		w.bind(b.limit)
+	}
+	if b.offset > 0 {
+		w.sb.WriteString(" OFFSET ")
+		w.bind(b.offset)
+	}
+	return w.result()
+}
+
+// InsertBuilder 插入构造器
+type InsertBuilder struct {
+	table    string
+	columns  []string
+	rows     [][]interface{}
+	verb     string
+	conflict *conflictClause
+}
+
+// conflictClause ON CONFLICT 子句
+type conflictClause struct {
+	target []string
+	update []string
+}
+
+// Insert 创建插入构造器
+func Insert(table string) *InsertBuilder {
+	return &InsertBuilder{table: table, verb: "INSERT"}
+}
+
+// OrReplace 冲突时替换已有行
+func (b *InsertBuilder) OrReplace() *InsertBuilder {
+	b.verb = "INSERT OR REPLACE"
+	return b
+}
+
+// OrIgnore 冲突时忽略
+func (b *InsertBuilder) OrIgnore() *InsertBuilder {
+	b.verb = "INSERT OR IGNORE"
+	return b
+}
+
+// OnConflict 设置冲突目标列,配合 DoUpdate 或 DoNothing 生成 ON CONFLICT 子句
+func (b *InsertBuilder) OnConflict(columns ...string) *InsertBuilder {
+	b.conflict = &conflictClause{target: columns}
+	return b
+}
+
+// DoUpdate 冲突时用新值更新指定列,即 SET 列 = excluded.列
+func (b *InsertBuilder) DoUpdate(columns ...string) *InsertBuilder {
+	if b.conflict == nil {
+		b.conflict = &conflictClause{}
+	}
+	b.conflict.update = columns
+	return b
+}
+
+// DoNothing 冲突时跳过该行
+func (b *InsertBuilder) DoNothing() *InsertBuilder {
+	if b.conflict == nil {
+		b.conflict = &conflictClause{}
+	}
+	b.conflict.update = nil
+	return b
+}
+
+// Columns 设置列名
+func (b *InsertBuilder) Columns(columns ...string) *InsertBuilder {
+	b.columns = columns
+	return b
+}
+
+// Values 添加一行值,多次调用生成多行插入
+func (b *InsertBuilder) Values(values ...interface{}) *InsertBuilder {
+	b.rows = append(b.rows, values)
+	return b
+}
+
+// SetMap 按 map 设置列和单行值,列按名称排序保证语句稳定
+func (b *InsertBuilder) SetMap(data map[string]interface{}) *InsertBuilder {
+	columns, values := sortedMap(data)
+	b.columns = columns
+	b.rows = [][]interface{}{values}
+	return b
+}
+
+// Build 生成插入语句
+func (b *InsertBuilder) Build() (string, []interface{}, error) {
+	w := &sqlWriter{}
+	if b.table == "" || len(b.columns) == 0 || len(b.rows) == 0 {
+		return "", nil, fmt.Errorf("%w: insert requires table, columns and values", ErrInvalidStatement)
+	}
+	w.sb.WriteString(b.verb + " INTO ")
+	w.ident(b.table)
+	w.sb.WriteString(" (")
+	w.idents(b.columns)
+	w.sb.WriteString(") VALUES ")
+	for i, row := range b.rows {
+		if len(row) != len(b.columns) {
+			return "", nil, fmt.Errorf("%w: row %d has %d values, want %d", ErrInvalidStatement, i, len(row), len(b.columns))
+		}
+		if i > 0 {
+			w.sb.WriteString(", ")
+		}
+		w.sb.WriteString("(")
+		for j, v := range row {
+			if j > 0 {
+				w.sb.WriteString(", ")
+			}
+			w.bind(v)
+		}
+		w.sb.WriteString(")")
+	}
+	if c := b.conflict; c != nil {
+		w.sb.WriteString(" ON CONFLICT")
+		if len(c.target) > 0 {
+			w.sb.WriteString(" (")
+			w.idents(c.target)
+			w.sb.WriteString(")")
+		} else if len(c.update) > 0 {
+			return "", nil, fmt.Errorf("%w: do update requires conflict columns", ErrInvalidStatement)
+		}
+		if len(c.update) == 0 {
+			w.sb.WriteString(" DO NOTHING")
+		} else {
+			w.sb.WriteString(" DO UPDATE SET ")
+			for i, col := range c.update {
+				if i > 0 {
+					w.sb.WriteString(", ")
+				}
+				w.ident(col)
+				w.sb.WriteString(" = excluded.")
+				w.ident(col)
+			}
+		}
+	}
+	return w.result()
+}
+
+// UpdateBuilder 更新构造器
+type UpdateBuilder struct {
+	table   string
+	columns []string
+	values  []interface{}
+	where   []Cond
+}
+
+// Update 创建更新构造器
+func Update(table string) *UpdateBuilder {
+	return &UpdateBuilder{table: table}
+}
+
+// Set 设置列值
+func (b *UpdateBuilder) Set(column string, value interface{}) *UpdateBuilder {
+	b.columns = append(b.columns, column)
+	b.values = append(b.values, value)
+	return b
+}
+
+// SetMap 按 map 设置列值,列按名称排序
+func (b *UpdateBuilder) SetMap(data map[string]interface{}) *UpdateBuilder {
+	columns, values := sortedMap(data)
+	b.columns = append(b.columns, columns...)
+	b.values = append(b.values, values...)
+	return b
+}
+
+// Where 添加条件,多次调用之间为 AND 关系
+func (b *UpdateBuilder) Where(conds ...Cond) *UpdateBuilder {
+	b.where = append(b.where, conds...)
+	return b
+}
+
+// Build 生成更新语句
+func (b *UpdateBuilder) Build() (string, []interface{}, error) {
+	w := &sqlWriter{}
+	if b.table == "" || len(b.columns) == 0 {
+		return "", nil, fmt.Errorf("%w: update requires table and columns", ErrInvalidStatement)
+	}
+	w.sb.WriteString("UPDATE ")
+	w.ident(b.table)
+	w.sb.WriteString(" SET ")
+	for i, c := range b.columns {
+		if i > 0 {
+			w.sb.WriteString(", ")
+		}
+		w.ident(c)
+		w.sb.WriteString(" = ")
+		w.bind(b.values[i])
+	}
+	w.where(" WHERE ", b.where)
+	return w.result()
+}
+
+// DeleteBuilder 删除构造器
+type DeleteBuilder struct {
+	table string
+	where []Cond
+}
+
+// DeleteFrom 创建删除构造器
+func DeleteFrom(table string) *DeleteBuilder {
+	return &DeleteBuilder{table: table}
+}
+
+// Where 添加条件,多次调用之间为 AND 关系
+func (b *DeleteBuilder) Where(conds ...Cond) *DeleteBuilder {
+	b.where = append(b.where, conds...)
+	return b
+}
+
+// Build 生成删除语句
+func (b *DeleteBuilder) Build() (string, []interface{}, error) {
+	w := &sqlWriter{}
+	if b.table == "" {
+		return "", nil, fmt.Errorf("%w: delete without table", ErrInvalidStatement)
+	}
+	w.sb.WriteString("DELETE FROM ")
+	w.ident(b.table)
+	w.where(" WHERE ", b.where)
+	return w.result()
+}
+
+// ExecStmt 执行构造器生成的写语句
+func (m *DBManager) ExecStmt(ctx context.Context, b Builder) (sql.Result, error) {
+	query, args, err := b.Build()
+	if err != nil {
+		return nil, err
+	}
+	return m.Execute(ctx, query, args...)
+}
+
+// QueryStmt 执行构造器生成的查询
+func (m *DBManager) QueryStmt(ctx context.Context, b Builder) (*sql.Rows, error) {
+	query, args, err := b.Build()
+	if err != nil {
+		return nil, err
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.db == nil {
+		return nil, errors.New("database not initialized")
+	}
+	return m.db.QueryContext(ctx, query, args...)
+}
+
+// QueryRowStmt 执行构造器生成的单行查询
+func (m *DBManager) QueryRowStmt(ctx context.Context, b Builder) (*sql.Row, error) {
+	query, args, err := b.Build()
+	if err != nil {
+		return nil, err
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.db == nil {
+		return nil, errors.New("database not initialized")
+	}
+	return m.db.QueryRowContext(ctx, query, args...), nil
+}
+
+// QuoteIdent 为标识符加反引号,"t.col" 分段引用,* 保持不变。
+// 不使用双引号: SQLite 会把找不到列的双引号名称当作字符串常量,拼写错误的列名不会报错
+func QuoteIdent(name string) string {
+	parts := strings.Split(name, ".")
+	for i, p := range parts {
+		if p == "*" && i == len(parts)-1 {
+			continue
+		}
+		parts[i] = "`" + strings.ReplaceAll(p, "`", "``") + "`"
+	}
+	return strings.Join(parts, ".")
+}
+
+// ident 写入标识符,只接受字母、数字和下划线组成的名称,可用 "." 分段,末段可为 *
+func (w *sqlWriter) ident(name string) {
+	parts := strings.Split(name, ".")
+	for i, p := range parts {
+		if !(p == "*" && i == len(parts)-1) && !plainIdent(p) {
+			w.fail("invalid identifier %q", name)
+			return
+		}
+	}
+	w.sb.WriteString(QuoteIdent(name))
+}
+
+// plainIdent 判断是否为不以数字开头的字母、数字、下划线序列
+func plainIdent(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, r := range s {
+		if r != '_' && !unicode.IsLetter(r) && (i == 0 || !unicode.IsDigit(r)) {
+			return false
+		}
+	}
+	return true
+}
+
+// idents 写入逗号分隔的标识符
+func (w *sqlWriter) idents(names []string) {
+	for i, n := range names {
+		if i > 0 {
+			w.sb.WriteString(", ")
+		}
+		w.ident(n)
+	}
+}
+
+// column 写入查询列或表名,支持 "name AS alias"
+func (w *sqlWriter) column(c string) {
+	if i := strings.Index(strings.ToUpper(c), " AS "); i > 0 {
+		w.ident(strings.TrimSpace(c[:i]))
+		w.sb.WriteString(" AS ")
+		w.ident(strings.TrimSpace(c[i+4:]))
+		return
+	}
+	w.ident(c)
+}
+
+// bind 写入占位符并记录参数
+func (w *sqlWriter) bind(v interface{}) {
+	w.sb.WriteString("?")
+	w.args = append(w.args, v)
+}
+
+// where 写入以 AND 连接的条件
+func (w *sqlWriter) where(keyword string, conds []Cond) {
+	if len(conds) == 0 {
+		return
+	}
+	w.sb.WriteString(keyword)
+	for i, c := range conds {
+		if i > 0 {
+			w.sb.WriteString(" AND ")
+		}
+		c.build(w)
+	}
+}
+
+// fail 记录第一个错误
+func (w *sqlWriter) fail(format string, args ...interface{}) {
+	if w.err == nil {
+		w.err = fmt.Errorf("%w: "+format, append([]interface{}{ErrInvalidStatement}, args...)...)
+	}
+}
+
+// result 返回语句、参数和错误
+func (w *sqlWriter) result() (string, []interface{}, error) {
+	if w.err != nil {
+		return "", nil, w.err
+	}
+	return w.sb.String(), w.args, nil
+}
+
+// sortedMap 按键排序拆分 map
+func sortedMap(data map[string]interface{}) ([]string, []interface{}) {
+	columns := make([]string, 0, len(data))
+	for c := range data {
+		columns = append(columns, c)
+	}
+	sort.Strings(columns)
+	values := make([]interface{}, len(columns))
+	for i, c := range columns {
+		values[i] = data[c]
+	}
+	return columns, values
+}
@@ -0,0 +1,604 @@
+package sqliteutil
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+var (
+	ErrFTS5Unavailable = errors.New("sqliteutil: FTS5 is not compiled in, build with -tags sqlite_fts5")
+	ErrUnknownColumn   = errors.New("sqliteutil: unknown full-text column")
+)
+
+// cjkSeparator 插入到中日韩文字之间的零宽空格,unicode61 分词器视其为分隔符
+const cjkSeparator = '\u200b'
+
+// FTS 全文检索表,go-sqlite3 需要以 -tags sqlite_fts5 编译才包含 FTS5
+type FTS struct {
+	db        *DBManager
+	name      string
+	columns   []string
+	content   string
+	rowid     string
+	tokenizer string
+	prefix    []int
+	cjk       bool
+}
+
+// FTSOption 全文检索表配置选项
+type FTSOption func(*FTS)
+
+// WithContentTable 关联已有的内容表,通过触发器在内容表增删改时同步索引,
+// 全文检索表的列名需要与内容表一致,rowid 为内容表的整数主键列。与 WithCJK 同时使用时不创建触发器,见 WithCJK
+func WithContentTable(table, rowid string) FTSOption {
+	return func(f *FTS) {
+		f.content = table
+		f.rowid = rowid
+	}
+}
+
+// WithTokenizer 设置分词器,如 "unicode61 remove_diacritics 2"、"porter unicode61"、"trigram"
+func WithTokenizer(tokenizer string) FTSOption {
+	return func(f *FTS) {
+		f.tokenizer = tokenizer
+	}
+}
+
+// WithPrefixIndex 为指定长度的前缀建立索引,加速 abc* 形式的前缀查询
+func WithPrefixIndex(lengths ...int) FTSOption {
+	return func(f *FTS) {
+		f.prefix = lengths
+	}
+}
+
+// WithCJK 按字切分中日韩文字,使中文可以按任意长度的词检索;查询中的中文会自动转换为短语,
+// 返回的文本会去掉切分时插入的分隔符。切分在 Go 中完成,关联内容表时索引单独保存一份切分后的文本,
+// 不在内容表上创建触发器,内容表可以被其他工具正常写入;内容表变化后需调用 Sync 或 Rebuild 同步索引
+func WithCJK() FTSOption {
+	return func(f *FTS) {
+		f.cjk = true
+	}
+}
+
+// SearchResult 检索结果
+type SearchResult struct {
+	RowID     int64
+	Rank      float64           // bm25 分数,越小越相关
+	Values    map[string]string // 各列的内容
+	Highlight string            // WithHighlight 指定列的高亮文本
+	Snippet   string            // WithSnippet 指定列的摘要
+}
+
+// searchConfig 检索配置
+type searchConfig struct {
+	highlight string
+	snippet   string
+	tokens    int
+	open      string
+	close     string
+	ellipsis  string
+	weights   []float64
+	limit     int
+	offset    int
+}
+
+// SearchOption 检索配置选项
+type SearchOption func(*searchConfig)
+
+// WithHighlight 返回指定列高亮匹配词后的完整文本
+func WithHighlight(column string) SearchOption {
+	return func(c *searchConfig) {
+		c.highlight = column
+	}
+}
+
+// WithSnippet 返回指定列包含匹配词的摘要,tokens 为摘要的最大词数(1-64)
+func WithSnippet(column string, tokens int) SearchOption {
+	return func(c *searchConfig) {
+		c.snippet = column
+		c.tokens = tokens
+	}
+}
+
+// WithMarkers 设置高亮标记和摘要省略符,默认 <b>、</b> 和 ...
+func WithMarkers(open, close, ellipsis string) SearchOption {
+	return func(c *searchConfig) {
+		c.open = open
+		c.close = close
+		c.ellipsis = ellipsis
+	}
+}
+
+// WithWeights 设置 bm25 中各列的权重,按列顺序,未设置的列权重为1
+func WithWeights(weights ...float64) SearchOption {
+	return func(c *searchConfig) {
+		c.weights = weights
+	}
+}
+
+// WithSearchLimit 设置返回的结果数量和偏移量,默认返回前20条
+func WithSearchLimit(limit, offset int) SearchOption {
+	return func(c *searchConfig) {
+		c.limit = limit
+		c.offset = offset
+	}
+}
+
+// FTS5Enabled 检查 SQLite 是否编译了 FTS5
+func (m *DBManager) FTS5Enabled(ctx context.Context) (bool, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.db == nil {
+		return false, errors.New("database not initialized")
+	}
+	var enabled bool
+	err := m.db.QueryRowContext(ctx, "SELECT sqlite_compileoption_used('ENABLE_FTS5')").Scan(&enabled)
+	return enabled, err
+}
+
+// NewFTS 创建 FTS5 全文检索表,已存在时直接返回。关联内容表时会索引已有数据,非 CJK 模式下创建同步触发器
+func NewFTS(ctx context.Context, m *DBManager, name string, columns []string, opts ...FTSOption) (*FTS, error) {
+	f := &FTS{db: m, name: name, columns: columns}
+	for _, opt := range opts {
+		opt(f)
+	}
+	if name == "" || len(columns) == 0 {
+		return nil, fmt.Errorf("%w: full-text table requires name and columns", ErrInvalidStatement)
+	}
+	if f.content != "" && f.rowid == "" {
+		return nil, fmt.Errorf("%w: content table requires rowid column", ErrInvalidStatement)
+	}
+	if ok, err := m.FTS5Enabled(ctx); err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, ErrFTS5Unavailable
+	}
+
+	exists, err := m.TableExists(ctx, name)
+	if err != nil || exists {
+		return f, err
+	}
+	err = m.Transaction(ctx, func(tx *sql.Tx) error {
+		for _, stmt := range f.createStatements() {
+			if _, err := tx.ExecContext(ctx, stmt); err != nil {
+				return fmt.Errorf("create full-text table failed: %w", err)
+			}
+		}
+		if f.content != "" && f.cjk {
+			return f.populate(ctx, tx)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Name 返回表名
+func (f *FTS) Name() string {
+	return f.name
+}
+
+// Index 写入或替换独立全文检索表中 rowid 对应的文档;关联内容表时由触发器或 Sync 维护,无需调用
+func (f *FTS) Index(ctx context.Context, rowid int64, values map[string]string) error {
+	if f.content != "" {
+		return fmt.Errorf("%w: %s is synced from %s", ErrInvalidStatement, f.name, f.content)
+	}
+	row := make([]interface{}, len(f.columns))
+	for i, c := range f.columns {
+		row[i] = values[c]
+	}
+	for c := range values {
+		if f.columnIndex(c) < 0 {
+			return fmt.Errorf("%w: %s", ErrUnknownColumn, c)
+		}
+	}
+	return f.db.Transaction(ctx, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, "DELETE FROM "+QuoteIdent(f.name)+" WHERE rowid = ?", rowid); err != nil {
+			return err
+		}
+		_, err := tx.ExecContext(ctx, f.insertStatement(), f.segmentRow(rowid, row)...)
+		return err
+	})
+}
+
+// Remove 删除独立全文检索表中 rowid 对应的文档
+func (f *FTS) Remove(ctx context.Context, rowid int64) error {
+	if f.content != "" {
+		return fmt.Errorf("%w: %s is synced from %s", ErrInvalidStatement, f.name, f.content)
+	}
+	_, err := f.db.Execute(ctx, "DELETE FROM "+QuoteIdent(f.name)+" WHERE rowid = ?", rowid)
+	return err
+}
+
+// Sync 从内容表重新索引 rowids 对应的行,行已删除时移除索引。用于 WithCJK 关联内容表的情况,
+// 其他模式由触发器同步,调用时直接返回
+func (f *FTS) Sync(ctx context.Context, rowids ...int64) error {
+	if f.content == "" {
+		return fmt.Errorf("%w: %s has no content table", ErrInvalidStatement, f.name)
+	}
+	if !f.cjk || len(rowids) == 0 {
+		return nil
+	}
+	query := fmt.Sprintf("SELECT %s, %s FROM %s WHERE %s = ?",
+		QuoteIdent(f.rowid), f.columnList(), QuoteIdent(f.content), QuoteIdent(f.rowid))
+	return f.db.Transaction(ctx, func(tx *sql.Tx) error {
+		for _, rowid := range rowids {
+			if _, err := tx.ExecContext(ctx, "DELETE FROM "+QuoteIdent(f.name)+" WHERE rowid = ?", rowid); err != nil {
+				return err
+			}
+			rows, err := tx.QueryContext(ctx, query, rowid)
+			if err != nil {
+				return err
+			}
+			if err := f.indexRows(ctx, tx, rows); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Search 执行 MATCH 查询,按 bm25 相关度排序。query 使用 FTS5 查询语法,如 "sqlite AND 检索"、"data*"
+func (f *FTS) Search(ctx context.Context, query string, opts ...SearchOption) ([]SearchResult, error) {
+	c := &searchConfig{tokens: 16, open: "<b>", close: "</b>", ellipsis: "...", limit: 20}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	table := QuoteIdent(f.name)
+	var sb strings.Builder
+	var args []interface{}
+	sb.WriteString("SELECT rowid, " + f.bm25(c.weights))
+	if c.highlight != "" {
+		i := f.columnIndex(c.highlight)
+		if i < 0 {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownColumn, c.highlight)
+		}
+		fmt.Fprintf(&sb, ", highlight(%s, %d, ?, ?)", table, i)
+		args = append(args, c.open, c.close)
+	}
+	if c.snippet != "" {
+		i := f.columnIndex(c.snippet)
+		if i < 0 {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownColumn, c.snippet)
+		}
+		fmt.Fprintf(&sb, ", snippet(%s, %d, ?, ?, ?, %d)", table, i, min(max(c.tokens, 1), 64))
+		args = append(args, c.open, c.close, c.ellipsis)
+	}
+	for _, col := range f.columns {
+		sb.WriteString(", " + QuoteIdent(col))
+	}
+	sb.WriteString(" FROM " + table + " WHERE " + table + " MATCH ? ORDER BY 2 LIMIT ? OFFSET ?")
+	args = append(args, f.matchQuery(query), c.limit, c.offset)
+
+	f.db.mu.RLock()
+	defer f.db.mu.RUnlock()
+	if f.db.db == nil {
+		return nil, errors.New("database not initialized")
+	}
+	rows, err := f.db.db.QueryContext(ctx, sb.String(), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []SearchResult
+	for rows.Next() {
+		var r SearchResult
+		var highlight, snippet sql.NullString
+		values := make([]sql.NullString, len(f.columns))
+		dest := []interface{}{&r.RowID, &r.Rank}
+		if c.highlight != "" {
+			dest = append(dest, &highlight)
+		}
+		if c.snippet != "" {
+			dest = append(dest, &snippet)
+		}
+		for i := range values {
+			dest = append(dest, &values[i])
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		r.Highlight = f.unsegment(highlight.String)
+		r.Snippet = f.unsegment(snippet.String)
+		r.Values = make(map[string]string, len(f.columns))
+		for i, col := range f.columns {
+			r.Values[col] = f.unsegment(values[i].String)
+		}
+		results = append(results, r)
+	}
+	return results, rows.Err()
+}
+
+// Count 返回匹配 query 的文档数量
+func (f *FTS) Count(ctx context.Context, query string) (int64, error) {
+	f.db.mu.RLock()
+	defer f.db.mu.RUnlock()
+
+	if f.db.db == nil {
+		return 0, errors.New("database not initialized")
+	}
+	table := QuoteIdent(f.name)
+	var n int64
+	err := f.db.db.QueryRowContext(ctx, "SELECT count(*) FROM "+table+" WHERE "+table+" MATCH ?", f.matchQuery(query)).Scan(&n)
+	return n, err
+}
+
+// Rebuild 重建索引,用于导入数据或修改内容表后绕过触发器的情况
+func (f *FTS) Rebuild(ctx context.Context) error {
+	return f.db.Transaction(ctx, func(tx *sql.Tx) error {
+		if f.content != "" && f.cjk {
+			if _, err := tx.ExecContext(ctx, "DELETE FROM "+QuoteIdent(f.name)); err != nil {
+				return err
+			}
+			return f.populate(ctx, tx)
+		}
+		_, err := tx.ExecContext(ctx, f.command("rebuild"))
+		return err
+	})
+}
+
+// Optimize 合并索引段,减小索引并加快查询
+func (f *FTS) Optimize(ctx context.Context) error {
+	_, err := f.db.Execute(ctx, f.command("optimize"))
+	return err
+}
+
+// Drop 删除全文检索表和同步触发器
+func (f *FTS) Drop(ctx context.Context) error {
+	return f.db.Transaction(ctx, func(tx *sql.Tx) error {
+		for _, suffix := range []string{"_ai", "_ad", "_au"} {
+			if _, err := tx.ExecContext(ctx, "DROP TRIGGER IF EXISTS "+QuoteIdent(f.name+suffix)); err != nil {
+				return err
+			}
+		}
+		_, err := tx.ExecContext(ctx, "DROP TABLE IF EXISTS "+QuoteIdent(f.name))
+		return err
+	})
+}
+
+// createStatements 生成建表、触发器和初始索引语句,CJK 模式下只建表,由 populate 写入切分后的文本
+func (f *FTS) createStatements() []string {
+	table := QuoteIdent(f.name)
+	defs := make([]string, 0, len(f.columns)+4)
+	for _, c := range f.columns {
+		defs = append(defs, QuoteIdent(c))
+	}
+	// 切分后的文本与内容表不一致,CJK 模式下索引单独保存内容
+	external := f.content != "" && !f.cjk
+	if external {
+		defs = append(defs, "content="+sqlString(f.content), "content_rowid="+sqlString(f.rowid))
+	}
+	if f.tokenizer != "" {
+		defs = append(defs, "tokenize="+sqlString(f.tokenizer))
+	}
+	if len(f.prefix) > 0 {
+		lengths := make([]string, len(f.prefix))
+		for i, n := range f.prefix {
+			lengths[i] = fmt.Sprint(n)
+		}
+		defs = append(defs, "prefix="+sqlString(strings.Join(lengths, " ")))
+	}
+	stmts := []string{"CREATE VIRTUAL TABLE " + table + " USING fts5(" + strings.Join(defs, ", ") + ")"}
+	if !external {
+		return stmts
+	}
+
+	rowid, colList := QuoteIdent(f.rowid), f.columnList()
+	insert := fmt.Sprintf("INSERT INTO %s(rowid, %s) VALUES (new.%s, %s);", table, colList, rowid, f.rowValues("new"))
+	remove := fmt.Sprintf("INSERT INTO %s(%s, rowid, %s) VALUES ('delete', old.%s, %s);", table, table, colList, rowid, f.rowValues("old"))
+	trigger := func(suffix, event, body string) string {
+		return fmt.Sprintf("CREATE TRIGGER %s AFTER %s ON %s BEGIN %s END", QuoteIdent(f.name+suffix), event, QuoteIdent(f.content), body)
+	}
+	return append(stmts,
+		trigger("_ai", "INSERT", insert),
+		trigger("_ad", "DELETE", remove),
+		trigger("_au", "UPDATE", remove+" "+insert),
+		f.command("rebuild"),
+	)
+}
+
+// populate 读取内容表的所有行,在 Go 中切分后写入索引
+func (f *FTS) populate(ctx context.Context, tx *sql.Tx) error {
+	rows, err := tx.QueryContext(ctx, fmt.Sprintf("SELECT %s, %s FROM %s", QuoteIdent(f.rowid), f.columnList(), QuoteIdent(f.content)))
+	if err != nil {
+		return err
+	}
+	return f.indexRows(ctx, tx, rows)
+}
+
+// indexRows 将内容表的查询结果(rowid 和各列)切分后写入索引,完成后关闭 rows
+func (f *FTS) indexRows(ctx context.Context, tx *sql.Tx, rows *sql.Rows) error {
+	defer rows.Close()
+	stmt, err := tx.PrepareContext(ctx, f.insertStatement())
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for rows.Next() {
+		var rowid int64
+		values := make([]sql.NullString, len(f.columns))
+		dest := []interface{}{&rowid}
+		for i := range values {
+			dest = append(dest, &values[i])
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return err
+		}
+		row := make([]interface{}, len(values))
+		for i, v := range values {
+			if v.Valid {
+				row[i] = v.String
+			}
+		}
+		if _, err := stmt.ExecContext(ctx, f.segmentRow(rowid, row)...); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// insertStatement 写入一行索引的语句,参数为 rowid 和各列
+func (f *FTS) insertStatement() string {
+	return fmt.Sprintf("INSERT INTO %s(rowid, %s) VALUES (?%s)",
+		QuoteIdent(f.name), f.columnList(), strings.Repeat(", ?", len(f.columns)))
+}
+
+// segmentRow 生成 insertStatement 的参数,CJK 模式下切分文本,NULL 原样保留
+func (f *FTS) segmentRow(rowid int64, values []interface{}) []interface{} {
+	args := []interface{}{rowid}
+	for _, v := range values {
+		if s, ok := v.(string); ok {
+			v = f.segment(s)
+		}
+		args = append(args, v)
+	}
+	return args
+}
+
+// columnList 逗号分隔的列名
+func (f *FTS) columnList() string {
+	cols := make([]string, len(f.columns))
+	for i, c := range f.columns {
+		cols[i] = QuoteIdent(c)
+	}
+	return strings.Join(cols, ", ")
+}
+
+// rowValues 触发器中 new/old 行的列值
+func (f *FTS) rowValues(row string) string {
+	values := make([]string, len(f.columns))
+	for i, c := range f.columns {
+		values[i] = row + "." + QuoteIdent(c)
+	}
+	return strings.Join(values, ", ")
+}
+
+// command 生成 FTS5 特殊命令语句,如 rebuild、optimize
+func (f *FTS) command(cmd string) string {
+	table := QuoteIdent(f.name)
+	return fmt.Sprintf("INSERT INTO %s(%s) VALUES (%s)", table, table, sqlString(cmd))
+}
+
+// bm25 生成 bm25 表达式,列权重只能以字面量传入
+func (f *FTS) bm25(weights []float64) string {
+	parts := []string{QuoteIdent(f.name)}
+	for _, w := range weights {
+		parts = append(parts, fmt.Sprint(w))
+	}
+	return "bm25(" + strings.Join(parts, ", ") + ")"
+}
+
+// columnIndex 返回列在表中的序号,不存在时返回-1
+func (f *FTS) columnIndex(column string) int {
+	for i, c := range f.columns {
+		if c == column {
+			return i
+		}
+	}
+	return -1
+}
+
+// segment CJK 模式下切分写入的文本
+func (f *FTS) segment(s string) string {
+	if !f.cjk {
+		return s
+	}
+	return segmentCJK(s)
+}
+
+// unsegment CJK 模式下去掉切分时插入的分隔符
+func (f *FTS) unsegment(s string) string {
+	if !f.cjk {
+		return s
+	}
+	return strings.ReplaceAll(s, string(cjkSeparator), "")
+}
+
+// matchQuery CJK 模式下将查询中连续的中日韩文字转换为逐字短语,如 数据库* 转换为 "数 据 库"*,
+// body:检索 转换为 body:"检 索";引号内的中文只插入空格
+func (f *FTS) matchQuery(query string) string {
+	if !f.cjk {
+		return query
+	}
+	var sb strings.Builder
+	runes := []rune(query)
+	quoted := false
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		if r == '"' {
+			quoted = !quoted
+		}
+		if !isCJK(r) {
+			sb.WriteRune(r)
+			i++
+			continue
+		}
+		j := i
+		for j < len(runes) && isCJK(runes[j]) {
+			j++
+		}
+		chars := make([]string, 0, j-i)
+		for _, c := range runes[i:j] {
+			chars = append(chars, string(c))
+		}
+		phrase := strings.Join(chars, " ")
+		if quoted {
+			if i > 0 && runes[i-1] != '"' && !unicode.IsSpace(runes[i-1]) {
+				sb.WriteByte(' ')
+			}
+			sb.WriteString(phrase)
+			if j < len(runes) && runes[j] != '"' && !unicode.IsSpace(runes[j]) {
+				sb.WriteByte(' ')
+			}
+		} else {
+			if i > 0 && !unicode.IsSpace(runes[i-1]) && !strings.ContainsRune("(:^", runes[i-1]) {
+				sb.WriteByte(' ')
+			}
+			sb.WriteString(`"` + phrase + `"`)
+			if j < len(runes) && !unicode.IsSpace(runes[j]) && !strings.ContainsRune("*),", runes[j]) {
+				sb.WriteByte(' ')
+			}
+		}
+		i = j
+	}
+	return sb.String()
+}
+
+// segmentCJK 在中日韩文字与相邻字符之间插入零宽空格,使 unicode61 分词器逐字切分
+func segmentCJK(s string) string {
+	var sb strings.Builder
+	var prev rune
+	for i, r := range s {
+		if i > 0 && (isCJK(r) || isCJK(prev)) && !isBreak(r) && !isBreak(prev) {
+			sb.WriteRune(cjkSeparator)
+		}
+		sb.WriteRune(r)
+		prev = r
+	}
+	return sb.String()
+}
+
+// isCJK 判断是否为中日韩文字
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul)
+}
+
+// isBreak 判断是否已是分隔字符
+func isBreak(r rune) bool {
+	return r == cjkSeparator || unicode.IsSpace(r) || unicode.IsPunct(r)
+}
+
+// sqlString 转义为SQL字符串字面量
+func sqlString(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
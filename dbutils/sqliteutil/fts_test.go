@@ -0,0 +1,71 @@
+package sqliteutil
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSegmentCJK(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string // 按零宽空格切分后的片段
+	}{
+		{"", []string{""}},
+		{"sqlite", []string{"sqlite"}},
+		{"数据库", []string{"数", "据", "库"}},
+		{"使用SQLite实现", []string{"使", "用", "SQLite", "实", "现"}},
+		{"今天 天气", []string{"今", "天 天", "气"}},
+		{"你好,世界", []string{"你", "好,世", "界"}},
+		{"ひらがなカタカナ", []string{"ひ", "ら", "が", "な", "カ", "タ", "カ", "ナ"}},
+		{"한국어", []string{"한", "국", "어"}},
+		{"v2版本", []string{"v2", "版", "本"}},
+	}
+	for _, tt := range tests {
+		got := segmentCJK(tt.in)
+		assert.Equal(t, tt.want, strings.Split(got, string(cjkSeparator)), tt.in)
+		// 已切分的文本再次切分不变
+		assert.Equal(t, got, segmentCJK(got), tt.in)
+	}
+}
+
+func TestUnsegment(t *testing.T) {
+	cjk := &FTS{cjk: true}
+	plain := &FTS{}
+	for _, s := range []string{"", "sqlite", "使用SQLite实现全文检索", "今天 天气, 很好", "한국어 ひらがな"} {
+		assert.Equal(t, s, cjk.unsegment(segmentCJK(s)), s)
+	}
+	assert.Equal(t, "全文<b>检索</b>", cjk.unsegment(segmentCJK("全文<b>检索</b>")))
+	assert.Equal(t, segmentCJK("数据库"), plain.unsegment(segmentCJK("数据库")))
+}
+
+func TestMatchQuery(t *testing.T) {
+	f := &FTS{cjk: true}
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"sqlite", "sqlite"},
+		{"检索", `"检 索"`},
+		{"数据库*", `"数 据 库"*`},
+		{"sqlite AND 全文", `sqlite AND "全 文"`},
+		{"库 OR 功能", `"库" OR "功 能"`},
+		{"使用SQLite", `"使 用" SQLite`},
+		{"SQLite实现", `SQLite "实 现"`},
+		{`"数据库连接"`, `"数 据 库 连 接"`},
+		{`"使用 sqlite"`, `"使 用 sqlite"`},
+		{`"全文search"`, `"全 文 search"`},
+		{"(天气 OR 下雨) NOT 明天", `("天 气" OR "下 雨") NOT "明 天"`},
+		{"body:检索", `body:"检 索"`},
+		{"title:sqlite AND body:全文*", `title:sqlite AND body:"全 文"*`},
+		{"{title body}:数据", `{title body}:"数 据"`},
+		{"NEAR(检索 数据, 5)", `NEAR("检 索" "数 据", 5)`},
+		{"^开头", `^"开 头"`},
+		{"- body:检索", `- body:"检 索"`},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, f.matchQuery(tt.query), tt.query)
+	}
+	assert.Equal(t, "检索*", (&FTS{}).matchQuery("检索*"))
+}
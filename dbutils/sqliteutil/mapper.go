@@ -0,0 +1,501 @@
+package sqliteutil
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+	"github.com/wind959/ko-utils/strutil"
+)
+
+var (
+	ErrNotStruct    = errors.New("sqliteutil: value must be a struct or pointer to struct")
+	ErrNoPrimaryKey = errors.New("sqliteutil: struct has no primary key field")
+)
+
+// structMeta 结构体映射元数据
+type structMeta struct {
+	fields   []*fieldMeta
+	byColumn map[string]*fieldMeta
+	pk       *fieldMeta
+}
+
+// fieldMeta 字段映射元数据
+type fieldMeta struct {
+	column    string
+	index     []int
+	omitempty bool
+	pk        bool
+	autoinc   bool
+	json      bool // 结构体、map、切片等复合类型按JSON存储
+}
+
+var (
+	metaCache sync.Map // reflect.Type -> *structMeta
+
+	timeType    = reflect.TypeOf(time.Time{})
+	bytesType   = reflect.TypeOf([]byte(nil))
+	scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+	valuerType  = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+)
+
+// InsertStruct 按 db 标签插入结构体,返回自增ID;v 为指针时自增主键会回填
+//
+// 标签格式: `db:"列名,omitempty,pk,autoincrement"`,`db:"-"` 表示忽略,未设置列名时使用字段名的 snake_case。
+// time.Time 直接存储,结构体、map、切片(除 []byte)以JSON存储
+func (m *DBManager) InsertStruct(ctx context.Context, table string, v interface{}) (int64, error) {
+	rv, meta, err := structValue(v)
+	if err != nil {
+		return 0, err
+	}
+	data := make(map[string]interface{}, len(meta.fields))
+	for _, f := range meta.fields {
+		fv, err := rv.FieldByIndexErr(f.index)
+		if err != nil || ((f.autoinc || f.omitempty) && fv.IsZero()) {
+			continue // 嵌入的nil指针或可省略的零值
+		}
+		val, err := f.value(fv)
+		if err != nil {
+			return 0, err
+		}
+		data[f.column] = val
+	}
+	if len(data) == 0 {
+		return 0, fmt.Errorf("%w: no columns to insert", ErrInvalidStatement)
+	}
+
+	res, err := m.ExecStmt(ctx, Insert(table).SetMap(data))
+	if err != nil {
+		return 0, fmt.Errorf("insert failed: %w", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	if pk := meta.pk; pk != nil && pk.autoinc && rv.CanSet() {
+		switch fv := rv.FieldByIndex(pk.index); {
+		case !fv.IsZero():
+		case fv.CanInt():
+			fv.SetInt(id)
+		case fv.CanUint():
+			fv.SetUint(uint64(id))
+		}
+	}
+	return id, nil
+}
+
+// UpdateStruct 按主键更新结构体的其余字段,omitempty 字段为零值时不更新,返回受影响的行数
+func (m *DBManager) UpdateStruct(ctx context.Context, table string, v interface{}) (int64, error) {
+	rv, meta, err := structValue(v)
+	if err != nil {
+		return 0, err
+	}
+	if meta.pk == nil {
+		return 0, ErrNoPrimaryKey
+	}
+	b := Update(table)
+	for _, f := range meta.fields {
+		fv, err := rv.FieldByIndexErr(f.index)
+		if err != nil || f.pk || (f.omitempty && fv.IsZero()) {
+			continue
+		}
+		val, err := f.value(fv)
+		if err != nil {
+			return 0, err
+		}
+		b.Set(f.column, val)
+	}
+	pk, err := meta.pk.value(rv.FieldByIndex(meta.pk.index))
+	if err != nil {
+		return 0, err
+	}
+	res, err := m.ExecStmt(ctx, b.Where(Eq(meta.pk.column, pk)))
+	if err != nil {
+		return 0, fmt.Errorf("update failed: %w", err)
+	}
+	return res.RowsAffected()
+}
+
+// QueryStructs 执行查询并按列名映射到结构体切片,结果中没有对应字段的列会被忽略
+func QueryStructs[T any](ctx context.Context, m *DBManager, query string, args ...interface{}) ([]T, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.db == nil {
+		return nil, errors.New("database not initialized")
+	}
+	rows, err := m.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanStructs[T](rows, 0)
+}
+
+// QueryOne 查询单条记录,没有结果时返回 sql.ErrNoRows
+func QueryOne[T any](ctx context.Context, m *DBManager, query string, args ...interface{}) (T, error) {
+	var zero T
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.db == nil {
+		return zero, errors.New("database not initialized")
+	}
+	rows, err := m.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return zero, err
+	}
+	defer rows.Close()
+	items, err := scanStructs[T](rows, 1)
+	if err != nil {
+		return zero, err
+	}
+	if len(items) == 0 {
+		return zero, sql.ErrNoRows
+	}
+	return items[0], nil
+}
+
+// ScanStructs 将 rows 映射到结构体切片,不会关闭 rows
+func ScanStructs[T any](rows *sql.Rows) ([]T, error) {
+	return scanStructs[T](rows, 0)
+}
+
+// scanStructs 映射结果集,limit>0 时最多读取 limit 行
+func scanStructs[T any](rows *sql.Rows, limit int) ([]T, error) {
+	meta, err := metaOf(reflect.TypeOf((*T)(nil)).Elem())
+	if err != nil {
+		return nil, err
+	}
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	var items []T
+	dest := make([]interface{}, len(columns))
+	for rows.Next() {
+		var item T
+		rv := reflect.ValueOf(&item).Elem()
+		for i, c := range columns {
+			f, ok := meta.byColumn[strings.ToLower(c)]
+			if !ok {
+				dest[i] = new(interface{})
+				continue
+			}
+			dest[i] = &fieldScanner{dst: fieldByIndexAlloc(rv, f.index), json: f.json}
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+		if limit > 0 && len(items) >= limit {
+			break
+		}
+	}
+	return items, rows.Err()
+}
+
+// structValue 解析结构体或结构体指针
+func structValue(v interface{}) (reflect.Value, *structMeta, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return rv, nil, ErrNotStruct
+		}
+		rv = rv.Elem()
+	}
+	meta, err := metaOf(rv.Type())
+	return rv, meta, err
+}
+
+// metaOf 获取并缓存结构体元数据
+func metaOf(t reflect.Type) (*structMeta, error) {
+	if t.Kind() != reflect.Struct {
+		return nil, ErrNotStruct
+	}
+	if cached, ok := metaCache.Load(t); ok {
+		return cached.(*structMeta), nil
+	}
+	meta := &structMeta{byColumn: map[string]*fieldMeta{}}
+	collectFields(t, nil, meta)
+	actual, _ := metaCache.LoadOrStore(t, meta)
+	return actual.(*structMeta), nil
+}
+
+// collectFields 收集字段,匿名嵌入且无标签的结构体会展开
+func collectFields(t reflect.Type, parent []int, meta *structMeta) {
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag, hasTag := sf.Tag.Lookup("db")
+		if tag == "-" || (!sf.IsExported() && !sf.Anonymous) {
+			continue
+		}
+		index := append(append([]int(nil), parent...), i)
+		ft := sf.Type
+		if sf.Anonymous && !hasTag {
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct && ft != timeType {
+				collectFields(ft, index, meta)
+				continue
+			}
+		}
+		if !sf.IsExported() {
+			continue
+		}
+
+		parts := strings.Split(tag, ",")
+		f := &fieldMeta{column: parts[0], index: index, json: isJSONType(sf.Type)}
+		if f.column == "" {
+			f.column = strutil.SnakeCase(sf.Name)
+		}
+		for _, opt := range parts[1:] {
+			switch strings.TrimSpace(opt) {
+			case "omitempty":
+				f.omitempty = true
+			case "pk":
+				f.pk = true
+			case "autoincrement":
+				f.autoinc = true
+				f.pk = true
+			}
+		}
+		if f.pk && meta.pk == nil {
+			meta.pk = f
+		}
+		if _, dup := meta.byColumn[strings.ToLower(f.column)]; dup {
+			continue // 同名列只保留先出现的字段
+		}
+		meta.fields = append(meta.fields, f)
+		meta.byColumn[strings.ToLower(f.column)] = f
+	}
+}
+
+// isJSONType 判断字段是否需要按JSON存储
+func isJSONType(t reflect.Type) bool {
+	if t.Implements(valuerType) || reflect.PointerTo(t).Implements(scannerType) {
+		return false
+	}
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == timeType || t == bytesType {
+		return false
+	}
+	switch t.Kind() {
+	case reflect.Struct, reflect.Map, reflect.Slice, reflect.Array:
+		return true
+	}
+	return false
+}
+
+// value 将字段值转换为驱动参数
+func (f *fieldMeta) value(fv reflect.Value) (interface{}, error) {
+	if fv.Kind() == reflect.Pointer && fv.IsNil() {
+		return nil, nil
+	}
+	if f.json {
+		data, err := json.Marshal(fv.Interface())
+		if err != nil {
+			return nil, fmt.Errorf("encoding column %s failed: %w", f.column, err)
+		}
+		return string(data), nil
+	}
+	return fv.Interface(), nil
+}
+
+// fieldByIndexAlloc 按索引取字段,沿途为nil的嵌入指针会被分配
+func fieldByIndexAlloc(v reflect.Value, index []int) reflect.Value {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v
+}
+
+// fieldScanner 将驱动返回的值写入结构体字段
+type fieldScanner struct {
+	dst  reflect.Value
+	json bool
+}
+
+// Scan 实现 sql.Scanner,NULL 写入零值
+func (s *fieldScanner) Scan(src interface{}) error {
+	return assign(s.dst, src, s.json)
+}
+
+// assign 按目标类型转换并赋值
+func assign(dst reflect.Value, src interface{}, asJSON bool) error {
+	if src == nil {
+		dst.Set(reflect.Zero(dst.Type()))
+		return nil
+	}
+	if sc, ok := dst.Addr().Interface().(sql.Scanner); ok {
+		return sc.Scan(src)
+	}
+	if dst.Kind() == reflect.Pointer && !asJSON {
+		elem := reflect.New(dst.Type().Elem())
+		if err := assign(elem.Elem(), src, false); err != nil {
+			return err
+		}
+		dst.Set(elem)
+		return nil
+	}
+	if asJSON {
+		var data []byte
+		switch v := src.(type) {
+		case []byte:
+			data = v
+		case string:
+			data = []byte(v)
+		default:
+			return fmt.Errorf("sqliteutil: cannot decode %T as JSON into %s", src, dst.Type())
+		}
+		return json.Unmarshal(data, dst.Addr().Interface())
+	}
+
+	if dst.Type() == timeType {
+		t, err := toTime(src)
+		if err != nil {
+			return err
+		}
+		dst.Set(reflect.ValueOf(t))
+		return nil
+	}
+	sv := reflect.ValueOf(src)
+	switch {
+	case dst.Kind() == reflect.String && sv.Type() == bytesType:
+		dst.SetString(string(src.([]byte)))
+	case dst.Type() == bytesType && sv.Kind() == reflect.String:
+		dst.SetBytes([]byte(sv.String()))
+	case dst.Type() == bytesType && sv.Type() == bytesType:
+		dst.SetBytes(append([]byte(nil), src.([]byte)...))
+	case dst.Kind() == reflect.Bool && sv.Kind() == reflect.Int64:
+		dst.SetBool(sv.Int() != 0)
+	case dst.Kind() == reflect.String && sv.Kind() != reflect.String:
+		dst.SetString(fmt.Sprint(src))
+	case isNumber(dst.Kind()) && isNumber(sv.Kind()):
+		return setNumber(dst, sv)
+	case sv.Type().ConvertibleTo(dst.Type()):
+		dst.Set(sv.Convert(dst.Type()))
+	default:
+		return fmt.Errorf("sqliteutil: cannot assign %T to %s", src, dst.Type())
+	}
+	return nil
+}
+
+// isNumber 判断是否为整数或浮点数类型
+func isNumber(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// setNumber 在数值类型间赋值,小数截断、溢出或负数转无符号等有损转换返回错误
+func setNumber(dst, sv reflect.Value) error {
+	lossy := func() error {
+		return fmt.Errorf("sqliteutil: cannot assign %s %v to %s without loss", sv.Type(), sv.Interface(), dst.Type())
+	}
+	switch {
+	case dst.CanInt():
+		var i int64
+		switch {
+		case sv.CanInt():
+			i = sv.Int()
+		case sv.CanUint():
+			if sv.Uint() > math.MaxInt64 {
+				return lossy()
+			}
+			i = int64(sv.Uint())
+		default:
+			f := sv.Float()
+			if f != math.Trunc(f) || f < math.MinInt64 || f >= math.MaxInt64 {
+				return lossy()
+			}
+			i = int64(f)
+		}
+		if dst.OverflowInt(i) {
+			return lossy()
+		}
+		dst.SetInt(i)
+	case dst.CanUint():
+		var u uint64
+		switch {
+		case sv.CanInt():
+			if sv.Int() < 0 {
+				return lossy()
+			}
+			u = uint64(sv.Int())
+		case sv.CanUint():
+			u = sv.Uint()
+		default:
+			f := sv.Float()
+			if f != math.Trunc(f) || f < 0 || f >= math.MaxUint64 {
+				return lossy()
+			}
+			u = uint64(f)
+		}
+		if dst.OverflowUint(u) {
+			return lossy()
+		}
+		dst.SetUint(u)
+	default:
+		var f float64
+		switch {
+		case sv.CanInt():
+			f = float64(sv.Int())
+		case sv.CanUint():
+			f = float64(sv.Uint())
+		default:
+			f = sv.Float()
+		}
+		if dst.OverflowFloat(f) {
+			return lossy()
+		}
+		dst.SetFloat(f)
+	}
+	return nil
+}
+
+// toTime 将驱动返回的时间、字符串或Unix秒转换为 time.Time
+func toTime(src interface{}) (time.Time, error) {
+	switch v := src.(type) {
+	case time.Time:
+		return v, nil
+	case int64:
+		return time.Unix(v, 0), nil
+	case []byte:
+		return toTime(string(v))
+	case string:
+		s := strings.TrimSuffix(v, "Z")
+		for _, layout := range sqlite3.SQLiteTimestampFormats {
+			if t, err := time.ParseInLocation(layout, s, time.UTC); err == nil {
+				return t, nil
+			}
+		}
+		if t, err := time.Parse(time.RFC3339Nano, v); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("sqliteutil: cannot convert %T %v to time.Time", src, src)
+}
@@ -0,0 +1,398 @@
+package sqliteutil
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+)
+
+var (
+	ErrMigrationLocked  = errors.New("sqliteutil: migration lock is held by another process")
+	ErrNoDownMigration  = errors.New("sqliteutil: down migration not found")
+	ErrUnknownMigration = errors.New("sqliteutil: unknown migration version")
+)
+
+// migrationFile 迁移文件名: 版本号_名称.up.sql / 版本号_名称.down.sql
+var migrationFile = regexp.MustCompile(`^(\d+)_(.+)\.(up|down)\.sql$`)
+
+// Migration 单个迁移
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// MigrationStatus 迁移状态
+type MigrationStatus struct {
+	Version   int64
+	Name      string
+	Applied   bool
+	AppliedAt time.Time
+}
+
+// Migrator 迁移执行器
+type Migrator struct {
+	db          *DBManager
+	migrations  []Migration
+	table       string
+	lockTimeout time.Duration
+	staleAge    time.Duration
+	owner       string
+}
+
+// MigratorOption 迁移执行器配置选项
+type MigratorOption func(*Migrator)
+
+// WithMigrationsTable 设置记录版本的表名,默认 schema_migrations,锁表名为其加 _lock 后缀
+func WithMigrationsTable(table string) MigratorOption {
+	return func(m *Migrator) {
+		m.table = table
+	}
+}
+
+// WithLockTimeout 设置等待迁移锁的最长时间,默认30秒
+func WithLockTimeout(d time.Duration) MigratorOption {
+	return func(m *Migrator) {
+		m.lockTimeout = d
+	}
+}
+
+// WithStaleLockAge 设置迁移锁的过期时长,超过该时长未刷新的锁视为进程异常退出后的残留,可被抢占,
+// 默认15分钟。持有锁时每执行一个迁移刷新一次,因此应大于单个迁移的最长执行时间
+func WithStaleLockAge(d time.Duration) MigratorOption {
+	return func(m *Migrator) {
+		m.staleAge = d
+	}
+}
+
+// NewMigrator 从 fsys 的 dir 目录读取迁移文件,支持 embed.FS 和 os.DirFS
+func NewMigrator(db *DBManager, fsys fs.FS, dir string, opts ...MigratorOption) (*Migrator, error) {
+	m := &Migrator{
+		db:          db,
+		table:       "schema_migrations",
+		lockTimeout: 30 * time.Second,
+		staleAge:    15 * time.Minute,
+		owner:       fmt.Sprintf("%d-%d", os.Getpid(), time.Now().UnixNano()),
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+	byVersion := map[int64]*Migration{}
+	for _, e := range entries {
+		match := migrationFile.FindStringSubmatch(e.Name())
+		if e.IsDir() || match == nil {
+			continue
+		}
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("sqliteutil: invalid migration version %s: %w", e.Name(), err)
+		}
+		data, err := fs.ReadFile(fsys, path.Join(dir, e.Name()))
+		if err != nil {
+			return nil, err
+		}
+		mg, ok := byVersion[version]
+		if !ok {
+			mg = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = mg
+		} else if mg.Name != match[2] {
+			return nil, fmt.Errorf("sqliteutil: duplicate migration version %d (%s, %s)", version, mg.Name, match[2])
+		}
+		if match[3] == "up" {
+			mg.Up = string(data)
+		} else {
+			mg.Down = string(data)
+		}
+	}
+	for _, mg := range byVersion {
+		if mg.Up == "" {
+			return nil, fmt.Errorf("sqliteutil: migration %d_%s has no up file", mg.Version, mg.Name)
+		}
+		m.migrations = append(m.migrations, *mg)
+	}
+	sort.Slice(m.migrations, func(i, j int) bool {
+		return m.migrations[i].Version < m.migrations[j].Version
+	})
+	return m, nil
+}
+
+// Migrations 返回按版本排序的迁移列表
+func (m *Migrator) Migrations() []Migration {
+	return append([]Migration(nil), m.migrations...)
+}
+
+// Up 执行所有未执行的迁移,返回执行的数量
+func (m *Migrator) Up(ctx context.Context) (int, error) {
+	return m.Goto(ctx, -1)
+}
+
+// Down 回滚最近执行的 n 个迁移,返回回滚的数量
+func (m *Migrator) Down(ctx context.Context, n int) (int, error) {
+	count := 0
+	err := m.withLock(ctx, func(applied map[int64]time.Time) error {
+		versions := sortedVersions(applied)
+		for i := len(versions) - 1; i >= 0 && count < n; i-- {
+			if err := m.rollback(ctx, versions[i]); err != nil {
+				return err
+			}
+			count++
+		}
+		return nil
+	})
+	return count, err
+}
+
+// Goto 迁移到指定版本: 执行不超过 version 的未执行迁移,回滚大于 version 的已执行迁移。
+// version 为0时回滚全部,为负数时执行全部,大于0时必须是已有的迁移版本;返回执行和回滚的总数
+func (m *Migrator) Goto(ctx context.Context, version int64) (int, error) {
+	if version > 0 && !m.known(version) {
+		return 0, fmt.Errorf("%w: %d", ErrUnknownMigration, version)
+	}
+	count := 0
+	err := m.withLock(ctx, func(applied map[int64]time.Time) error {
+		if version >= 0 {
+			versions := sortedVersions(applied)
+			for i := len(versions) - 1; i >= 0 && versions[i] > version; i-- {
+				if err := m.rollback(ctx, versions[i]); err != nil {
+					return err
+				}
+				count++
+			}
+		}
+		for _, mg := range m.migrations {
+			if _, ok := applied[mg.Version]; ok || (version >= 0 && mg.Version > version) {
+				continue
+			}
+			if err := m.apply(ctx, mg); err != nil {
+				return err
+			}
+			count++
+		}
+		return nil
+	})
+	return count, err
+}
+
+// Status 返回所有迁移的状态,数据库中存在但文件缺失的版本也会列出
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	if err := m.ensureTables(ctx); err != nil {
+		return nil, err
+	}
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var status []MigrationStatus
+	known := map[int64]bool{}
+	for _, mg := range m.migrations {
+		at, ok := applied[mg.Version]
+		status = append(status, MigrationStatus{Version: mg.Version, Name: mg.Name, Applied: ok, AppliedAt: at})
+		known[mg.Version] = true
+	}
+	for v, at := range applied {
+		if !known[v] {
+			status = append(status, MigrationStatus{Version: v, Applied: true, AppliedAt: at})
+		}
+	}
+	sort.Slice(status, func(i, j int) bool { return status[i].Version < status[j].Version })
+	return status, nil
+}
+
+// Version 返回已执行的最大版本,没有时返回0
+func (m *Migrator) Version(ctx context.Context) (int64, error) {
+	if err := m.ensureTables(ctx); err != nil {
+		return 0, err
+	}
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return 0, err
+	}
+	versions := sortedVersions(applied)
+	if len(versions) == 0 {
+		return 0, nil
+	}
+	return versions[len(versions)-1], nil
+}
+
+// apply 在事务中执行迁移并记录版本
+func (m *Migrator) apply(ctx context.Context, mg Migration) error {
+	return m.inTx(ctx, func(tx *sql.Tx) error {
+		if err := m.refreshLock(ctx, tx); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, mg.Up); err != nil {
+			return fmt.Errorf("migration %d_%s up failed: %w", mg.Version, mg.Name, err)
+		}
+		_, err := tx.ExecContext(ctx, "INSERT INTO "+QuoteIdent(m.table)+" (version, name, applied_at) VALUES (?, ?, ?)",
+			mg.Version, mg.Name, time.Now().UTC())
+		return err
+	})
+}
+
+// rollback 在事务中回滚迁移并删除版本记录
+func (m *Migrator) rollback(ctx context.Context, version int64) error {
+	var mg *Migration
+	for i := range m.migrations {
+		if m.migrations[i].Version == version {
+			mg = &m.migrations[i]
+		}
+	}
+	if mg == nil {
+		return fmt.Errorf("%w: %d", ErrUnknownMigration, version)
+	}
+	if mg.Down == "" {
+		return fmt.Errorf("%w: %d_%s", ErrNoDownMigration, mg.Version, mg.Name)
+	}
+	return m.inTx(ctx, func(tx *sql.Tx) error {
+		if err := m.refreshLock(ctx, tx); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, mg.Down); err != nil {
+			return fmt.Errorf("migration %d_%s down failed: %w", mg.Version, mg.Name, err)
+		}
+		_, err := tx.ExecContext(ctx, "DELETE FROM "+QuoteIdent(m.table)+" WHERE version = ?", mg.Version)
+		return err
+	})
+}
+
+// withLock 持有迁移锁执行 fn,传入当前已执行的版本
+func (m *Migrator) withLock(ctx context.Context, fn func(applied map[int64]time.Time) error) error {
+	if err := m.ensureTables(ctx); err != nil {
+		return err
+	}
+	if err := m.lock(ctx); err != nil {
+		return err
+	}
+	defer m.unlock()
+
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return err
+	}
+	return fn(applied)
+}
+
+// lock 获取迁移锁,锁被占用时轮询等待直到超时
+func (m *Migrator) lock(ctx context.Context) error {
+	lockTable := QuoteIdent(m.table + "_lock")
+	ctx, cancel := context.WithTimeout(ctx, m.lockTimeout)
+	defer cancel()
+	for {
+		now := time.Now().UTC()
+		// 清理残留的过期锁
+		if _, err := m.db.Execute(ctx, "DELETE FROM "+lockTable+" WHERE locked_at < ?", now.Add(-m.staleAge)); err != nil {
+			return err
+		}
+		res, err := m.db.Execute(ctx, "INSERT OR IGNORE INTO "+lockTable+" (id, owner, locked_at) VALUES (1, ?, ?)", m.owner, now)
+		if err != nil {
+			return err
+		}
+		if n, _ := res.RowsAffected(); n == 1 {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return ErrMigrationLocked
+			}
+			return ctx.Err()
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+}
+
+// refreshLock 在迁移事务中刷新锁的时间,锁已被其他进程抢占时返回 ErrMigrationLocked
+func (m *Migrator) refreshLock(ctx context.Context, tx *sql.Tx) error {
+	res, err := tx.ExecContext(ctx, "UPDATE "+QuoteIdent(m.table+"_lock")+" SET locked_at = ? WHERE owner = ?", time.Now().UTC(), m.owner)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("%w: lock was taken over", ErrMigrationLocked)
+	}
+	return nil
+}
+
+// unlock 释放迁移锁
+func (m *Migrator) unlock() {
+	m.db.SafeExec(context.Background(), "DELETE FROM "+QuoteIdent(m.table+"_lock")+" WHERE owner = ?", m.owner)
+}
+
+// ensureTables 创建版本表和锁表
+func (m *Migrator) ensureTables(ctx context.Context) error {
+	_, err := m.db.Execute(ctx, fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+		version INTEGER PRIMARY KEY,
+		name TEXT NOT NULL,
+		applied_at DATETIME NOT NULL
+	);
+	CREATE TABLE IF NOT EXISTS %s (
+		id INTEGER PRIMARY KEY CHECK (id = 1),
+		owner TEXT NOT NULL,
+		locked_at DATETIME NOT NULL
+	)`, QuoteIdent(m.table), QuoteIdent(m.table+"_lock")))
+	return err
+}
+
+// applied 读取已执行的版本
+func (m *Migrator) applied(ctx context.Context) (map[int64]time.Time, error) {
+	type row struct {
+		Version   int64     `db:"version"`
+		AppliedAt time.Time `db:"applied_at"`
+	}
+	rows, err := QueryStructs[row](ctx, m.db, "SELECT version, applied_at FROM "+QuoteIdent(m.table))
+	if err != nil {
+		return nil, err
+	}
+	applied := make(map[int64]time.Time, len(rows))
+	for _, r := range rows {
+		applied[r.Version] = r.AppliedAt
+	}
+	return applied, nil
+}
+
+// inTx 在事务中执行 fn,出错时回滚
+func (m *Migrator) inTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// known 是否存在 version 对应的迁移文件
+func (m *Migrator) known(version int64) bool {
+	for _, mg := range m.migrations {
+		if mg.Version == version {
+			return true
+		}
+	}
+	return false
+}
+
+// sortedVersions 返回升序排列的版本号
+func sortedVersions(applied map[int64]time.Time) []int64 {
+	versions := make([]int64, 0, len(applied))
+	for v := range applied {
+		versions = append(versions, v)
+	}
+	sort.Slice(versions, func(i, j int) bool { return versions[i] < versions[j] })
+	return versions
+}
@@ -0,0 +1,139 @@
+package sqliteutil
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+var ErrInvalidPragma = errors.New("sqliteutil: invalid pragma value")
+
+var (
+	journalModes = []string{"DELETE", "TRUNCATE", "PERSIST", "MEMORY", "WAL", "OFF"}
+	syncModes    = []string{"OFF", "NORMAL", "FULL", "EXTRA"}
+)
+
+// WithJournalMode 设置 journal_mode,如 WAL、DELETE、TRUNCATE
+func WithJournalMode(mode string) Option {
+	return func(c *Config) {
+		c.JournalMode = mode
+	}
+}
+
+// WithSynchronous 设置 synchronous,如 OFF、NORMAL、FULL、EXTRA
+func WithSynchronous(mode string) Option {
+	return func(c *Config) {
+		c.Synchronous = mode
+	}
+}
+
+// WithWAL 启用 WAL 模式并将 synchronous 设为 NORMAL,读写互不阻塞
+func WithWAL() Option {
+	return func(c *Config) {
+		c.JournalMode = "WAL"
+		c.Synchronous = "NORMAL"
+	}
+}
+
+// WithBusyTimeout 设置 busy_timeout,数据库被锁定时等待的最长时间
+func WithBusyTimeout(d time.Duration) Option {
+	return func(c *Config) {
+		c.BusyTimeout = d
+	}
+}
+
+// WithForeignKeys 设置是否启用外键约束
+func WithForeignKeys(enabled bool) Option {
+	return func(c *Config) {
+		c.ForeignKeys = &enabled
+	}
+}
+
+// WithCacheSize 设置 cache_size,正数为页数,负数为 KiB
+func WithCacheSize(size int) Option {
+	return func(c *Config) {
+		c.CacheSize = size
+	}
+}
+
+// WithSingleWriter 启用单写连接模式: 写操作在一个独立连接上串行执行,读操作使用读连接池,
+// 排队等待写连接时响应 ctx 取消。需要文件数据库,建议配合 WithWAL 使用
+func WithSingleWriter() Option {
+	return func(c *Config) {
+		c.SingleWriter = true
+	}
+}
+
+// pragmas 根据配置生成每个连接需要执行的 PRAGMA 语句
+func (c *Config) pragmas() ([]string, error) {
+	var stmts []string
+	if c.JournalMode != "" {
+		mode, err := pragmaValue(c.JournalMode, journalModes)
+		if err != nil {
+			return nil, err
+		}
+		stmts = append(stmts, "PRAGMA journal_mode = "+mode)
+	}
+	if c.Synchronous != "" {
+		mode, err := pragmaValue(c.Synchronous, syncModes)
+		if err != nil {
+			return nil, err
+		}
+		stmts = append(stmts, "PRAGMA synchronous = "+mode)
+	}
+	if c.BusyTimeout > 0 {
+		stmts = append(stmts, fmt.Sprintf("PRAGMA busy_timeout = %d", c.BusyTimeout.Milliseconds()))
+	}
+	if c.ForeignKeys != nil {
+		if *c.ForeignKeys {
+			stmts = append(stmts, "PRAGMA foreign_keys = ON")
+		} else {
+			stmts = append(stmts, "PRAGMA foreign_keys = OFF")
+		}
+	}
+	if c.CacheSize != 0 {
+		stmts = append(stmts, fmt.Sprintf("PRAGMA cache_size = %d", c.CacheSize))
+	}
+	return stmts, nil
+}
+
+// pragmaValue 校验枚举型 PRAGMA 的取值
+func pragmaValue(value string, allowed []string) (string, error) {
+	value = strings.ToUpper(value)
+	for _, v := range allowed {
+		if v == value {
+			return value, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %s", ErrInvalidPragma, value)
+}
+
+// execPragmas 在新连接上执行 PRAGMA
+func execPragmas(conn *sqlite3.SQLiteConn, stmts []string) error {
+	for _, stmt := range stmts {
+		if _, err := conn.Exec(stmt, nil); err != nil {
+			return fmt.Errorf("%s: %w", stmt, err)
+		}
+	}
+	return nil
+}
+
+// writeLock 获取写操作使用的连接池和锁。单写连接模式下只持有读锁,写操作在写连接上排队,
+// 等待时响应 ctx 取消;否则持有写锁独占执行
+func (m *DBManager) writeLock() (*sql.DB, func()) {
+	m.mu.RLock()
+	if m.writer != nil {
+		return m.writer, m.mu.RUnlock
+	}
+	m.mu.RUnlock()
+
+	m.mu.Lock()
+	if m.writer != nil {
+		return m.writer, m.mu.Unlock
+	}
+	return m.db, m.mu.Unlock
+}
@@ -0,0 +1,107 @@
+package sqliteutil
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// DefaultName 默认数据库名,对应 GetInstance 返回的单例
+const DefaultName = "default"
+
+var (
+	ErrAlreadyRegistered = errors.New("sqliteutil: database name already registered")
+	ErrReservedName      = errors.New("sqliteutil: database name is reserved")
+)
+
+var (
+	registry   = map[string]*DBManager{}
+	registryMu sync.RWMutex
+)
+
+// NewDBManager 创建并初始化独立的数据库管理器,可与 GetInstance 同时使用
+func NewDBManager(dataSourceName string, options ...Option) (*DBManager, error) {
+	m := &DBManager{}
+	if err := m.Init(dataSourceName, options...); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Register 以 name 注册数据库管理器,之后可以通过 Get(name) 获取
+func Register(name string, m *DBManager) error {
+	if name == DefaultName {
+		return ErrReservedName
+	}
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if _, ok := registry[name]; ok {
+		return fmt.Errorf("%w: %s", ErrAlreadyRegistered, name)
+	}
+	registry[name] = m
+	return nil
+}
+
+// Open 创建数据库管理器并以 name 注册,注册失败时关闭连接
+func Open(name, dataSourceName string, options ...Option) (*DBManager, error) {
+	m, err := NewDBManager(dataSourceName, options...)
+	if err != nil {
+		return nil, err
+	}
+	if err := Register(name, m); err != nil {
+		m.Close()
+		return nil, err
+	}
+	return m, nil
+}
+
+// Get 获取已注册的数据库管理器,name 为 DefaultName 时返回 GetInstance,不存在时返回 nil
+func Get(name string) *DBManager {
+	if name == DefaultName {
+		return GetInstance()
+	}
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	return registry[name]
+}
+
+// Unregister 取消注册并返回数据库管理器,不会关闭连接
+func Unregister(name string) *DBManager {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	m := registry[name]
+	delete(registry, name)
+	return m
+}
+
+// Names 返回已注册的数据库名,按字母排序,不含 DefaultName
+func Names() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// CloseAll 关闭并取消注册所有已注册的数据库,不影响 GetInstance 单例
+func CloseAll() error {
+	registryMu.Lock()
+	managers := registry
+	registry = map[string]*DBManager{}
+	registryMu.Unlock()
+
+	var errs []error
+	for name, m := range managers {
+		if err := m.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -8,6 +8,7 @@ import (
 	"log"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	_ "github.com/mattn/go-sqlite3"
@@ -19,8 +20,15 @@ var (
 )
 
 type DBManager struct {
-	db *sql.DB
-	mu sync.RWMutex // 读写锁：读操作可以并发，写操作独占
+	db      *sql.DB
+	writer  *sql.DB      // 单写连接模式下的写连接,为 nil 时读写共用 db
+	mu      sync.RWMutex // 读写锁：读操作可以并发，写操作独占
+	config  *Config
+	pragmas []string // 每个新连接执行的 PRAGMA
+
+	attachMu  sync.Mutex   // 保护 attached,连接钩子在连接池内部调用,不能使用 mu
+	attached  []Attachment // 每个新连接都会附加的数据库
+	attachGen atomic.Int64 // attached 的版本号,每次变化加一,旧版本的连接不再复用
 }
 
 // Config 数据库配置
@@ -29,6 +37,14 @@ type Config struct {
 	MaxIdleConns    int           // 最大空闲连接数
 	ConnMaxLifetime time.Duration // 连接最大生命周期
 	ConnMaxIdleTime time.Duration // 连接最大空闲时间
+	Attachments     []Attachment  // 附加数据库
+	JournalMode     string        // journal_mode,为空时使用默认值
+	Synchronous     string        // synchronous,为空时使用默认值
+	BusyTimeout     time.Duration // busy_timeout,为0时使用驱动默认的5秒
+	ForeignKeys     *bool         // foreign_keys,为 nil 时使用默认值
+	CacheSize       int           // cache_size,为0时使用默认值
+	SingleWriter    bool          // 单写连接模式
+	MaxVariables    int           // 单条语句的最大绑定参数数量,批量写入据此拆分
 }
 
 // Option 配置选项函数
@@ -61,27 +77,34 @@ func (m *DBManager) Init(dataSourceName string, options ...Option) error {
 		return errors.New("database already initialized")
 	}
 
-	db, err := sql.Open("sqlite3", dataSourceName)
-	if err != nil {
-		return fmt.Errorf("failed to open database: %w", err)
-	}
-
 	// 应用配置选项
 	config := &Config{
 		MaxOpenConns:    25,
 		MaxIdleConns:    25,
 		ConnMaxLifetime: 5 * time.Minute,
 		ConnMaxIdleTime: 5 * time.Minute,
+		MaxVariables:    999,
 	}
 	for _, opt := range options {
 		opt(config)
 	}
+	pragmas, err := config.pragmas()
+	if err != nil {
+		return err
+	}
+	m.pragmas = pragmas
+	m.attachMu.Lock()
+	m.attached = append([]Attachment(nil), config.Attachments...)
+	m.attachMu.Unlock()
+
+	db := openDB(dataSourceName, m.connectHook, &m.attachGen)
 
 	db.SetMaxOpenConns(config.MaxOpenConns)
 	db.SetMaxIdleConns(config.MaxIdleConns)
 	db.SetConnMaxLifetime(config.ConnMaxLifetime)
 	db.SetConnMaxIdleTime(config.ConnMaxIdleTime)
 	m.db = db
+	m.config = config
 
 	// 测试连接
 	if err := m.db.Ping(); err != nil {
@@ -90,6 +113,21 @@ func (m *DBManager) Init(dataSourceName string, options ...Option) error {
 		return fmt.Errorf("database ping failed: %w", err)
 	}
 
+	if config.SingleWriter {
+		writer := openDB(dataSourceName, m.connectHook, &m.attachGen)
+		writer.SetMaxOpenConns(1)
+		writer.SetMaxIdleConns(1)
+		writer.SetConnMaxLifetime(config.ConnMaxLifetime)
+		writer.SetConnMaxIdleTime(config.ConnMaxIdleTime)
+		if err := writer.Ping(); err != nil {
+			writer.Close()
+			m.db.Close()
+			m.db = nil
+			return fmt.Errorf("failed to open writer: %w", err)
+		}
+		m.writer = writer
+	}
+
 	return nil
 }
 
@@ -103,6 +141,10 @@ func (m *DBManager) Close() error {
 	}
 
 	err := m.db.Close()
+	if m.writer != nil {
+		err = errors.Join(err, m.writer.Close())
+		m.writer = nil
+	}
 	m.db = nil
 	return err
 }
@@ -121,10 +163,10 @@ func (m *DBManager) Ping(ctx context.Context) error {
 
 // CreateTable 创建表
 func (m *DBManager) CreateTable(ctx context.Context, tableName string, columns map[string]string, options ...TableOption) error {
-	m.mu.Lock()
-	defer m.mu.Unlock()
+	db, unlock := m.writeLock()
+	defer unlock()
 
-	if m.db == nil {
+	if db == nil {
 		return errors.New("database not initialized")
 	}
 
@@ -151,7 +193,7 @@ func (m *DBManager) CreateTable(ctx context.Context, tableName string, columns m
 			return fmt.Errorf("table %s already exists", tableName)
 		}
 		// 删除现有表
-		_, err = m.db.ExecContext(ctx, fmt.Sprintf("DROP TABLE %s", tableName))
+		_, err = db.ExecContext(ctx, fmt.Sprintf("DROP TABLE %s", tableName))
 		if err != nil {
 			return fmt.Errorf("failed to drop table: %w", err)
 		}
@@ -175,7 +217,7 @@ func (m *DBManager) CreateTable(ctx context.Context, tableName string, columns m
 
 	// 创建表
 	query := fmt.Sprintf("CREATE TABLE %s (\n  %s\n)", tableName, strings.Join(columnDefs, ",\n  "))
-	_, err = m.db.ExecContext(ctx, query)
+	_, err = db.ExecContext(ctx, query)
 	return err
 }
 
@@ -201,10 +243,10 @@ func (m *DBManager) tableExists(ctx context.Context, tableName string) (bool, er
 
 // Insert 插入单条数据
 func (m *DBManager) Insert(ctx context.Context, tableName string, data map[string]interface{}) (int64, error) {
-	m.mu.Lock()
-	defer m.mu.Unlock()
+	db, unlock := m.writeLock()
+	defer unlock()
 
-	if m.db == nil {
+	if db == nil {
 		return 0, errors.New("database not initialized")
 	}
 
@@ -231,7 +273,7 @@ func (m *DBManager) Insert(ctx context.Context, tableName string, data map[strin
 		strings.Join(placeholders, ", "),
 	)
 
-	result, err := m.db.ExecContext(ctx, query, values...)
+	result, err := db.ExecContext(ctx, query, values...)
 	if err != nil {
 		return 0, fmt.Errorf("insert failed: %w", err)
 	}
@@ -239,73 +281,19 @@ func (m *DBManager) Insert(ctx context.Context, tableName string, data map[strin
 	return result.LastInsertId()
 }
 
-// BatchInsert 批量插入数据
+// BatchInsert 批量插入数据,按 Config.MaxVariables 自动拆分为多行插入语句,在一个事务中执行,返回插入的总行数
 func (m *DBManager) BatchInsert(ctx context.Context, tableName string, columns []string, data [][]interface{}) (int64, error) {
-	m.mu.Lock()
-	defer m.mu.Unlock()
-
-	if m.db == nil {
-		return 0, errors.New("database not initialized")
-	}
-
-	if len(data) == 0 {
-		return 0, errors.New("no data provided")
-	}
-
-	// 开始事务
-	tx, err := m.db.BeginTx(ctx, nil)
-	if err != nil {
-		return 0, fmt.Errorf("begin transaction failed: %w", err)
-	}
-	defer func() {
-		if err != nil {
-			tx.Rollback()
-		}
-	}()
-
-	// 准备插入语句
-	placeholders := make([]string, len(columns))
-	for i := range columns {
-		placeholders[i] = fmt.Sprintf("$%d", i+1)
-	}
-
-	query := fmt.Sprintf(
-		"INSERT INTO %s (%s) VALUES (%s)",
-		tableName,
-		strings.Join(columns, ", "),
-		strings.Join(placeholders, ", "),
-	)
-
-	stmt, err := tx.PrepareContext(ctx, query)
-	if err != nil {
-		return 0, fmt.Errorf("prepare statement failed: %w", err)
-	}
-	defer stmt.Close()
-
-	var totalRows int64
-	for _, row := range data {
-		result, err := stmt.ExecContext(ctx, row...)
-		if err != nil {
-			return totalRows, fmt.Errorf("batch insert failed: %w", err)
-		}
-		rows, _ := result.RowsAffected()
-		totalRows += rows
-	}
-
-	// 提交事务
-	if err := tx.Commit(); err != nil {
-		return totalRows, fmt.Errorf("commit failed: %w", err)
-	}
-
-	return totalRows, nil
+	return m.batchExec(ctx, columns, data, func() *InsertBuilder {
+		return Insert(tableName).Columns(columns...)
+	})
 }
 
 // Update 更新数据
 func (m *DBManager) Update(ctx context.Context, tableName string, data map[string]interface{}, where string, args ...interface{}) (int64, error) {
-	m.mu.Lock()
-	defer m.mu.Unlock()
+	db, unlock := m.writeLock()
+	defer unlock()
 
-	if m.db == nil {
+	if db == nil {
 		return 0, errors.New("database not initialized")
 	}
 
@@ -331,7 +319,7 @@ func (m *DBManager) Update(ctx context.Context, tableName string, data map[strin
 		query += " WHERE " + where
 	}
 
-	result, err := m.db.ExecContext(ctx, query, values...)
+	result, err := db.ExecContext(ctx, query, values...)
 	if err != nil {
 		return 0, fmt.Errorf("update failed: %w", err)
 	}
@@ -341,10 +329,10 @@ func (m *DBManager) Update(ctx context.Context, tableName string, data map[strin
 
 // Delete 删除数据
 func (m *DBManager) Delete(ctx context.Context, tableName string, where string, args ...interface{}) (int64, error) {
-	m.mu.Lock()
-	defer m.mu.Unlock()
+	db, unlock := m.writeLock()
+	defer unlock()
 
-	if m.db == nil {
+	if db == nil {
 		return 0, errors.New("database not initialized")
 	}
 
@@ -353,7 +341,7 @@ func (m *DBManager) Delete(ctx context.Context, tableName string, where string,
 		query += " WHERE " + where
 	}
 
-	result, err := m.db.ExecContext(ctx, query, args...)
+	result, err := db.ExecContext(ctx, query, args...)
 	if err != nil {
 		return 0, fmt.Errorf("delete failed: %w", err)
 	}
@@ -426,26 +414,26 @@ func (m *DBManager) QueryWithCallback(ctx context.Context, tableName string, col
 
 // BeginTx 开始事务
 func (m *DBManager) BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error) {
-	m.mu.Lock()
-	defer m.mu.Unlock()
+	db, unlock := m.writeLock()
+	defer unlock()
 
-	if m.db == nil {
+	if db == nil {
 		return nil, errors.New("database not initialized")
 	}
 
-	return m.db.BeginTx(ctx, opts)
+	return db.BeginTx(ctx, opts)
 }
 
 // Transaction 执行事务操作
 func (m *DBManager) Transaction(ctx context.Context, fn func(*sql.Tx) error) error {
-	m.mu.Lock()
-	defer m.mu.Unlock()
+	db, unlock := m.writeLock()
+	defer unlock()
 
-	if m.db == nil {
+	if db == nil {
 		return errors.New("database not initialized")
 	}
 
-	tx, err := m.db.BeginTx(ctx, nil)
+	tx, err := db.BeginTx(ctx, nil)
 	if err != nil {
 		return fmt.Errorf("begin transaction failed: %w", err)
 	}
@@ -467,14 +455,14 @@ func (m *DBManager) Transaction(ctx context.Context, fn func(*sql.Tx) error) err
 
 // Execute 执行原始 SQL
 func (m *DBManager) Execute(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
-	m.mu.Lock()
-	defer m.mu.Unlock()
+	db, unlock := m.writeLock()
+	defer unlock()
 
-	if m.db == nil {
+	if db == nil {
 		return nil, errors.New("database not initialized")
 	}
 
-	return m.db.ExecContext(ctx, query, args...)
+	return db.ExecContext(ctx, query, args...)
 }
 
 // GetTableInfo 获取表结构信息
@@ -515,14 +503,14 @@ func (m *DBManager) GetTableInfo(ctx context.Context, tableName string) ([]Colum
 
 // Vacuum 清理数据库碎片
 func (m *DBManager) Vacuum(ctx context.Context) error {
-	m.mu.Lock()
-	defer m.mu.Unlock()
+	db, unlock := m.writeLock()
+	defer unlock()
 
-	if m.db == nil {
+	if db == nil {
 		return errors.New("database not initialized")
 	}
 
-	_, err := m.db.ExecContext(ctx, "VACUUM")
+	_, err := db.ExecContext(ctx, "VACUUM")
 	return err
 }
 
@@ -534,6 +522,13 @@ func WithPoolSize(maxOpen, maxIdle int) Option {
 	}
 }
 
+// WithMaxVariables 设置单条语句的最大绑定参数数量,应不超过 SQLITE_MAX_VARIABLE_NUMBER,默认999
+func WithMaxVariables(n int) Option {
+	return func(c *Config) {
+		c.MaxVariables = n
+	}
+}
+
 // WithConnLifetime 设置连接生命周期
 func WithConnLifetime(lifetime, idleTime time.Duration) Option {
 	return func(c *Config) {
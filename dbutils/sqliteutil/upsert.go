@@ -0,0 +1,109 @@
+package sqliteutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Upsert 插入一行,与 conflictCols 冲突时更新 updateCols 为新值;updateCols 为空时更新除冲突列外的所有列,
+// 没有可更新的列时忽略该行。返回受影响的行数
+func (m *DBManager) Upsert(ctx context.Context, table string, data map[string]interface{}, conflictCols, updateCols []string) (int64, error) {
+	if len(data) == 0 {
+		return 0, errors.New("no data provided")
+	}
+	columns, values := sortedMap(data)
+	b, err := upsertBuilder(table, columns, conflictCols, updateCols)
+	if err != nil {
+		return 0, err
+	}
+	res, err := m.ExecStmt(ctx, b.Values(values...))
+	if err != nil {
+		return 0, fmt.Errorf("upsert failed: %w", err)
+	}
+	return res.RowsAffected()
+}
+
+// BatchUpsert 批量 Upsert,按 Config.MaxVariables 自动拆分语句,在一个事务中执行,返回受影响的总行数
+func (m *DBManager) BatchUpsert(ctx context.Context, table string, columns []string, data [][]interface{}, conflictCols, updateCols []string) (int64, error) {
+	if _, err := upsertBuilder(table, columns, conflictCols, updateCols); err != nil {
+		return 0, err
+	}
+	return m.batchExec(ctx, columns, data, func() *InsertBuilder {
+		b, _ := upsertBuilder(table, columns, conflictCols, updateCols)
+		return b
+	})
+}
+
+// upsertBuilder 创建带 ON CONFLICT DO UPDATE 子句的插入构造器
+func upsertBuilder(table string, columns, conflictCols, updateCols []string) (*InsertBuilder, error) {
+	if len(conflictCols) == 0 {
+		return nil, fmt.Errorf("%w: upsert requires conflict columns", ErrInvalidStatement)
+	}
+	if len(updateCols) == 0 {
+		conflict := make(map[string]bool, len(conflictCols))
+		for _, c := range conflictCols {
+			conflict[c] = true
+		}
+		for _, c := range columns {
+			if !conflict[c] {
+				updateCols = append(updateCols, c)
+			}
+		}
+	}
+	b := Insert(table).Columns(columns...).OnConflict(conflictCols...)
+	if len(updateCols) == 0 {
+		return b.DoNothing(), nil
+	}
+	return b.DoUpdate(updateCols...), nil
+}
+
+// batchExec 将 data 按绑定参数上限拆分为多条多行插入语句,在一个事务中执行,返回受影响的总行数
+func (m *DBManager) batchExec(ctx context.Context, columns []string, data [][]interface{}, newBuilder func() *InsertBuilder) (int64, error) {
+	db, unlock := m.writeLock()
+	defer unlock()
+
+	if db == nil {
+		return 0, errors.New("database not initialized")
+	}
+	if len(data) == 0 {
+		return 0, errors.New("no data provided")
+	}
+	if len(columns) == 0 {
+		return 0, errors.New("no columns provided")
+	}
+	chunk := m.config.MaxVariables / len(columns)
+	if chunk == 0 {
+		return 0, fmt.Errorf("%w: %d columns exceed the limit of %d variables", ErrInvalidStatement, len(columns), m.config.MaxVariables)
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("begin transaction failed: %w", err)
+	}
+	defer tx.Rollback()
+
+	var total int64
+	for start := 0; start < len(data); start += chunk {
+		end := min(start+chunk, len(data))
+		b := newBuilder()
+		for _, row := range data[start:end] {
+			b.Values(row...)
+		}
+		query, args, err := b.Build()
+		if err != nil {
+			return 0, err
+		}
+		res, err := tx.ExecContext(ctx, query, args...)
+		if err != nil {
+			return 0, fmt.Errorf("batch write rows %d-%d failed: %w", start, end-1, err)
+		}
+		n, _ := res.RowsAffected()
+		total += n
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("commit failed: %w", err)
+	}
+	return total, nil
+}
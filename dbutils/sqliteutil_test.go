@@ -4,9 +4,12 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"path/filepath"
 	"testing"
+	"testing/fstest"
 	"time"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/wind959/ko-utils/dbutils/sqliteutil"
 )
 
@@ -52,3 +55,670 @@ func TestSqlite(t *testing.T) {
 		return err
 	})
 }
+
+func TestSqliteBuilder(t *testing.T) {
+	query, args, err := sqliteutil.Select("u.id", "u.name AS n", "o.total").
+		From("users AS u").
+		Join("orders AS o", "o.user_id", "u.id").
+		Where(sqliteutil.Gt("u.age", 18), sqliteutil.Or(sqliteutil.Like("u.name", "A%"), sqliteutil.In("u.id", 1, 2))).
+		OrderByDesc("o.total").
+		Limit(10).Offset(20).
+		Build()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT `u`.`id`, `u`.`name` AS `n`, `o`.`total` FROM `users` AS `u` JOIN `orders` AS `o` ON `o`.`user_id` = `u`.`id` WHERE `u`.`age` > ? AND (`u`.`name` LIKE ? OR `u`.`id` IN (?, ?)) ORDER BY `o`.`total` DESC LIMIT ? OFFSET ?", query)
+	assert.Equal(t, []interface{}{18, "A%", 1, 2, 10, 20}, args)
+
+	// 非普通标识符一律拒绝,表达式需显式使用 Expr
+	for _, b := range []sqliteutil.Builder{
+		sqliteutil.DeleteFrom(`users"; DROP TABLE users; --`).Where(sqliteutil.Eq("id", 1)),
+		sqliteutil.Select("name", "count(*) AS c").From("users"),
+		sqliteutil.Select().From("users").GroupBy("name").Having(sqliteutil.Gt("count(*)", 1)),
+		sqliteutil.Select().From("user items"),
+		sqliteutil.Select("1id").From("users"),
+	} {
+		_, _, err = b.Build()
+		assert.ErrorIs(t, err, sqliteutil.ErrInvalidStatement)
+	}
+	query, args, err = sqliteutil.Select("name").SelectExpr(sqliteutil.Expr("count(*)"), "c").
+		SelectExpr(sqliteutil.Expr("max(age, ?)", 0), "").From("users").
+		GroupBy("name").Having(sqliteutil.Expr("count(*) > ?", 1)).Build()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT `name`, (count(*)) AS `c`, (max(age, ?)) FROM `users` GROUP BY `name` HAVING (count(*) > ?)", query)
+	assert.Equal(t, []interface{}{0, 1}, args)
+
+	_, _, err = sqliteutil.Select().Build()
+	assert.ErrorIs(t, err, sqliteutil.ErrInvalidStatement)
+	_, _, err = sqliteutil.Insert("t").Columns("a", "b").Values(1).Build()
+	assert.ErrorIs(t, err, sqliteutil.ErrInvalidStatement)
+	_, _, err = sqliteutil.Select().From("t").Where(sqliteutil.Raw("a = ? AND b = ?", 1)).Build()
+	assert.ErrorIs(t, err, sqliteutil.ErrInvalidStatement)
+
+	// 通过 DBManager 执行
+	db := &sqliteutil.DBManager{}
+	assert.NoError(t, db.Init(filepath.Join(t.TempDir(), "builder.db")))
+	defer db.Close()
+	ctx := context.Background()
+	_, err = db.Execute(ctx, `CREATE TABLE user_items (id INTEGER PRIMARY KEY, name TEXT, age INTEGER)`)
+	assert.NoError(t, err)
+
+	_, err = db.ExecStmt(ctx, sqliteutil.Insert("user_items").Columns("name", "age").
+		Values("Alice", 30).Values("Bob", 17).Values("Robert'); DROP TABLE x; --", 40))
+	assert.NoError(t, err)
+	res, err := db.ExecStmt(ctx, sqliteutil.Update("user_items").Set("age", 18).Where(sqliteutil.Eq("name", "Bob")))
+	assert.NoError(t, err)
+	n, _ := res.RowsAffected()
+	assert.Equal(t, int64(1), n)
+
+	rows, err := db.QueryStmt(ctx, sqliteutil.Select("name").From("user_items").
+		Where(sqliteutil.Between("age", 18, 35)).OrderBy("name"))
+	assert.NoError(t, err)
+	var names []string
+	for rows.Next() {
+		var name string
+		assert.NoError(t, rows.Scan(&name))
+		names = append(names, name)
+	}
+	rows.Close()
+	assert.Equal(t, []string{"Alice", "Bob"}, names)
+
+	// 拼写错误的列名报错,而不是被当作字符串常量比较
+	_, err = db.ExecStmt(ctx, sqliteutil.DeleteFrom("user_items").Where(sqliteutil.Ne("nmae", "Alice")))
+	assert.ErrorContains(t, err, "no such column: nmae")
+	var total int
+	assert.NoError(t, db.QueryRow(ctx, "user_items", []string{"count(*)"}, "").Scan(&total))
+	assert.Equal(t, 3, total)
+
+	// 聚合列和分组过滤
+	_, err = db.ExecStmt(ctx, sqliteutil.Insert("user_items").Columns("name", "age").Values("Alice", 31))
+	assert.NoError(t, err)
+	rows, err = db.QueryStmt(ctx, sqliteutil.Select("name").SelectExpr(sqliteutil.Expr("count(*)"), "c").
+		From("user_items").GroupBy("name").Having(sqliteutil.Expr("count(*) > ?", 1)))
+	assert.NoError(t, err)
+	var groups []string
+	for rows.Next() {
+		var name string
+		var c int
+		assert.NoError(t, rows.Scan(&name, &c))
+		groups = append(groups, fmt.Sprint(name, ":", c))
+	}
+	rows.Close()
+	assert.Equal(t, []string{"Alice:2"}, groups)
+
+	_, err = db.ExecStmt(ctx, sqliteutil.DeleteFrom("user_items").Where(sqliteutil.Not(sqliteutil.Lt("age", 40))))
+	assert.NoError(t, err)
+	row, err := db.QueryRowStmt(ctx, sqliteutil.Select("id").From("user_items").Where(sqliteutil.Like("name", "Robert%")))
+	assert.NoError(t, err)
+	assert.ErrorIs(t, row.Scan(new(int)), sql.ErrNoRows)
+}
+
+type Address struct {
+	City string `json:"city"`
+	Zip  string `json:"zip"`
+}
+
+type Timestamps struct {
+	CreatedAt time.Time `db:"created_at"`
+}
+
+type Member struct {
+	ID       int64             `db:"id,pk,autoincrement"`
+	Name     string            `db:"name"`
+	Nickname *string           `db:"nickname"`
+	Score    float64           `db:"score,omitempty"`
+	Active   bool              `db:"active"`
+	Address  Address           `db:"address"`
+	Tags     []string          `db:"tags"`
+	Extra    map[string]string `db:"extra,omitempty"`
+	Note     sql.NullString    `db:"note"`
+	Ignored  string            `db:"-"`
+	Timestamps
+}
+
+func TestSqliteStructMapping(t *testing.T) {
+	db := &sqliteutil.DBManager{}
+	assert.NoError(t, db.Init(filepath.Join(t.TempDir(), "mapping.db")))
+	defer db.Close()
+	ctx := context.Background()
+	_, err := db.Execute(ctx, `CREATE TABLE members (
+		id INTEGER PRIMARY KEY AUTOINCREMENT, name TEXT, nickname TEXT, score REAL DEFAULT 60,
+		active INTEGER, address TEXT, tags TEXT, extra TEXT, note TEXT, created_at DATETIME)`)
+	assert.NoError(t, err)
+
+	created := time.Date(2024, 3, 1, 8, 30, 0, 0, time.UTC)
+	alice := &Member{
+		Name:       "Alice",
+		Active:     true,
+		Address:    Address{City: "Shanghai", Zip: "200000"},
+		Tags:       []string{"vip"},
+		Timestamps: Timestamps{CreatedAt: created},
+	}
+	id, err := db.InsertStruct(ctx, "members", alice)
+	assert.NoError(t, err)
+	assert.Equal(t, id, alice.ID)
+
+	nick := "bobby"
+	_, err = db.InsertStruct(ctx, "members", Member{Name: "Bob", Nickname: &nick, Score: 90, Note: sql.NullString{String: "n", Valid: true}})
+	assert.NoError(t, err)
+
+	got, err := sqliteutil.QueryOne[Member](ctx, db, "SELECT * FROM members WHERE id = ?", alice.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "Shanghai", got.Address.City)
+	assert.Equal(t, []string{"vip"}, got.Tags)
+	assert.True(t, got.Active)
+	assert.Nil(t, got.Nickname)
+	assert.Equal(t, 60.0, got.Score) // omitempty 使用列默认值
+	assert.True(t, created.Equal(got.CreatedAt))
+	assert.False(t, got.Note.Valid)
+
+	got.Score = 75
+	got.Address.City = "Beijing"
+	got.Ignored = "x"
+	n, err := db.UpdateStruct(ctx, "members", got)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+
+	members, err := sqliteutil.QueryStructs[Member](ctx, db, "SELECT id, name, nickname, score, address, 1 AS unknown FROM members ORDER BY id")
+	assert.NoError(t, err)
+	assert.Len(t, members, 2)
+	assert.Equal(t, "Beijing", members[0].Address.City)
+	assert.Equal(t, 75.0, members[0].Score)
+	assert.Equal(t, "bobby", *members[1].Nickname)
+
+	_, err = sqliteutil.QueryOne[Member](ctx, db, "SELECT * FROM members WHERE id = ?", 99)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	// 数值列按值转换,有损转换返回错误
+	type Stat struct {
+		ID    uint64 `db:"id,pk,autoincrement"`
+		Count int8   `db:"count"`
+		Ratio uint   `db:"ratio"`
+	}
+	_, err = db.Execute(ctx, "CREATE TABLE stats (id INTEGER PRIMARY KEY AUTOINCREMENT, count, ratio)")
+	assert.NoError(t, err)
+	stat := &Stat{Count: 3, Ratio: 1}
+	id, err = db.InsertStruct(ctx, "stats", stat)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(id), stat.ID)
+	got2, err := sqliteutil.QueryOne[Stat](ctx, db, "SELECT id, 4.0 AS count, 2 AS ratio FROM stats")
+	assert.NoError(t, err)
+	assert.Equal(t, Stat{ID: stat.ID, Count: 4, Ratio: 2}, got2)
+	for _, q := range []string{
+		"SELECT 2.5 AS count",
+		"SELECT 300 AS count",
+		"SELECT -1 AS ratio",
+		"SELECT 1e30 AS ratio",
+	} {
+		_, err = sqliteutil.QueryOne[Stat](ctx, db, q)
+		assert.ErrorContains(t, err, "without loss", q)
+	}
+	_, err = db.InsertStruct(ctx, "members", 1)
+	assert.ErrorIs(t, err, sqliteutil.ErrNotStruct)
+	_, err = db.UpdateStruct(ctx, "members", Address{})
+	assert.ErrorIs(t, err, sqliteutil.ErrNoPrimaryKey)
+}
+
+func TestSqliteMigrator(t *testing.T) {
+	db := &sqliteutil.DBManager{}
+	assert.NoError(t, db.Init(filepath.Join(t.TempDir(), "migrate.db")))
+	defer db.Close()
+	ctx := context.Background()
+
+	fsys := fstest.MapFS{
+		"migrations/0001_create_users.up.sql":   {Data: []byte("CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT);")},
+		"migrations/0001_create_users.down.sql": {Data: []byte("DROP TABLE users;")},
+		"migrations/0002_add_email.up.sql":      {Data: []byte("ALTER TABLE users ADD COLUMN email TEXT;\nCREATE INDEX idx_users_email ON users(email);")},
+		"migrations/0002_add_email.down.sql":    {Data: []byte("DROP INDEX idx_users_email;\nALTER TABLE users DROP COLUMN email;")},
+		"migrations/0003_seed.up.sql":           {Data: []byte("INSERT INTO users (name, email) VALUES ('admin', 'a@example.com');")},
+		"migrations/README.md":                  {Data: []byte("ignored")},
+	}
+	m, err := sqliteutil.NewMigrator(db, fsys, "migrations")
+	assert.NoError(t, err)
+	assert.Len(t, m.Migrations(), 3)
+
+	n, err := m.Up(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, n)
+	v, _ := m.Version(ctx)
+	assert.Equal(t, int64(3), v)
+	n, err = m.Up(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, n)
+
+	// 没有 down 文件时回滚失败
+	_, err = m.Down(ctx, 1)
+	assert.ErrorIs(t, err, sqliteutil.ErrNoDownMigration)
+
+	_, err = db.Execute(ctx, "DELETE FROM schema_migrations WHERE version = 3")
+	assert.NoError(t, err)
+	n, err = m.Goto(ctx, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+	cols, _ := db.GetTableInfo(ctx, "users")
+	assert.Len(t, cols, 2)
+
+	status, err := m.Status(ctx)
+	assert.NoError(t, err)
+	assert.True(t, status[0].Applied)
+	assert.False(t, status[1].Applied)
+	assert.Equal(t, "add_email", status[1].Name)
+
+	n, err = m.Down(ctx, 5)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+	exists, _ := db.TableExists(ctx, "users")
+	assert.False(t, exists)
+
+	// 失败的迁移整体回滚
+	bad := fstest.MapFS{
+		"m/1_ok.up.sql":     {Data: []byte("CREATE TABLE a (id INTEGER);")},
+		"m/2_broken.up.sql": {Data: []byte("CREATE TABLE b (id INTEGER); SELECT * FROM missing;")},
+	}
+	mb, err := sqliteutil.NewMigrator(db, bad, "m", sqliteutil.WithMigrationsTable("other_migrations"))
+	assert.NoError(t, err)
+	n, err = mb.Up(ctx)
+	assert.Error(t, err)
+	assert.Equal(t, 1, n)
+	exists, _ = db.TableExists(ctx, "b")
+	assert.False(t, exists)
+	v, _ = mb.Version(ctx)
+	assert.Equal(t, int64(1), v)
+
+	// 其他进程持有锁时等待超时
+	_, err = db.Execute(ctx, "INSERT INTO schema_migrations_lock (id, owner, locked_at) VALUES (1, 'other', ?)", time.Now().UTC())
+	assert.NoError(t, err)
+	locked, err := sqliteutil.NewMigrator(db, fsys, "migrations", sqliteutil.WithLockTimeout(150*time.Millisecond))
+	assert.NoError(t, err)
+	_, err = locked.Up(ctx)
+	assert.ErrorIs(t, err, sqliteutil.ErrMigrationLocked)
+
+	// 超过 WithStaleLockAge 未刷新的锁可被抢占
+	_, err = db.Execute(ctx, "UPDATE schema_migrations_lock SET locked_at = ?", time.Now().UTC().Add(-2*time.Minute))
+	assert.NoError(t, err)
+	_, err = locked.Up(ctx)
+	assert.ErrorIs(t, err, sqliteutil.ErrMigrationLocked)
+	stale, err := sqliteutil.NewMigrator(db, fsys, "migrations", sqliteutil.WithLockTimeout(150*time.Millisecond), sqliteutil.WithStaleLockAge(time.Minute))
+	assert.NoError(t, err)
+	n, err = stale.Up(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, n)
+
+	// 不存在的目标版本
+	n, err = stale.Goto(ctx, 7)
+	assert.ErrorIs(t, err, sqliteutil.ErrUnknownMigration)
+	assert.Equal(t, 0, n)
+	v, _ = stale.Version(ctx)
+	assert.Equal(t, int64(3), v)
+
+	// 每个迁移执行前刷新锁,锁被抢占后停止
+	heartbeat := fstest.MapFS{
+		"h/1_age.up.sql":   {Data: []byte("UPDATE h_migrations_lock SET locked_at = '2000-01-01 00:00:00';")},
+		"h/2_check.up.sql": {Data: []byte("CREATE TABLE lock_seen AS SELECT locked_at FROM h_migrations_lock;")},
+		"h/3_steal.up.sql": {Data: []byte("UPDATE h_migrations_lock SET owner = 'other';")},
+		"h/4_never.up.sql": {Data: []byte("CREATE TABLE never (id INTEGER);")},
+	}
+	mh, err := sqliteutil.NewMigrator(db, heartbeat, "h", sqliteutil.WithMigrationsTable("h_migrations"))
+	assert.NoError(t, err)
+	n, err = mh.Up(ctx)
+	assert.ErrorIs(t, err, sqliteutil.ErrMigrationLocked)
+	assert.Equal(t, 3, n)
+	var seen string
+	assert.NoError(t, db.QueryRow(ctx, "lock_seen", []string{"locked_at"}, "").Scan(&seen))
+	assert.Greater(t, seen, "2001")
+	exists, _ = db.TableExists(ctx, "never")
+	assert.False(t, exists)
+
+	_, err = sqliteutil.NewMigrator(db, fstest.MapFS{"x/1_a.down.sql": {Data: []byte("")}}, "x")
+	assert.Error(t, err)
+}
+
+func TestSqliteRegistry(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	audit, err := sqliteutil.Open("audit", filepath.Join(dir, "audit.db"))
+	assert.NoError(t, err)
+	defer sqliteutil.CloseAll()
+	_, err = audit.Execute(ctx, "CREATE TABLE logs (user_id INTEGER, action TEXT)")
+	assert.NoError(t, err)
+	_, err = audit.Execute(ctx, "INSERT INTO logs VALUES (1, 'login'), (1, 'logout'), (2, 'login')")
+	assert.NoError(t, err)
+
+	assert.Same(t, audit, sqliteutil.Get("audit"))
+	assert.Same(t, sqliteutil.GetInstance(), sqliteutil.Get(sqliteutil.DefaultName))
+	assert.Nil(t, sqliteutil.Get("missing"))
+	assert.Equal(t, []string{"audit"}, sqliteutil.Names())
+	assert.ErrorIs(t, sqliteutil.Register("audit", audit), sqliteutil.ErrAlreadyRegistered)
+	assert.ErrorIs(t, sqliteutil.Register(sqliteutil.DefaultName, audit), sqliteutil.ErrReservedName)
+
+	app, err := sqliteutil.NewDBManager(filepath.Join(dir, "main.db"), sqliteutil.WithPoolSize(2, 2))
+	assert.NoError(t, err)
+	defer app.Close()
+	_, err = app.Execute(ctx, "CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT)")
+	assert.NoError(t, err)
+	_, err = app.Execute(ctx, "INSERT INTO users VALUES (1, 'alice'), (2, 'bob')")
+	assert.NoError(t, err)
+
+	// Attach 时持有未关闭的 Rows,该连接归还后不能以未附加的状态被复用
+	open, err := app.Query(ctx, "users", []string{"id"}, "")
+	assert.NoError(t, err)
+	assert.NoError(t, app.Attach(ctx, "audit", filepath.Join(dir, "audit.db")))
+	assert.NoError(t, open.Close())
+	assert.ErrorIs(t, app.Attach(ctx, "audit", filepath.Join(dir, "audit.db")), sqliteutil.ErrAlreadyAttached)
+	assert.Equal(t, []sqliteutil.Attachment{{Schema: "audit", Path: filepath.Join(dir, "audit.db")}}, app.Attached())
+
+	// 并发查询使用多个连接,每个连接都应已附加
+	query := "SELECT u.name, count(*) AS n FROM main.users u JOIN audit.logs l ON l.user_id = u.id GROUP BY u.name ORDER BY u.name"
+	type row struct {
+		Name string `db:"name"`
+		N    int    `db:"n"`
+	}
+	done := make(chan error, 8)
+	for i := 0; i < 8; i++ {
+		go func() {
+			rows, err := sqliteutil.QueryStructs[row](ctx, app, query)
+			if err == nil && (len(rows) != 2 || rows[0].N != 2) {
+				err = fmt.Errorf("unexpected rows %v", rows)
+			}
+			done <- err
+		}()
+	}
+	for i := 0; i < 8; i++ {
+		assert.NoError(t, <-done)
+	}
+
+	open, err = app.Query(ctx, "users", []string{"id"}, "")
+	assert.NoError(t, err)
+	assert.NoError(t, app.Detach(ctx, "audit"))
+	assert.NoError(t, open.Close())
+	assert.ErrorIs(t, app.Detach(ctx, "audit"), sqliteutil.ErrNotAttached)
+	assert.Empty(t, app.Attached())
+	for i := 0; i < 8; i++ {
+		_, err := sqliteutil.QueryStructs[row](ctx, app, query)
+		assert.Error(t, err)
+	}
+
+	// 初始化时附加
+	report, err := sqliteutil.NewDBManager(filepath.Join(dir, "report.db"), sqliteutil.WithAttach("audit", filepath.Join(dir, "audit.db")))
+	assert.NoError(t, err)
+	defer report.Close()
+	count, err := sqliteutil.QueryOne[row](ctx, report, "SELECT 'all' AS name, count(*) AS n FROM audit.logs")
+	assert.NoError(t, err)
+	assert.Equal(t, 3, count.N)
+
+	// 每个实例使用独立的连接器,不会向 database/sql 注册驱动
+	drivers := len(sql.Drivers())
+	for i := 0; i < 3; i++ {
+		tmp, err := sqliteutil.NewDBManager(filepath.Join(dir, "tmp.db"))
+		assert.NoError(t, err)
+		assert.NoError(t, tmp.Close())
+	}
+	assert.Len(t, sql.Drivers(), drivers)
+
+	assert.Same(t, audit, sqliteutil.Unregister("audit"))
+	assert.Empty(t, sqliteutil.Names())
+	assert.NoError(t, audit.Close())
+}
+
+func TestSqliteSingleWriter(t *testing.T) {
+	db, err := sqliteutil.NewDBManager(filepath.Join(t.TempDir(), "writer.db"),
+		sqliteutil.WithWAL(),
+		sqliteutil.WithBusyTimeout(2*time.Second),
+		sqliteutil.WithForeignKeys(true),
+		sqliteutil.WithCacheSize(-4096),
+		sqliteutil.WithSingleWriter(),
+	)
+	assert.NoError(t, err)
+	defer db.Close()
+	ctx := context.Background()
+
+	type pragmas struct {
+		JournalMode string `db:"journal_mode"`
+		Synchronous int    `db:"synchronous"`
+		BusyTimeout int    `db:"timeout"`
+		ForeignKeys int    `db:"foreign_keys"`
+		CacheSize   int    `db:"cache_size"`
+	}
+	p, err := sqliteutil.QueryOne[pragmas](ctx, db, `SELECT
+		(SELECT journal_mode FROM pragma_journal_mode) AS journal_mode,
+		(SELECT synchronous FROM pragma_synchronous) AS synchronous,
+		(SELECT timeout FROM pragma_busy_timeout) AS timeout,
+		(SELECT foreign_keys FROM pragma_foreign_keys) AS foreign_keys,
+		(SELECT cache_size FROM pragma_cache_size) AS cache_size`)
+	assert.NoError(t, err)
+	assert.Equal(t, pragmas{JournalMode: "wal", Synchronous: 1, BusyTimeout: 2000, ForeignKeys: 1, CacheSize: -4096}, p)
+
+	_, err = db.Execute(ctx, "CREATE TABLE counters (id INTEGER PRIMARY KEY, n INTEGER)")
+	assert.NoError(t, err)
+
+	// 并发写入由写连接串行执行,不会出现 database is locked
+	errs := make(chan error, 50)
+	for i := 0; i < 50; i++ {
+		go func(i int) {
+			_, err := db.Insert(ctx, "counters", map[string]interface{}{"id": i, "n": i})
+			errs <- err
+		}(i)
+	}
+	for i := 0; i < 50; i++ {
+		assert.NoError(t, <-errs)
+	}
+
+	// 写连接被事务占用时,读操作不受影响,排队的写操作在 ctx 超时后返回
+	started, release := make(chan struct{}), make(chan struct{})
+	txDone := make(chan error, 1)
+	go func() {
+		txDone <- db.Transaction(ctx, func(tx *sql.Tx) error {
+			if _, err := tx.Exec("UPDATE counters SET n = n + 1"); err != nil {
+				return err
+			}
+			close(started)
+			<-release
+			return nil
+		})
+	}()
+	<-started
+
+	type total struct {
+		Sum int `db:"total"`
+	}
+	sum, err := sqliteutil.QueryOne[total](ctx, db, "SELECT sum(n) AS total FROM counters")
+	assert.NoError(t, err)
+	assert.Equal(t, 1225, sum.Sum)
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	_, err = db.Execute(timeoutCtx, "DELETE FROM counters")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	close(release)
+	assert.NoError(t, <-txDone)
+	sum, err = sqliteutil.QueryOne[total](ctx, db, "SELECT sum(n) AS total FROM counters")
+	assert.NoError(t, err)
+	assert.Equal(t, 1275, sum.Sum)
+
+	_, err = sqliteutil.NewDBManager(filepath.Join(t.TempDir(), "bad.db"), sqliteutil.WithJournalMode("wal; DROP TABLE x"))
+	assert.ErrorIs(t, err, sqliteutil.ErrInvalidPragma)
+}
+
+func TestSqliteUpsert(t *testing.T) {
+	db, err := sqliteutil.NewDBManager(filepath.Join(t.TempDir(), "upsert.db"), sqliteutil.WithMaxVariables(100))
+	assert.NoError(t, err)
+	defer db.Close()
+	ctx := context.Background()
+
+	_, err = db.Execute(ctx, "CREATE TABLE stock (sku TEXT PRIMARY KEY, name TEXT, qty INTEGER)")
+	assert.NoError(t, err)
+
+	n, err := db.Upsert(ctx, "stock", map[string]interface{}{"sku": "a", "name": "apple", "qty": 1}, []string{"sku"}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+	_, err = db.Upsert(ctx, "stock", map[string]interface{}{"sku": "a", "name": "ignored", "qty": 5}, []string{"sku"}, []string{"qty"})
+	assert.NoError(t, err)
+
+	type item struct {
+		Sku  string `db:"sku"`
+		Name string `db:"name"`
+		Qty  int    `db:"qty"`
+	}
+	a, err := sqliteutil.QueryOne[item](ctx, db, "SELECT * FROM stock WHERE sku = ?", "a")
+	assert.NoError(t, err)
+	assert.Equal(t, item{"a", "apple", 5}, a)
+
+	_, err = db.Upsert(ctx, "stock", map[string]interface{}{"sku": "b"}, nil, nil)
+	assert.ErrorIs(t, err, sqliteutil.ErrInvalidStatement)
+
+	// 3列、每条语句最多100个参数,1000行拆分为31条语句
+	columns := []string{"sku", "name", "qty"}
+	rows := make([][]interface{}, 1000)
+	for i := range rows {
+		rows[i] = []interface{}{fmt.Sprintf("sku-%04d", i), "item", i}
+	}
+	n, err = db.BatchInsert(ctx, "stock", columns, rows)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1000), n)
+
+	for i := range rows {
+		rows[i][2] = 1
+	}
+	rows = append(rows, []interface{}{"new", "fresh", 1})
+	n, err = db.BatchUpsert(ctx, "stock", columns, rows, []string{"sku"}, []string{"qty"})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1001), n)
+
+	type total struct {
+		Count int `db:"c"`
+		Sum   int `db:"s"`
+	}
+	sum, err := sqliteutil.QueryOne[total](ctx, db, "SELECT count(*) AS c, sum(qty) AS s FROM stock")
+	assert.NoError(t, err)
+	assert.Equal(t, total{1002, 1006}, sum)
+
+	// 任一批次失败时整个事务回滚
+	_, err = db.BatchInsert(ctx, "stock", columns, [][]interface{}{{"x1", "x", 1}, {"a", "dup", 1}})
+	assert.Error(t, err)
+	_, err = sqliteutil.QueryOne[item](ctx, db, "SELECT * FROM stock WHERE sku = ?", "x1")
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	_, err = db.BatchInsert(ctx, "stock", columns, [][]interface{}{{"x2", "x"}})
+	assert.ErrorIs(t, err, sqliteutil.ErrInvalidStatement)
+}
+
+func TestSqliteFTS(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fts.db")
+	db, err := sqliteutil.NewDBManager(path)
+	assert.NoError(t, err)
+	defer db.Close()
+	ctx := context.Background()
+
+	if ok, _ := db.FTS5Enabled(ctx); !ok {
+		_, err := sqliteutil.NewFTS(ctx, db, "docs_fts", []string{"title"})
+		assert.ErrorIs(t, err, sqliteutil.ErrFTS5Unavailable)
+		t.Skip("FTS5 not compiled in, run with -tags sqlite_fts5")
+	}
+
+	_, err = db.Execute(ctx, "CREATE TABLE docs (id INTEGER PRIMARY KEY, title TEXT, body TEXT)")
+	assert.NoError(t, err)
+	_, err = db.Execute(ctx, `INSERT INTO docs (title, body) VALUES
+		('SQLite tuning', 'Enable WAL mode and busy timeout for concurrent writers'),
+		('Bolt storage', 'Buckets and cursors in an embedded key value store')`)
+	assert.NoError(t, err)
+
+	// 关联内容表: 已有数据被索引,之后的增删改由触发器同步
+	fts, err := sqliteutil.NewFTS(ctx, db, "docs_fts", []string{"title", "body"},
+		sqliteutil.WithContentTable("docs", "id"), sqliteutil.WithTokenizer("porter unicode61"), sqliteutil.WithPrefixIndex(2, 3))
+	assert.NoError(t, err)
+	_, err = db.Insert(ctx, "docs", map[string]interface{}{"title": "SQLite search", "body": "Full text search with ranking in SQLite"})
+	assert.NoError(t, err)
+
+	results, err := fts.Search(ctx, "sqlite", sqliteutil.WithHighlight("title"), sqliteutil.WithSnippet("body", 4), sqliteutil.WithWeights(10, 1))
+	assert.NoError(t, err)
+	if assert.Len(t, results, 2) {
+		assert.Equal(t, int64(3), results[0].RowID)
+		assert.Less(t, results[0].Rank, results[1].Rank)
+		assert.Equal(t, "<b>SQLite</b> search", results[0].Highlight)
+		assert.Contains(t, results[0].Snippet, "<b>SQLite</b>")
+		assert.Equal(t, "Full text search with ranking in SQLite", results[0].Values["body"])
+	}
+
+	_, err = db.Update(ctx, "docs", map[string]interface{}{"body": "Embedded buckets with sqlite export"}, "id = ?", 2)
+	assert.NoError(t, err)
+	_, err = db.Delete(ctx, "docs", "id = ?", 1)
+	assert.NoError(t, err)
+	n, err := fts.Count(ctx, "sqlite")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), n)
+	n, err = fts.Count(ctx, "writ*")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), n)
+	assert.NoError(t, fts.Rebuild(ctx))
+	assert.NoError(t, fts.Optimize(ctx))
+
+	_, err = fts.Search(ctx, "sqlite", sqliteutil.WithHighlight("missing"))
+	assert.ErrorIs(t, err, sqliteutil.ErrUnknownColumn)
+	assert.ErrorIs(t, fts.Index(ctx, 9, map[string]string{"title": "x"}), sqliteutil.ErrInvalidStatement)
+
+	// 独立表 + 中文按字切分
+	notes, err := sqliteutil.NewFTS(ctx, db, "notes_fts", []string{"content"}, sqliteutil.WithCJK())
+	assert.NoError(t, err)
+	assert.NoError(t, notes.Index(ctx, 1, map[string]string{"content": "使用SQLite实现全文检索功能"}))
+	assert.NoError(t, notes.Index(ctx, 2, map[string]string{"content": "数据库连接池和写锁"}))
+	assert.NoError(t, notes.Index(ctx, 2, map[string]string{"content": "数据库连接池与单写连接"}))
+	assert.ErrorIs(t, notes.Index(ctx, 3, map[string]string{"other": "x"}), sqliteutil.ErrUnknownColumn)
+
+	results, err = notes.Search(ctx, "检索", sqliteutil.WithHighlight("content"))
+	assert.NoError(t, err)
+	if assert.Len(t, results, 1) {
+		assert.Equal(t, int64(1), results[0].RowID)
+		assert.Equal(t, "使用SQLite实现全文<b>检索</b>功能", results[0].Highlight)
+		assert.Equal(t, "使用SQLite实现全文检索功能", results[0].Values["content"])
+	}
+	for query, want := range map[string]int64{"连接": 1, "写锁": 0, "sqlite AND 全文": 1, `"数据库连接"`: 1, "数据*": 1, "库 OR 功能": 2} {
+		n, err := notes.Count(ctx, query)
+		assert.NoError(t, err, query)
+		assert.Equal(t, want, n, query)
+	}
+	assert.NoError(t, notes.Remove(ctx, 1))
+	n, err = notes.Count(ctx, "检索")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), n)
+
+	// 关联内容表 + 中文
+	_, err = db.Execute(ctx, "CREATE TABLE posts (id INTEGER PRIMARY KEY, body TEXT)")
+	assert.NoError(t, err)
+	_, err = db.Execute(ctx, "INSERT INTO posts (body) VALUES ('今天天气很好'), (NULL)")
+	assert.NoError(t, err)
+	posts, err := sqliteutil.NewFTS(ctx, db, "posts_fts", []string{"body"}, sqliteutil.WithContentTable("posts", "id"), sqliteutil.WithCJK())
+	assert.NoError(t, err)
+	// 内容表上没有依赖自定义函数的触发器,其他工具也能写入,之后通过 Sync 同步
+	raw, err := sql.Open("sqlite3", path)
+	assert.NoError(t, err)
+	_, err = raw.Exec("UPDATE posts SET body = '明天可能下雨' WHERE id = 2")
+	assert.NoError(t, err)
+	_, err = raw.Exec("INSERT INTO posts (body) VALUES ('稍后删除')")
+	assert.NoError(t, err)
+	assert.NoError(t, raw.Close())
+	assert.NoError(t, posts.Sync(ctx, 2, 3))
+	_, err = db.Delete(ctx, "posts", "id = ?", 3)
+	assert.NoError(t, err)
+	assert.NoError(t, posts.Sync(ctx, 3))
+	n, err = posts.Count(ctx, "删除")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), n)
+	assert.NoError(t, fts.Sync(ctx, 1))
+	assert.ErrorIs(t, notes.Sync(ctx, 1), sqliteutil.ErrInvalidStatement)
+	results, err = posts.Search(ctx, "天气 OR 下雨", sqliteutil.WithSnippet("body", 8), sqliteutil.WithMarkers("[", "]", "…"))
+	assert.NoError(t, err)
+	assert.Len(t, results, 2)
+	results, err = posts.Search(ctx, "下雨", sqliteutil.WithSnippet("body", 8), sqliteutil.WithMarkers("[", "]", "…"))
+	assert.NoError(t, err)
+	if assert.Len(t, results, 1) {
+		assert.Equal(t, "明天可能[下雨]", results[0].Snippet)
+	}
+	assert.NoError(t, posts.Rebuild(ctx))
+	n, err = posts.Count(ctx, "天")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), n)
+
+	assert.NoError(t, posts.Drop(ctx))
+	_, err = db.Execute(ctx, "INSERT INTO posts (body) VALUES ('触发器已删除')")
+	assert.NoError(t, err)
+}
@@ -4,6 +4,8 @@ import (
 	"errors"
 	"sync"
 	"time"
+
+	"github.com/wind959/ko-utils/clock"
 )
 
 // 错误定义
@@ -19,17 +21,38 @@ type Queue[T any] struct {
 	items     chan T
 	closeOnce sync.Once
 	closed    chan struct{}
+	clock     clock.Clock
+}
+
+// queueConfig 队列配置
+type queueConfig struct {
+	clock clock.Clock
+}
+
+// Option 队列配置选项
+type Option func(*queueConfig)
+
+// WithClock 设置超时和重试等待使用的时钟，测试中可传入 clock.NewFake
+func WithClock(c clock.Clock) Option {
+	return func(cfg *queueConfig) {
+		cfg.clock = clock.OrReal(c)
+	}
 }
 
 // NewQueue 创建队列
 // capacity: 队列容量，0=无缓冲（同步），>0=缓冲队列
-func NewQueue[T any](capacity int) *Queue[T] {
+func NewQueue[T any](capacity int, opts ...Option) *Queue[T] {
 	if capacity < 0 {
 		capacity = 0
 	}
+	cfg := &queueConfig{clock: clock.Real}
+	for _, opt := range opts {
+		opt(cfg)
+	}
 	return &Queue[T]{
 		items:  make(chan T, capacity),
 		closed: make(chan struct{}),
+		clock:  cfg.clock,
 	}
 }
 
@@ -61,7 +84,7 @@ func (q *Queue[T]) PutWithTimeout(item T, timeout time.Duration) error {
 		return q.TryPut(item)
 	}
 
-	timer := time.NewTimer(timeout)
+	timer := q.clock.NewTimer(timeout)
 	defer timer.Stop()
 
 	select {
@@ -69,7 +92,7 @@ func (q *Queue[T]) PutWithTimeout(item T, timeout time.Duration) error {
 		return nil
 	case <-q.closed:
 		return ErrQueueClosed
-	case <-timer.C:
+	case <-timer.C():
 		return ErrTimeout
 	}
 }
@@ -136,7 +159,7 @@ func (q *Queue[T]) GetWithTimeout(timeout time.Duration) (T, error) {
 		return q.TryGetSimple()
 	}
 
-	timer := time.NewTimer(timeout)
+	timer := q.clock.NewTimer(timeout)
 	defer timer.Stop()
 
 	select {
@@ -147,7 +170,7 @@ func (q *Queue[T]) GetWithTimeout(timeout time.Duration) (T, error) {
 		return item, nil
 	case <-q.closed:
 		return zero, ErrQueueClosed
-	case <-timer.C:
+	case <-timer.C():
 		return zero, ErrTimeout
 	}
 }
@@ -283,7 +306,7 @@ func (q *Queue[T]) RetryPut(item T, maxRetries int, delay time.Duration) error {
 		}
 
 		if i < maxRetries-1 {
-			time.Sleep(delay)
+			q.clock.Sleep(delay)
 		}
 	}
 	return ErrQueueFull
@@ -307,7 +330,7 @@ func (q *Queue[T]) ProcessWithRetry(
 			}
 
 			if retry < maxRetries-1 {
-				time.Sleep(retryDelay)
+				q.clock.Sleep(retryDelay)
 				continue
 			}
 
@@ -315,7 +338,7 @@ func (q *Queue[T]) ProcessWithRetry(
 			if putErr := q.Put(item); putErr != nil {
 				return putErr
 			}
-			time.Sleep(retryDelay)
+			q.clock.Sleep(retryDelay)
 		}
 	}
 }
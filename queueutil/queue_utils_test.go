@@ -6,6 +6,9 @@ import (
 	"log"
 	"testing"
 	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wind959/ko-utils/clock"
 )
 
 func TestQueueUtils(t *testing.T) {
@@ -117,3 +120,52 @@ func retryConsumer(q *Queue[string]) {
 		log.Printf("处理失败: %v", err)
 	}
 }
+
+func TestQueueTimeoutWithClock(t *testing.T) {
+	fake := clock.NewFake(time.Time{})
+	q := NewQueue[int](1, WithClock(fake))
+	defer q.Close()
+
+	// 队列已满,PutWithTimeout 在模拟时间到期后返回 ErrTimeout
+	assert.NoError(t, q.Put(1))
+	done := make(chan error, 1)
+	go func() { done <- q.PutWithTimeout(2, time.Hour) }()
+	fake.BlockUntil(1)
+	fake.Advance(time.Hour - time.Second)
+	assert.Equal(t, 1, fake.Waiters())
+	fake.Advance(time.Second)
+	assert.ErrorIs(t, <-done, ErrTimeout)
+
+	// 超时前有空位,不会等到超时
+	go func() { done <- q.PutWithTimeout(2, time.Hour) }()
+	fake.BlockUntil(1)
+	item, err := q.Get()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, item)
+	assert.NoError(t, <-done)
+	assert.Equal(t, 0, fake.Waiters())
+
+	// 队列为空,GetWithTimeout 超时
+	item, err = q.Get()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, item)
+	got := make(chan error, 1)
+	go func() {
+		_, err := q.GetWithTimeout(time.Minute)
+		got <- err
+	}()
+	fake.BlockUntil(1)
+	fake.Advance(time.Minute)
+	assert.ErrorIs(t, <-got, ErrTimeout)
+
+	// RetryPut 在重试之间按模拟时间等待
+	assert.NoError(t, q.Put(3))
+	start := fake.Now()
+	go func() { done <- q.RetryPut(4, 3, time.Second) }()
+	for i := 0; i < 2; i++ {
+		fake.BlockUntil(1)
+		fake.Advance(time.Second)
+	}
+	assert.ErrorIs(t, <-done, ErrQueueFull)
+	assert.Equal(t, 2*time.Second, fake.Now().Sub(start))
+}
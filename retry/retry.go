@@ -10,6 +10,8 @@ import (
 	"runtime"
 	"strings"
 	"time"
+
+	"github.com/wind959/ko-utils/clock"
 )
 
 const (
@@ -24,6 +26,7 @@ type RetryConfig struct {
 	context         context.Context
 	retryTimes      uint
 	backoffStrategy BackoffStrategy
+	clock           clock.Clock
 }
 
 // RetryFunc 被重试执行的函数
@@ -92,6 +95,13 @@ func RetryWithExponentialWithJitterBackoff(interval time.Duration, base uint64,
 	}
 }
 
+// RetryWithClock 设置等待退避间隔使用的时钟，测试中可传入 clock.NewFake
+func RetryWithClock(c clock.Clock) Option {
+	return func(rc *RetryConfig) {
+		rc.clock = clock.OrReal(c)
+	}
+}
+
 // Context 设置重试context参数
 func Context(ctx context.Context) Option {
 	return func(rc *RetryConfig) {
@@ -104,6 +114,7 @@ func Retry(retryFunc RetryFunc, opts ...Option) error {
 	config := &RetryConfig{
 		retryTimes: DefaultRetryTimes,
 		context:    context.TODO(),
+		clock:      clock.Real,
 	}
 
 	for _, opt := range opts {
@@ -121,7 +132,7 @@ func Retry(retryFunc RetryFunc, opts ...Option) error {
 		err := retryFunc()
 		if err != nil {
 			select {
-			case <-time.After(config.backoffStrategy.CalculateInterval()):
+			case <-config.clock.After(config.backoffStrategy.CalculateInterval()):
 			case <-config.context.Done():
 				return errors.New("retry is cancelled")
 			}
@@ -0,0 +1,63 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wind959/ko-utils/clock"
+)
+
+func TestRetryWithClock(t *testing.T) {
+	fake := clock.NewFake(time.Time{})
+	start := fake.Now()
+
+	// 指数退避,间隔依次为 1s、2s、4s,第4次调用成功
+	calls := 0
+	done := make(chan error, 1)
+	go func() {
+		done <- Retry(func() error {
+			calls++
+			if calls < 4 {
+				return errors.New("fail")
+			}
+			return nil
+		}, RetryTimes(5), RetryWithExponentialWithJitterBackoff(time.Second, 2, 0), RetryWithClock(fake))
+	}()
+
+	for _, interval := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second} {
+		fake.BlockUntil(1)
+		fake.Advance(interval - time.Millisecond)
+		assert.Equal(t, 1, fake.Waiters(), "retried before %v elapsed", interval)
+		fake.Advance(time.Millisecond)
+	}
+	assert.NoError(t, <-done)
+	assert.Equal(t, 4, calls)
+	assert.Equal(t, 7*time.Second, fake.Now().Sub(start))
+}
+
+func TestRetryWithClockExhausted(t *testing.T) {
+	fake := clock.NewFake(time.Time{})
+	done := make(chan error, 1)
+	go func() {
+		done <- Retry(func() error { return errors.New("fail") }, RetryTimes(3), RetryWithLinearBackoff(time.Minute), RetryWithClock(fake))
+	}()
+	for i := 0; i < 3; i++ {
+		fake.BlockUntil(1)
+		fake.Advance(time.Minute)
+	}
+	err := <-done
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "after 3 times retry")
+
+	// 等待退避间隔时取消
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		done <- Retry(func() error { return errors.New("fail") }, RetryWithLinearBackoff(time.Hour), RetryWithClock(fake), Context(ctx))
+	}()
+	fake.BlockUntil(1)
+	cancel()
+	assert.EqualError(t, <-done, "retry is cancelled")
+}
@@ -0,0 +1,305 @@
+package scheduler
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxSearchYears Next/Prev 最多搜索的年数,超过后认为表达式永远不会触发(如 2月30日)
+const maxSearchYears = 5
+
+var ErrInvalidSpec = errors.New("scheduler: invalid cron spec")
+
+// Schedule 调度计划,计算给定时间之后/之前的触发时间
+type Schedule interface {
+	// Next 返回严格晚于t的下一次触发时间,不会再触发时返回零值
+	Next(t time.Time) time.Time
+	// Prev 返回严格早于t的上一次触发时间,不存在时返回零值
+	Prev(t time.Time) time.Time
+}
+
+// CronSchedule 由cron表达式解析得到的调度计划,每个字段用位图表示
+type CronSchedule struct {
+	second, minute, hour, dom, month, dow uint64
+	location                              *time.Location
+}
+
+// EverySchedule 固定间隔的调度计划,对应 @every 描述符
+type EverySchedule struct {
+	Interval time.Duration
+}
+
+// fieldBounds cron字段的取值范围
+type fieldBounds struct {
+	min, max uint
+	names    map[string]uint
+}
+
+var (
+	secondBounds = fieldBounds{0, 59, nil}
+	minuteBounds = fieldBounds{0, 59, nil}
+	hourBounds   = fieldBounds{0, 23, nil}
+	domBounds    = fieldBounds{1, 31, nil}
+	monthBounds  = fieldBounds{1, 12, map[string]uint{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	// 星期允许 7 表示周日,解析后归并到 0
+	dowBounds = fieldBounds{0, 7, map[string]uint{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+// starBit 标记字段为 * 或 ?,用于日期与星期的"或"语义判断
+const starBit = 1 << 63
+
+// descriptors 预定义描述符
+var descriptors = map[string]string{
+	"@yearly":   "0 0 0 1 1 *",
+	"@annually": "0 0 0 1 1 *",
+	"@monthly":  "0 0 0 1 * *",
+	"@weekly":   "0 0 0 * * 0",
+	"@daily":    "0 0 0 * * *",
+	"@midnight": "0 0 0 * * *",
+	"@hourly":   "0 0 * * * *",
+}
+
+// ParseCron 解析cron表达式,使用本地时区
+//
+// 支持5字段(分 时 日 月 周)和6字段(秒 分 时 日 月 周)格式,
+// @yearly、@monthly、@weekly、@daily、@hourly、@every <duration> 描述符,
+// 以及 CRON_TZ=Asia/Shanghai 或 TZ=Asia/Shanghai 前缀指定时区
+func ParseCron(spec string) (Schedule, error) {
+	return ParseCronInLocation(spec, time.Local)
+}
+
+// ParseCronInLocation 解析cron表达式,未通过 CRON_TZ 前缀指定时区时使用loc
+func ParseCronInLocation(spec string, loc *time.Location) (Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	if loc == nil {
+		loc = time.Local
+	}
+
+	if strings.HasPrefix(spec, "CRON_TZ=") || strings.HasPrefix(spec, "TZ=") {
+		i := strings.IndexAny(spec, " \t")
+		if i < 0 {
+			return nil, fmt.Errorf("%w: missing fields after time zone", ErrInvalidSpec)
+		}
+		name := spec[strings.Index(spec, "=")+1 : i]
+		l, err := time.LoadLocation(name)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unknown time zone %q", ErrInvalidSpec, name)
+		}
+		loc, spec = l, strings.TrimSpace(spec[i:])
+	}
+
+	if strings.HasPrefix(spec, "@") {
+		if rest, ok := strings.CutPrefix(spec, "@every "); ok {
+			d, err := time.ParseDuration(strings.TrimSpace(rest))
+			if err != nil || d <= 0 {
+				return nil, fmt.Errorf("%w: bad @every duration %q", ErrInvalidSpec, rest)
+			}
+			return EverySchedule{Interval: d}, nil
+		}
+		expr, ok := descriptors[strings.ToLower(spec)]
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown descriptor %q", ErrInvalidSpec, spec)
+		}
+		spec = expr
+	}
+
+	fields := strings.Fields(spec)
+	switch len(fields) {
+	case 5:
+		fields = append([]string{"0"}, fields...)
+	case 6:
+	default:
+		return nil, fmt.Errorf("%w: expected 5 or 6 fields, got %d", ErrInvalidSpec, len(fields))
+	}
+
+	s := &CronSchedule{location: loc}
+	targets := []*uint64{&s.second, &s.minute, &s.hour, &s.dom, &s.month, &s.dow}
+	bounds := []fieldBounds{secondBounds, minuteBounds, hourBounds, domBounds, monthBounds, dowBounds}
+	for i, f := range fields {
+		bits, err := parseField(f, bounds[i])
+		if err != nil {
+			return nil, err
+		}
+		*targets[i] = bits
+	}
+	// 7 和 0 都表示周日
+	if s.dow&(1<<7) != 0 {
+		s.dow = s.dow&^(1<<7) | 1
+	}
+	return s, nil
+}
+
+// MustParseCron 解析cron表达式,失败时panic
+func MustParseCron(spec string) Schedule {
+	s, err := ParseCron(spec)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// Next 返回严格晚于t的下一次触发时间
+func (s *CronSchedule) Next(t time.Time) time.Time {
+	origLoc := t.Location()
+	t = t.In(s.location).Add(time.Second - time.Duration(t.Nanosecond())).Truncate(time.Second)
+	yearLimit := t.Year() + maxSearchYears
+
+	for t.Year() <= yearLimit {
+		loc := t.Location()
+		switch {
+		case s.month&(1<<uint(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+		case !s.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+		case s.hour&(1<<uint(t.Hour())) == 0:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, loc).Add(time.Hour)
+		case s.minute&(1<<uint(t.Minute())) == 0:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), 0, 0, loc).Add(time.Minute)
+		case s.second&(1<<uint(t.Second())) == 0:
+			t = t.Add(time.Second)
+		default:
+			return t.In(origLoc)
+		}
+	}
+	return time.Time{}
+}
+
+// Prev 返回严格早于t的上一次触发时间
+func (s *CronSchedule) Prev(t time.Time) time.Time {
+	origLoc := t.Location()
+	t = t.In(s.location)
+	if t.Nanosecond() > 0 {
+		t = t.Truncate(time.Second)
+	} else {
+		t = t.Add(-time.Second)
+	}
+	yearLimit := t.Year() - maxSearchYears
+
+	for t.Year() >= yearLimit {
+		loc := t.Location()
+		switch {
+		case s.month&(1<<uint(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, loc).Add(-time.Second)
+		case !s.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc).Add(-time.Second)
+		case s.hour&(1<<uint(t.Hour())) == 0:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, loc).Add(-time.Second)
+		case s.minute&(1<<uint(t.Minute())) == 0:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), 0, 0, loc).Add(-time.Second)
+		case s.second&(1<<uint(t.Second())) == 0:
+			t = t.Add(-time.Second)
+		default:
+			return t.In(origLoc)
+		}
+	}
+	return time.Time{}
+}
+
+// Location 返回调度计划使用的时区
+func (s *CronSchedule) Location() *time.Location {
+	return s.location
+}
+
+// dayMatches 日期与星期的匹配规则: 任一字段为 * 时取"与",否则取"或"
+func (s *CronSchedule) dayMatches(t time.Time) bool {
+	domMatch := s.dom&(1<<uint(t.Day())) != 0
+	dowMatch := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.dom&starBit != 0 || s.dow&starBit != 0 {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
+
+// Next 返回t之后一个间隔的时间
+func (e EverySchedule) Next(t time.Time) time.Time {
+	return t.Add(e.Interval)
+}
+
+// Prev 返回t之前一个间隔的时间
+func (e EverySchedule) Prev(t time.Time) time.Time {
+	return t.Add(-e.Interval)
+}
+
+// parseField 解析单个cron字段,支持 * ? 列表 范围 步长 和名称
+func parseField(field string, b fieldBounds) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		bit, err := parseRange(part, b)
+		if err != nil {
+			return 0, err
+		}
+		bits |= bit
+	}
+	return bits, nil
+}
+
+// parseRange 解析 a、a-b、*、a/n、a-b/n、*/n 形式的片段
+func parseRange(expr string, b fieldBounds) (uint64, error) {
+	var (
+		start, end, step uint = 0, 0, 1
+		extra            uint64
+	)
+
+	rangeExpr, stepExpr, hasStep := strings.Cut(expr, "/")
+	if hasStep {
+		n, err := strconv.ParseUint(stepExpr, 10, 8)
+		if err != nil || n == 0 {
+			return 0, fmt.Errorf("%w: bad step %q", ErrInvalidSpec, expr)
+		}
+		step = uint(n)
+	}
+
+	switch {
+	case rangeExpr == "*" || rangeExpr == "?":
+		start, end = b.min, b.max
+		if !hasStep {
+			extra = starBit
+		}
+	default:
+		lo, hi, isRange := strings.Cut(rangeExpr, "-")
+		var err error
+		if start, err = parseValue(lo, b); err != nil {
+			return 0, err
+		}
+		switch {
+		case isRange:
+			if end, err = parseValue(hi, b); err != nil {
+				return 0, err
+			}
+		case hasStep:
+			end = b.max
+		default:
+			end = start
+		}
+	}
+
+	if start < b.min || end > b.max || start > end {
+		return 0, fmt.Errorf("%w: %q out of range [%d, %d]", ErrInvalidSpec, expr, b.min, b.max)
+	}
+
+	var bits uint64
+	for i := start; i <= end; i += step {
+		bits |= 1 << i
+	}
+	return bits | extra, nil
+}
+
+// parseValue 解析数字或名称
+func parseValue(s string, b fieldBounds) (uint, error) {
+	if v, ok := b.names[strings.ToLower(s)]; ok {
+		return v, nil
+	}
+	n, err := strconv.ParseUint(s, 10, 8)
+	if err != nil {
+		return 0, fmt.Errorf("%w: bad value %q", ErrInvalidSpec, s)
+	}
+	return uint(n), nil
+}
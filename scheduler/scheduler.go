@@ -0,0 +1,429 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"runtime/debug"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/wind959/ko-utils/clock"
+	"github.com/wind959/ko-utils/retry"
+)
+
+// Job 定时任务,ctx 在调度器强制停止时被取消
+type Job func(ctx context.Context) error
+
+// EntryID 任务标识
+type EntryID int
+
+// OverlapPolicy 上一次执行尚未结束时再次触发的处理策略
+type OverlapPolicy int
+
+const (
+	// OverlapConcurrent 并发执行,默认策略
+	OverlapConcurrent OverlapPolicy = iota
+	// OverlapSkip 跳过本次触发
+	OverlapSkip
+	// OverlapQueue 排队,上一次执行结束后立即补跑
+	OverlapQueue
+)
+
+var (
+	ErrSchedulerStopped = errors.New("scheduler: scheduler is stopped")
+	ErrJobPanic         = errors.New("scheduler: job panicked")
+)
+
+// ErrorHandler 任务执行失败(含panic)时的回调
+type ErrorHandler func(id EntryID, name string, err error)
+
+// Entry 任务快照
+type Entry struct {
+	ID   EntryID
+	Name string
+	Next time.Time // 下一次触发时间
+	Prev time.Time // 上一次触发时间
+}
+
+// Option 调度器配置选项
+type Option func(*Scheduler)
+
+// JobOption 任务配置选项
+type JobOption func(*entry)
+
+// Scheduler 进程内任务调度器
+type Scheduler struct {
+	mu       sync.Mutex
+	entries  map[EntryID]*entry
+	nextID   EntryID
+	location *time.Location
+	onError  ErrorHandler
+	clock    clock.Clock
+
+	running bool
+	stopped bool
+	wake    chan struct{}
+	quit    chan struct{}
+	done    chan struct{}
+	ctx     context.Context
+	cancel  context.CancelFunc
+	jobs    sync.WaitGroup
+}
+
+// entry 内部任务
+type entry struct {
+	id        EntryID
+	name      string
+	schedule  Schedule
+	job       Job
+	overlap   OverlapPolicy
+	retryOpts []retry.Option
+	next      time.Time
+	prev      time.Time
+
+	mu      sync.Mutex
+	active  int // 正在执行的次数
+	pending int // 排队等待执行的次数
+}
+
+// WithLocation 设置解析cron表达式的默认时区,默认为本地时区
+func WithLocation(loc *time.Location) Option {
+	return func(s *Scheduler) {
+		if loc != nil {
+			s.location = loc
+		}
+	}
+}
+
+// WithClock 设置调度使用的时钟,测试中可传入 clock.NewFake 手动推进时间
+func WithClock(c clock.Clock) Option {
+	return func(s *Scheduler) {
+		s.clock = clock.OrReal(c)
+	}
+}
+
+// WithErrorHandler 设置任务失败回调,默认使用 log.Printf 输出
+func WithErrorHandler(handler ErrorHandler) Option {
+	return func(s *Scheduler) {
+		if handler != nil {
+			s.onError = handler
+		}
+	}
+}
+
+// WithJobName 设置任务名称,用于日志和错误回调
+func WithJobName(name string) JobOption {
+	return func(e *entry) {
+		e.name = name
+	}
+}
+
+// WithOverlapPolicy 设置任务的重叠执行策略
+func WithOverlapPolicy(policy OverlapPolicy) JobOption {
+	return func(e *entry) {
+		e.overlap = policy
+	}
+}
+
+// WithRetry 任务失败时使用 retry.Retry 重试,调度器停止时重试会被取消;退避等待默认使用 WithClock 设置的时钟
+func WithRetry(opts ...retry.Option) JobOption {
+	return func(e *entry) {
+		e.retryOpts = append([]retry.Option{}, opts...)
+	}
+}
+
+// New 创建调度器
+func New(opts ...Option) *Scheduler {
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &Scheduler{
+		entries:  make(map[EntryID]*entry),
+		location: time.Local,
+		clock:    clock.Real,
+		onError: func(id EntryID, name string, err error) {
+			log.Printf("scheduler: job %d(%s) failed: %v", id, name, err)
+		},
+		wake:   make(chan struct{}, 1),
+		quit:   make(chan struct{}),
+		done:   make(chan struct{}),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// AddJob 按cron表达式添加任务
+func (s *Scheduler) AddJob(spec string, job Job, opts ...JobOption) (EntryID, error) {
+	schedule, err := ParseCronInLocation(spec, s.location)
+	if err != nil {
+		return 0, err
+	}
+	return s.Schedule(schedule, job, opts...)
+}
+
+// AddFunc 按cron表达式添加无返回值的任务
+func (s *Scheduler) AddFunc(spec string, fn func(), opts ...JobOption) (EntryID, error) {
+	return s.AddJob(spec, func(context.Context) error {
+		fn()
+		return nil
+	}, opts...)
+}
+
+// Schedule 按自定义调度计划添加任务
+func (s *Scheduler) Schedule(schedule Schedule, job Job, opts ...JobOption) (EntryID, error) {
+	if schedule == nil || job == nil {
+		return 0, errors.New("scheduler: schedule and job must not be nil")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stopped {
+		return 0, ErrSchedulerStopped
+	}
+
+	s.nextID++
+	e := &entry{id: s.nextID, schedule: schedule, job: job}
+	for _, opt := range opts {
+		opt(e)
+	}
+	if e.name == "" {
+		e.name = fmt.Sprintf("job-%d", e.id)
+	}
+	if s.running {
+		e.next = schedule.Next(s.clock.Now())
+	}
+	s.entries[e.id] = e
+	s.notify()
+	return e.id, nil
+}
+
+// Remove 移除任务,正在执行的实例不受影响
+func (s *Scheduler) Remove(id EntryID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.entries, id)
+	s.notify()
+}
+
+// Entries 返回按下一次触发时间排序的任务快照
+func (s *Scheduler) Entries() []Entry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list := make([]Entry, 0, len(s.entries))
+	for _, e := range s.entries {
+		list = append(list, Entry{ID: e.id, Name: e.name, Next: e.next, Prev: e.prev})
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Next.Before(list[j].Next) || list[i].Next.Equal(list[j].Next) && list[i].ID < list[j].ID
+	})
+	return list
+}
+
+// Start 启动调度器,重复调用无副作用
+func (s *Scheduler) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.running || s.stopped {
+		return
+	}
+	s.running = true
+
+	now := s.clock.Now()
+	for _, e := range s.entries {
+		e.next = e.schedule.Next(now)
+	}
+	go s.loop()
+}
+
+// Stop 停止调度并等待正在执行的任务结束,ctx 到期时取消任务的context并返回 ctx.Err()
+func (s *Scheduler) Stop(ctx context.Context) error {
+	s.mu.Lock()
+	if s.stopped {
+		s.mu.Unlock()
+		return nil
+	}
+	s.stopped = true
+	wasRunning := s.running
+	s.running = false
+	close(s.quit)
+	s.mu.Unlock()
+
+	if wasRunning {
+		<-s.done
+	}
+
+	finished := make(chan struct{})
+	go func() {
+		s.jobs.Wait()
+		close(finished)
+	}()
+
+	select {
+	case <-finished:
+		s.cancel()
+		return nil
+	case <-ctx.Done():
+		s.cancel()
+		return ctx.Err()
+	}
+}
+
+// loop 调度主循环
+func (s *Scheduler) loop() {
+	defer close(s.done)
+
+	// 定时器在首次计算等待时长后再创建,使模拟时钟下注册的触发时间确定
+	var timer clock.Timer
+	defer func() {
+		if timer != nil {
+			timer.Stop()
+		}
+	}()
+
+	for {
+		s.mu.Lock()
+		next := s.earliest()
+		s.mu.Unlock()
+
+		wait := time.Hour
+		if !next.IsZero() {
+			wait = clock.Until(s.clock, next)
+		}
+		if timer == nil {
+			timer = s.clock.NewTimer(wait)
+		} else {
+			timer.Reset(wait)
+		}
+
+		select {
+		case <-timer.C():
+			s.runDue(s.clock.Now())
+		case <-s.wake:
+		case <-s.quit:
+			return
+		}
+	}
+}
+
+// earliest 返回最早的触发时间,调用方需持有锁
+func (s *Scheduler) earliest() time.Time {
+	var next time.Time
+	for _, e := range s.entries {
+		if e.next.IsZero() {
+			continue
+		}
+		if next.IsZero() || e.next.Before(next) {
+			next = e.next
+		}
+	}
+	return next
+}
+
+// runDue 触发所有到期任务并计算下一次触发时间
+func (s *Scheduler) runDue(now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, e := range s.entries {
+		if e.next.IsZero() || e.next.After(now) {
+			continue
+		}
+		e.prev = e.next
+		e.next = e.schedule.Next(now)
+		s.dispatch(e)
+	}
+}
+
+// dispatch 按重叠策略执行任务,调用方需持有锁
+func (s *Scheduler) dispatch(e *entry) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.active > 0 {
+		switch e.overlap {
+		case OverlapSkip:
+			return
+		case OverlapQueue:
+			e.pending++
+			return
+		}
+	}
+	e.active++
+	s.jobs.Add(1)
+	go s.run(e)
+}
+
+// run 执行任务,排队策略下会继续执行排队的次数
+func (s *Scheduler) run(e *entry) {
+	defer s.jobs.Done()
+
+	for {
+		if err := s.execute(e); err != nil {
+			s.onError(e.id, e.name, err)
+		}
+
+		e.mu.Lock()
+		if e.pending > 0 && !s.stopping() {
+			e.pending--
+			e.mu.Unlock()
+			continue
+		}
+		e.pending = 0
+		e.active--
+		e.mu.Unlock()
+		return
+	}
+}
+
+// execute 执行一次任务,配置了重试时交给 retry.Retry,退避等待默认使用调度器的时钟
+func (s *Scheduler) execute(e *entry) error {
+	if len(e.retryOpts) == 0 {
+		return safeCall(s.ctx, e.job)
+	}
+
+	var lastErr error
+	opts := append([]retry.Option{retry.RetryWithClock(s.clock)}, e.retryOpts...)
+	opts = append(opts, retry.Context(s.ctx))
+	err := retry.Retry(func() error {
+		lastErr = safeCall(s.ctx, e.job)
+		return lastErr
+	}, opts...)
+	if err != nil && lastErr != nil {
+		return fmt.Errorf("%w: %w", err, lastErr)
+	}
+	return err
+}
+
+// stopping 调度器是否已开始停止,停止后不再执行排队的任务
+func (s *Scheduler) stopping() bool {
+	select {
+	case <-s.quit:
+		return true
+	default:
+		return false
+	}
+}
+
+// notify 唤醒调度循环重新计算等待时间
+func (s *Scheduler) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// safeCall 执行任务并把panic转换为错误
+func safeCall(ctx context.Context, job Job) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("%w: %v\n%s", ErrJobPanic, p, debug.Stack())
+		}
+	}()
+	return job(ctx)
+}
@@ -0,0 +1,205 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wind959/ko-utils/clock"
+	"github.com/wind959/ko-utils/retry"
+)
+
+func TestParseCron(t *testing.T) {
+	layout := "2006-01-02 15:04:05"
+	at := func(s string) time.Time {
+		d, _ := time.ParseInLocation(layout, s, time.UTC)
+		return d
+	}
+
+	cases := []struct {
+		spec       string
+		from       string
+		next, prev string
+	}{
+		{"*/15 * * * *", "2024-03-01 10:07:30", "2024-03-01 10:15:00", "2024-03-01 10:00:00"},
+		{"0 9 * * MON-FRI", "2024-03-01 09:00:00", "2024-03-04 09:00:00", "2024-02-29 09:00:00"},
+		{"30 0 12 1,15 * *", "2024-03-01 12:00:30", "2024-03-15 12:00:30", "2024-02-15 12:00:30"},
+		{"0 0 29 2 *", "2024-03-01 00:00:00", "2028-02-29 00:00:00", "2024-02-29 00:00:00"},
+		{"@daily", "2024-12-31 23:59:59", "2025-01-01 00:00:00", "2024-12-31 00:00:00"},
+		{"@weekly", "2024-03-06 08:00:00", "2024-03-10 00:00:00", "2024-03-03 00:00:00"},
+		// 日期与星期同时限定时取"或"
+		{"0 0 13 * 5", "2024-09-01 00:00:00", "2024-09-06 00:00:00", "2024-08-30 00:00:00"},
+		{"0 0 * * 7", "2024-03-06 00:00:00", "2024-03-10 00:00:00", "2024-03-03 00:00:00"},
+	}
+	for _, c := range cases {
+		s, err := ParseCronInLocation(c.spec, time.UTC)
+		assert.NoError(t, err, c.spec)
+		assert.Equal(t, c.next, s.Next(at(c.from)).Format(layout), c.spec)
+		assert.Equal(t, c.prev, s.Prev(at(c.from)).Format(layout), c.spec)
+	}
+
+	// 时区前缀: 上海 09:00 即 UTC 01:00
+	s, err := ParseCron("CRON_TZ=Asia/Shanghai 0 9 * * *")
+	assert.NoError(t, err)
+	assert.Equal(t, at("2024-03-02 01:00:00"), s.Next(at("2024-03-01 02:00:00")).UTC())
+
+	every, err := ParseCron("@every 1h30m")
+	assert.NoError(t, err)
+	assert.Equal(t, at("2024-03-01 11:30:00"), every.Next(at("2024-03-01 10:00:00")))
+
+	never := MustParseCron("0 0 30 2 *")
+	assert.True(t, never.Next(time.Now()).IsZero())
+
+	for _, bad := range []string{"", "* * * *", "60 * * * *", "* * * * MON-", "*/0 * * * *", "@often", "TZ=Nowhere/City * * * * *"} {
+		_, err := ParseCron(bad)
+		assert.ErrorIs(t, err, ErrInvalidSpec, bad)
+	}
+}
+
+func TestSchedulerOverlapAndPanic(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		errs   []error
+		skip   atomic.Int32
+		queued atomic.Int32
+	)
+	fake := clock.NewFake(time.Time{})
+	s := New(WithClock(fake), WithErrorHandler(func(id EntryID, name string, err error) {
+		mu.Lock()
+		errs = append(errs, err)
+		mu.Unlock()
+	}))
+
+	// 两个任务在 release 关闭前一直执行中
+	release := make(chan struct{})
+	ran := make(chan struct{}, 10)
+	every := EverySchedule{Interval: 10 * time.Millisecond}
+	_, err := s.Schedule(every, func(ctx context.Context) error {
+		skip.Add(1)
+		<-release
+		return nil
+	}, WithOverlapPolicy(OverlapSkip))
+	assert.NoError(t, err)
+	_, err = s.Schedule(every, func(ctx context.Context) error {
+		queued.Add(1)
+		<-release
+		ran <- struct{}{}
+		return nil
+	}, WithOverlapPolicy(OverlapQueue))
+	assert.NoError(t, err)
+	_, err = s.Schedule(EverySchedule{Interval: 20 * time.Millisecond}, func(ctx context.Context) error {
+		panic("boom")
+	}, WithJobName("panicky"))
+	assert.NoError(t, err)
+
+	// 每次推进后等待调度循环重新注册定时器,确保本次触发已分发
+	s.Start()
+	for i := 0; i < 5; i++ {
+		fake.BlockUntil(1)
+		fake.Advance(10 * time.Millisecond)
+	}
+	fake.BlockUntil(1)
+	close(release)
+	for i := 0; i < 5; i++ {
+		<-ran
+	}
+	assert.NoError(t, s.Stop(context.Background()))
+
+	// 跳过策略只执行第一次触发,排队策略补跑其余4次
+	assert.Equal(t, int32(1), skip.Load())
+	assert.Equal(t, int32(5), queued.Load())
+
+	mu.Lock()
+	if assert.Len(t, errs, 2) {
+		assert.ErrorIs(t, errs[0], ErrJobPanic)
+	}
+	mu.Unlock()
+
+	_, err = s.AddFunc("@hourly", func() {})
+	assert.ErrorIs(t, err, ErrSchedulerStopped)
+}
+
+func TestSchedulerRetryAndStop(t *testing.T) {
+	var calls atomic.Int32
+	done := make(chan struct{})
+	fake := clock.NewFake(time.Time{})
+	s := New(WithClock(fake), WithErrorHandler(func(EntryID, string, error) {}))
+	_, err := s.Schedule(EverySchedule{Interval: 10 * time.Millisecond}, func(ctx context.Context) error {
+		if calls.Add(1) < 3 {
+			return errors.New("temporary")
+		}
+		select {
+		case <-done:
+		default:
+			close(done)
+		}
+		return nil
+	}, WithOverlapPolicy(OverlapSkip), WithRetry(retry.RetryTimes(5), retry.RetryWithLinearBackoff(time.Second)))
+	assert.NoError(t, err)
+
+	// 重试的退避等待使用调度器的模拟时钟: 调度定时器和退避定时器同时等待
+	s.Start()
+	fake.BlockUntil(1)
+	fake.Advance(10 * time.Millisecond)
+	fake.BlockUntil(2)
+	assert.Equal(t, int32(1), calls.Load())
+	fake.Advance(time.Second - 10*time.Millisecond)
+	fake.BlockUntil(2)
+	assert.Equal(t, int32(1), calls.Load())
+	fake.Advance(10 * time.Millisecond)
+	fake.BlockUntil(2)
+	assert.Equal(t, int32(2), calls.Load())
+	fake.Advance(time.Second)
+	<-done
+	assert.NoError(t, s.Stop(context.Background()))
+
+	// 超时停止时取消任务的context
+	s = New(WithClock(fake))
+	started := make(chan struct{})
+	cancelled := make(chan error, 1)
+	_, err = s.Schedule(EverySchedule{Interval: 5 * time.Millisecond}, func(ctx context.Context) error {
+		close(started)
+		<-ctx.Done()
+		cancelled <- ctx.Err()
+		return ctx.Err()
+	}, WithOverlapPolicy(OverlapSkip))
+	assert.NoError(t, err)
+	s.Start()
+	fake.BlockUntil(1)
+	fake.Advance(5 * time.Millisecond)
+	<-started
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, s.Stop(ctx), context.Canceled)
+	assert.ErrorIs(t, <-cancelled, context.Canceled)
+}
+
+func TestSchedulerFakeClock(t *testing.T) {
+	start := time.Date(2024, 3, 1, 8, 59, 0, 0, time.UTC)
+	fake := clock.NewFake(start)
+	s := New(WithClock(fake), WithLocation(time.UTC))
+
+	runs := make(chan time.Time, 10)
+	_, err := s.AddJob("0 9 * * *", func(ctx context.Context) error {
+		runs <- fake.Now()
+		return nil
+	})
+	assert.NoError(t, err)
+	s.Start()
+	defer s.Stop(context.Background())
+
+	assert.Equal(t, start.Add(time.Minute), s.Entries()[0].Next)
+
+	fake.BlockUntil(1)
+	fake.Advance(time.Minute)
+	assert.Equal(t, start.Add(time.Minute), <-runs)
+
+	assert.Eventually(t, func() bool {
+		return s.Entries()[0].Next.Equal(start.Add(24*time.Hour + time.Minute))
+	}, time.Second, time.Millisecond)
+}